@@ -0,0 +1,63 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// validateModuleAliases returns an error if the aliases of modules are empty,
+// cannot be listed in a Modules footer, or are ambiguous.
+func validateModuleAliases(aliases map[string]string) error {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := map[string]string{}
+	for _, name := range names {
+		alias := aliases[name]
+		if alias == "" {
+			return fmt.Errorf("empty alias for module %s", name)
+		}
+		if strings.ContainsRune(alias, ',') || strings.IndexFunc(alias, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("invalid alias %q for module %s: must not contain commas or spaces", alias, name)
+		}
+		if other, ok := seen[alias]; ok {
+			return fmt.Errorf("modules %s and %s have the same alias %q", other, name, alias)
+		}
+		if _, ok := aliases[alias]; ok && alias != name {
+			return fmt.Errorf("alias %q for module %s is the name of another module", alias, name)
+		}
+		seen[alias] = name
+	}
+
+	return nil
+}
+
+// moduleAlias returns the alias of the module name, or the empty string if it
+// has none.
+func (g *Gotagger) moduleAlias(name string) string {
+	return g.Config.ModuleAliases[name]
+}
+
+// moduleName returns the name of the module whose name or alias is
+// nameOrAlias. Module names take precedence over aliases.
+func (g *Gotagger) moduleName(nameOrAlias string) string {
+	if _, ok := g.Config.ModuleAliases[nameOrAlias]; ok {
+		return nameOrAlias
+	}
+
+	for name, alias := range g.Config.ModuleAliases {
+		if alias == nameOrAlias {
+			return name
+		}
+	}
+
+	return nameOrAlias
+}
@@ -0,0 +1,178 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sassoftware/gotagger/internal/git"
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// BackfillTag is a version tag missing from a past release commit.
+type BackfillTag struct {
+	// Commit is the hash of the release commit.
+	Commit string
+
+	// Module is the name of the go module, if any.
+	Module string
+
+	// Path is the path to the module or path filter,
+	// relative to the root of the repository.
+	Path string
+
+	// Tag is the version tag the release commit should have, including its
+	// prefix.
+	Tag string
+}
+
+// Backfill finds the release commits in the history of HEAD that were never
+// tagged, such as releases made before gotagger was adopted or by pipelines
+// that failed, and returns the version tag each should have, oldest first.
+//
+// Versions are calculated as TagRepo would have calculated them at each
+// release commit, as if the missing tags of earlier release commits existed.
+// A release is skipped if its commit already has a version tag for the
+// module, or if the calculated tag already exists on another commit.
+//
+// If Config.CreateTag is set, then the missing tags are created, and they are
+// pushed if Config.PushTag is set. Floating tags are not moved.
+func (g *Gotagger) Backfill() ([]BackfillTag, error) {
+	if err := g.checkCommits(); err != nil {
+		return nil, err
+	}
+
+	if g.Config.CreateTag && g.Config.PushTag {
+		if err := g.checkOnline("push tags"); err != nil {
+			return nil, err
+		}
+	}
+
+	commits, err := g.repo.RevList(head, "")
+	if err != nil {
+		return nil, err
+	}
+
+	assumed := map[moduleKey]assumedTag{}
+
+	var missing []BackfillTag
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		if c.Type != mapper.TypeRelease {
+			continue
+		}
+
+		tags, err := g.backfillCommit(c, assumed)
+		if err != nil {
+			return nil, fmt.Errorf("could not version release commit %s: %w", c.Hash, err)
+		}
+
+		missing = append(missing, tags...)
+	}
+
+	if len(missing) == 0 || !g.Config.CreateTag {
+		return missing, nil
+	}
+
+	// the tags are not a release of HEAD,
+	// so an interrupted backfill is simply run again
+	created := make([]string, 0, len(missing))
+	for _, tag := range missing {
+		if err := g.repo.CreateTag(tag.Commit, tag.Tag, tagMessage(tag.Tag), false); err != nil {
+			return nil, g.abortRelease(created, err)
+		}
+		g.events.Info("created tag", "tag", tag.Tag, "commit", tag.Commit)
+		created = append(created, tag.Tag)
+	}
+
+	if g.Config.PushTag {
+		if err := g.pushTags(&journal{Tags: created, Remote: g.Config.RemoteName}); err != nil {
+			return nil, g.abortRelease(created, err)
+		}
+	}
+
+	return missing, nil
+}
+
+// backfillCommit returns the missing version tags of the release commit c,
+// and adds them to assumed, so that later release commits are versioned as if
+// they existed.
+func (g *Gotagger) backfillCommit(c git.Commit, assumed map[moduleKey]assumedTag) ([]BackfillTag, error) {
+	logger := g.logger.WithValues("commit", c.Hash)
+
+	opts, err := g.extractReleaseOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	opts.to = c.Hash
+	opts.tagsOnly = true
+
+	// only assume the tags of earlier releases in the history of c
+	opts.assumed = map[moduleKey]assumedTag{}
+	for key, tag := range assumed {
+		if ok, err := g.repo.IsAncestor(tag.hash, c.Hash); err != nil {
+			return nil, err
+		} else if ok {
+			opts.assumed[key] = tag
+		}
+	}
+
+	var modules []module
+	if !g.Config.IgnoreModules {
+		if modules, err = g.findModulesAt(c.Hash, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	commitModules, err := g.releaseModules(c, modules, &opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := g.releases(modules, commitModules, opts)
+	if err != nil {
+		return nil, err
+	}
+	results := releaseResults(releases)
+
+	existing, err := g.repo.TagsAt(c.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []BackfillTag
+	for i, res := range results {
+		if tag := tagFor(results, i, existing); tag != "" {
+			logger.Info("release commit is already tagged", "tag", tag)
+			continue
+		}
+
+		refs, err := g.repo.FindRefs([]string{res.Version})
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) > 0 {
+			logger.Info("tag already exists on another commit", "tag", res.Version)
+			continue
+		}
+
+		v, err := semver.NewVersion(strings.TrimPrefix(res.Version, res.Prefix))
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("release commit is missing a tag", "tag", res.Version)
+		missing = append(missing, BackfillTag{
+			Commit: c.Hash,
+			Module: res.Module,
+			Path:   res.Path,
+			Tag:    res.Version,
+		})
+		assumed[moduleKey{res.Module, res.Path}] = assumedTag{name: res.Version, version: v, hash: c.Hash}
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,61 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	badgeLabel = "version"
+
+	// colors of stable and unstable versions, such as v0 and pre-releases
+	badgeColorStable   = "blue"
+	badgeColorUnstable = "orange"
+)
+
+// Badge is a shields.io endpoint badge of a version, such as
+// {"schemaVersion":1,"label":"version","message":"v1.4.2","color":"blue"}.
+// See https://shields.io/badges/endpoint-badge.
+type Badge struct {
+	// SchemaVersion is the version of the endpoint schema. It is always 1.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// Label is the text on the left of the badge.
+	Label string `json:"label"`
+
+	// Message is the version, without any module prefix.
+	Message string `json:"message"`
+
+	// Color is blue for stable versions, and orange for v0 versions and
+	// pre-releases.
+	Color string `json:"color"`
+}
+
+// Badges returns a Badge of the version of each of results, such as those
+// returned by Results.
+func (g *Gotagger) Badges(results []Result) []Badge {
+	badges := make([]Badge, len(results))
+	for i, res := range results {
+		// the module prefix is not part of the version
+		modPrefix := strings.TrimSuffix(res.Prefix, g.Config.VersionPrefix)
+		message := strings.TrimPrefix(res.Version, modPrefix)
+
+		color := badgeColorStable
+		if v, err := semver.NewVersion(strings.TrimPrefix(message, g.Config.VersionPrefix)); err != nil || v.Major() == 0 || v.Prerelease() != "" {
+			color = badgeColorUnstable
+		}
+
+		badges[i] = Badge{
+			SchemaVersion: 1,
+			Label:         badgeLabel,
+			Message:       message,
+			Color:         color,
+		}
+	}
+
+	return badges
+}
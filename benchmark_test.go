@@ -0,0 +1,168 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/sassoftware/gotagger/internal/testutils"
+)
+
+// benchSizes are the synthetic repositories used by the benchmarks.
+var benchSizes = []struct {
+	modules int
+	commits int
+	tags    int
+}{
+	{modules: 1, commits: 100, tags: 10},
+	{modules: 1, commits: 5000, tags: 1000},
+	{modules: 10, commits: 1000, tags: 100},
+	{modules: 50, commits: 5000, tags: 100},
+}
+
+func BenchmarkGotagger_Version(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("modules=%d/commits=%d/tags=%d", size.modules, size.commits, size.tags), func(b *testing.B) {
+			g := newBenchGotagger(b, size.modules, size.commits, size.tags)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := g.Version(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGotagger_ModuleVersions(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("modules=%d/commits=%d/tags=%d", size.modules, size.commits, size.tags), func(b *testing.B) {
+			g := newBenchGotagger(b, size.modules, size.commits, size.tags)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := g.ModuleVersions(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGotagger_TagRepo(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("modules=%d/commits=%d/tags=%d", size.modules, size.commits, size.tags), func(b *testing.B) {
+			g := newBenchGotagger(b, size.modules, size.commits, size.tags)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := g.TagRepo(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// newBenchGotagger returns a Gotagger for a synthetic repository with the
+// given number of modules, commits, and version tags per module.
+//
+// The first module is the root module, and the others are in directories
+// named modN. Each commit changes one module, in turn, and the tags are
+// spread evenly across the history.
+func newBenchGotagger(b *testing.B, modules, commits, tags int) *Gotagger {
+	b.Helper()
+
+	dir := b.TempDir()
+	runBenchGit(b, dir, nil, "init", "--quiet")
+
+	moduleDir := func(m int) string {
+		if m == 0 {
+			return ""
+		}
+		return fmt.Sprintf("mod%d", m)
+	}
+
+	// build a fast-import stream, which is much faster than creating
+	// commits one at a time
+	var stream bytes.Buffer
+	data := func(s string) {
+		fmt.Fprintf(&stream, "data %d\n%s\n", len(s), s)
+	}
+	commit := func(mark int, message string, files map[string]string) {
+		fmt.Fprintf(&stream, "commit refs/heads/master\nmark :%d\n", mark)
+		fmt.Fprintf(&stream, "committer %s <%s> %d +0000\n", testutils.GotaggerName, testutils.GotaggerEmail, 1600000000+mark)
+		data(message)
+		for name, contents := range files {
+			fmt.Fprintf(&stream, "M 100644 inline %s\n", name)
+			data(contents)
+		}
+	}
+
+	// the first commit adds every module
+	files := make(map[string]string, modules)
+	for m := 0; m < modules; m++ {
+		files[path.Join(moduleDir(m), goMod)] = "module " + path.Join("bench", moduleDir(m)) + "\n"
+	}
+	commit(1, "feat: add modules", files)
+
+	every := commits / tags
+	if every == 0 {
+		every = 1
+	}
+
+	var tagged int
+	for c := 1; c < commits; c++ {
+		m := c % modules
+		typ := "fix"
+		if c%3 == 0 {
+			typ = "feat"
+		}
+
+		commit(c+1, fmt.Sprintf("%s: change %d", typ, c), map[string]string{
+			path.Join(moduleDir(m), "file.go"): fmt.Sprintf("package bench\n\n// change %d\n", c),
+		})
+
+		if c%every == 0 && tagged < tags {
+			for m := 0; m < modules; m++ {
+				prefix := ""
+				if m > 0 {
+					prefix = moduleDir(m) + "/"
+				}
+				fmt.Fprintf(&stream, "reset refs/tags/%sv1.%d.0\nfrom :%d\n\n", prefix, tagged, c+1)
+			}
+			tagged++
+		}
+	}
+
+	runBenchGit(b, dir, &stream, "fast-import", "--quiet")
+	runBenchGit(b, dir, nil, "symbolic-ref", "HEAD", "refs/heads/master")
+	runBenchGit(b, dir, nil, "reset", "--quiet", "--hard")
+
+	g, err := New(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return g
+}
+
+func runBenchGit(b *testing.B, dir string, stdin *bytes.Buffer, args ...string) {
+	b.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		b.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
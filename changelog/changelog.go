@@ -0,0 +1,350 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package changelog renders release changes as a keep-a-changelog document,
+// plain text, or JSON.
+//
+// See https://keepachangelog.com for details on the keep-a-changelog format.
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FileName is the name of a markdown changelog file.
+const FileName = "CHANGELOG.md"
+
+const (
+	sectionAdded        = "Added"
+	sectionChanged      = "Changed"
+	sectionDependencies = "Dependencies"
+	sectionFixed        = "Fixed"
+
+	dateFormat        = "2006-01-02"
+	header            = "# Changelog\n\nAll notable changes to this project will be documented in this file.\n"
+	releasePrefix     = "## "
+	unreleasedHeading = "## [Unreleased]"
+	unreleasedTitle   = "## [unreleased]"
+)
+
+// Change is a single change in a release,
+// usually derived from a conventional commit.
+type Change struct {
+	Type     string `json:"type"`
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Breaking bool   `json:"breaking,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+
+	// Dependency is true if the change is a dependency update,
+	// such as one made by Dependabot or Renovate.
+	Dependency bool `json:"dependency,omitempty"`
+
+	// Insertions and Deletions are the number of lines the change added and
+	// removed. They are shown if either is set.
+	Insertions int `json:"insertions,omitempty"`
+	Deletions  int `json:"deletions,omitempty"`
+
+	// ChangeID is the Gerrit Change-Id of the change, if any. Commit links
+	// may use it instead of the hash.
+	ChangeID string `json:"changeId,omitempty"`
+
+	// Topic is the Gerrit topic that the change was merged in, if any.
+	Topic string `json:"topic,omitempty"`
+
+	// PullRequest is the number of the Azure Repos or Bitbucket pull request
+	// that merged the change, if its subject was decorated with it.
+	PullRequest int `json:"pullRequest,omitempty"`
+}
+
+// Release is a version and the changes that were made in it.
+type Release struct {
+	// Name is a short name for what was released, such as the alias of a
+	// module. It is shown before the version in headings, if set.
+	Name string `json:"name,omitempty"`
+
+	Version string    `json:"version"`
+	Date    time.Time `json:"date"`
+	Changes []Change  `json:"changes"`
+
+	// Owners are the teams or people that own the changes, such as those
+	// listed in a CODEOWNERS file. They are shown below the heading, if set.
+	Owners []string `json:"owners,omitempty"`
+
+	// Preset controls how the changes are grouped when rendered as
+	// markdown or text.
+	Preset Preset `json:"-"`
+
+	// Links controls how commits and issues are linked when rendered as
+	// markdown.
+	Links Links `json:"-"`
+
+	// GroupByTopic lists the changes with a Gerrit topic in a section for
+	// their topic when rendered as markdown or text.
+	GroupByTopic bool `json:"-"`
+}
+
+// section is a titled group of changes.
+type section struct {
+	title   string
+	changes []Change
+}
+
+// Markdown returns the markdown section for r.
+//
+// With the keep-a-changelog preset, features are listed under "Added", bug
+// fixes under "Fixed", and breaking changes, performance improvements, and
+// refactors under "Changed". With the Angular preset, features, bug fixes,
+// and performance improvements each have their own section, and breaking
+// changes are also listed under "BREAKING CHANGES". With either preset,
+// dependency updates are listed under "Dependencies". Other changes are not
+// notable, so they are omitted.
+func (r Release) Markdown() string {
+	return r.heading() + "\n" + r.body()
+}
+
+// heading returns the markdown heading of the section for r.
+func (r Release) heading() string {
+	if r.Preset == PresetAngular {
+		return releasePrefix + r.label() + r.Version + " (" + r.Date.Format(dateFormat) + ")"
+	}
+
+	return releasePrefix + r.label() + "[" + r.Version + "] - " + r.Date.Format(dateFormat)
+}
+
+// body returns the markdown of the owners and changes of r, which follows
+// its heading.
+func (r Release) body() string {
+	bullet := "- "
+	if r.Preset == PresetAngular {
+		bullet = "* "
+	}
+
+	var b strings.Builder
+	if len(r.Owners) > 0 {
+		b.WriteString("\nOwners: " + strings.Join(r.Owners, ", ") + "\n")
+	}
+	for _, s := range r.sections() {
+		b.WriteString("\n### " + s.title + "\n\n")
+		for _, c := range s.changes {
+			b.WriteString(bullet + c.markdown(r.Links) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// Update returns the changelog in data with the section for r added.
+//
+// The new section is inserted above the previous releases, after any
+// Unreleased section. If data already has a section for the version of r,
+// then it is replaced. If data is empty, then a new changelog is created.
+//
+// If the Unreleased section lists any changes, such as those written by
+// UpdateUnreleased and then edited by hand, then they are moved under the
+// heading for r instead of the changes of r, and the Unreleased section is
+// left empty.
+func Update(data []byte, r Release) []byte {
+	lines := splitLines(data)
+	if len(lines) == 0 {
+		return []byte(header + "\n" + r.Markdown())
+	}
+
+	section := r.Markdown()
+	if u, uend := unreleasedSection(lines); u >= 0 {
+		if body := trimBlankLines(lines[u+1 : uend]); len(body) > 0 {
+			section = r.heading() + "\n\n" + strings.Join(body, "\n") + "\n"
+			lines = append(lines[:u+1:u+1], lines[uend:]...)
+		}
+	}
+
+	// find where the new section goes,
+	// and where the section it replaces ends
+	start, end := len(lines), len(lines)
+	for i, line := range lines {
+		if !strings.HasPrefix(line, releasePrefix) || isUnreleased(line) {
+			continue
+		}
+
+		start, end = i, i
+		if isHeading(line, r) {
+			for end = i + 1; end < len(lines) && !strings.HasPrefix(lines[end], releasePrefix); end++ {
+			}
+		}
+		break
+	}
+
+	var b strings.Builder
+	for _, line := range lines[:start] {
+		b.WriteString(line + "\n")
+	}
+
+	// make sure there is a blank line before the new section
+	if start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(section)
+
+	if end < len(lines) {
+		b.WriteString("\n")
+		for _, line := range lines[end:] {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// UpdateUnreleased returns the changelog in data with its Unreleased section
+// replaced by the changes of r, which have not been released yet. The version
+// and date of r are not used.
+//
+// If data has no Unreleased section, then one is inserted above the previous
+// releases. If data is empty, then a new changelog is created.
+func UpdateUnreleased(data []byte, r Release) []byte {
+	section := unreleasedHeading + "\n" + r.body()
+
+	lines := splitLines(data)
+	if len(lines) == 0 {
+		return []byte(header + "\n" + section)
+	}
+
+	start, end := unreleasedSection(lines)
+	if start < 0 {
+		// the new section goes above the first release
+		for start = 0; start < len(lines) && !strings.HasPrefix(lines[start], releasePrefix); start++ {
+		}
+		end = start
+	}
+
+	var b strings.Builder
+	for _, line := range lines[:start] {
+		b.WriteString(line + "\n")
+	}
+
+	// make sure there is a blank line before the new section
+	if start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(section)
+
+	if end < len(lines) {
+		b.WriteString("\n")
+		for _, line := range lines[end:] {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// unreleasedSection returns the index of the heading of the Unreleased
+// section in lines, and the index of the line after the section, or -1 if
+// there is no Unreleased section.
+func unreleasedSection(lines []string) (start, end int) {
+	for i, line := range lines {
+		if !strings.HasPrefix(line, releasePrefix) {
+			continue
+		}
+		if !isUnreleased(line) {
+			break
+		}
+
+		for end = i + 1; end < len(lines) && !strings.HasPrefix(lines[end], releasePrefix); end++ {
+		}
+		return i, end
+	}
+
+	return -1, -1
+}
+
+// isUnreleased returns true if line is the heading of an Unreleased section.
+func isUnreleased(line string) bool {
+	return strings.HasPrefix(strings.ToLower(line), unreleasedTitle)
+}
+
+// splitLines returns the lines of data.
+func splitLines(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines
+}
+
+// trimBlankLines returns lines without its leading and trailing blank lines.
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// label returns the name of r followed by a space, or the empty string if r
+// has no name.
+func (r Release) label() string {
+	if r.Name == "" {
+		return ""
+	}
+
+	return r.Name + " "
+}
+
+// isHeading returns true if line is the markdown heading for r, in either the
+// keep-a-changelog or the Angular style.
+func isHeading(line string, r Release) bool {
+	prefix := releasePrefix + r.label()
+	return strings.HasPrefix(line, prefix+"["+r.Version+"]") ||
+		strings.HasPrefix(line, prefix+r.Version+" ")
+}
+
+func (c Change) markdown(links Links) string {
+	var line string
+	if c.Breaking {
+		line += "**BREAKING:** "
+	}
+	if c.Scope != "" {
+		line += "**" + c.Scope + ":** "
+	}
+	line += links.issues(c.Subject)
+
+	if details := c.details(links.commit(c.Hash, c.ChangeID, c.shortHash())); details != "" {
+		line += " (" + details + ")"
+	}
+
+	return line
+}
+
+// details returns the hash and size of c, such as "0123456, +12/-3",
+// where hash is how the short hash of c is rendered.
+func (c Change) details(hash string) string {
+	var details []string
+	if hash != "" {
+		details = append(details, hash)
+	}
+	if c.Insertions > 0 || c.Deletions > 0 {
+		details = append(details, fmt.Sprintf("+%d/-%d", c.Insertions, c.Deletions))
+	}
+
+	return strings.Join(details, ", ")
+}
+
+func (c Change) shortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+
+	return c.Hash
+}
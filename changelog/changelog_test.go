@@ -0,0 +1,163 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRelease = Release{
+	Version: "1.1.0",
+	Date:    time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC),
+	Changes: []Change{
+		{Type: "feat", Subject: "add bar", Hash: "0123456789abcdef"},
+		{Type: "fix", Scope: "foo", Subject: "fix foo", Hash: "fedcba9876543210"},
+		{Type: "feat", Subject: "remove baz", Breaking: true, Hash: "1111111111111111"},
+		{Type: "chore", Subject: "update tooling", Hash: "2222222222222222"},
+	},
+}
+
+const testMarkdown = `## [1.1.0] - 2024-06-01
+
+### Added
+
+- add bar (0123456)
+
+### Changed
+
+- **BREAKING:** remove baz (1111111)
+
+### Fixed
+
+- **foo:** fix foo (fedcba9)
+`
+
+func TestRelease_Markdown(t *testing.T) {
+	assert.Equal(t, testMarkdown, testRelease.Markdown())
+
+	deps := Release{Version: "1.0.1", Date: testRelease.Date, Changes: []Change{
+		{Subject: "Bump qux from 1.0.0 to 1.1.0", Dependency: true, Hash: "3333333333333333"},
+	}}
+	assert.Equal(t, "## [1.0.1] - 2024-06-01\n\n### Dependencies\n\n- Bump qux from 1.0.0 to 1.1.0 (3333333)\n", deps.Markdown())
+
+	sized := Release{Version: "1.0.1", Date: testRelease.Date, Changes: []Change{
+		{Type: "fix", Subject: "fix foo", Hash: "4444444444444444", Insertions: 12, Deletions: 3},
+		{Type: "fix", Subject: "fix bar", Insertions: 1},
+	}}
+	assert.Equal(t, "## [1.0.1] - 2024-06-01\n\n### Fixed\n\n- fix foo (4444444, +12/-3)\n- fix bar (+1/-0)\n", sized.Markdown())
+
+	empty := Release{Version: "1.0.1", Date: testRelease.Date}
+	assert.Equal(t, "## [1.0.1] - 2024-06-01\n", empty.Markdown())
+
+	named := Release{Name: "api", Version: "1.0.1", Date: testRelease.Date}
+	assert.Equal(t, "## api [1.0.1] - 2024-06-01\n", named.Markdown())
+	named.Preset = PresetAngular
+	assert.Equal(t, "## api 1.0.1 (2024-06-01)\n", named.Markdown())
+
+	owned := Release{Version: "1.0.1", Date: testRelease.Date, Owners: []string{"@org/api", "@alice"}, Changes: []Change{
+		{Type: "fix", Subject: "fix foo", Hash: "4444444444444444"},
+	}}
+	assert.Equal(t, "## [1.0.1] - 2024-06-01\n\nOwners: @org/api, @alice\n\n### Fixed\n\n- fix foo (4444444)\n", owned.Markdown())
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		title string
+		data  string
+		want  string
+	}{
+		{
+			title: "new changelog",
+			want:  header + "\n" + testMarkdown,
+		},
+		{
+			title: "previous release",
+			data:  "# Changelog\n\n## [1.0.0] - 2024-01-01\n\n- initial release\n",
+			want:  "# Changelog\n\n" + testMarkdown + "\n## [1.0.0] - 2024-01-01\n\n- initial release\n",
+		},
+		{
+			title: "unreleased section",
+			data:  "# Changelog\n\n## [Unreleased]\n\n## [1.0.0] - 2024-01-01\n",
+			want:  "# Changelog\n\n## [Unreleased]\n\n" + testMarkdown + "\n## [1.0.0] - 2024-01-01\n",
+		},
+		{
+			title: "unreleased changes",
+			data:  "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- add bar, edited\n\n## [1.0.0] - 2024-01-01\n",
+			want:  "# Changelog\n\n## [Unreleased]\n\n## [1.1.0] - 2024-06-01\n\n### Added\n\n- add bar, edited\n\n## [1.0.0] - 2024-01-01\n",
+		},
+		{
+			title: "no previous release",
+			data:  "# Changelog",
+			want:  "# Changelog\n\n" + testMarkdown,
+		},
+		{
+			title: "replace release",
+			data:  "# Changelog\n\n## [1.1.0] - 2024-05-01\n\n- stale\n\n## [1.0.0] - 2024-01-01\n",
+			want:  "# Changelog\n\n" + testMarkdown + "\n## [1.0.0] - 2024-01-01\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, string(Update([]byte(tt.data), testRelease)))
+		})
+	}
+}
+
+func TestUpdateUnreleased(t *testing.T) {
+	unreleased := "## [Unreleased]" + testMarkdown[len("## [1.1.0] - 2024-06-01"):]
+
+	tests := []struct {
+		title string
+		data  string
+		want  string
+	}{
+		{
+			title: "new changelog",
+			want:  header + "\n" + unreleased,
+		},
+		{
+			title: "previous release",
+			data:  "# Changelog\n\n## [1.0.0] - 2024-01-01\n\n- initial release\n",
+			want:  "# Changelog\n\n" + unreleased + "\n## [1.0.0] - 2024-01-01\n\n- initial release\n",
+		},
+		{
+			title: "replace unreleased section",
+			data:  "# Changelog\n\n## [unreleased]\n\n- stale\n\n## [1.0.0] - 2024-01-01\n",
+			want:  "# Changelog\n\n" + unreleased + "\n## [1.0.0] - 2024-01-01\n",
+		},
+		{
+			title: "no previous release",
+			data:  "# Changelog",
+			want:  "# Changelog\n\n" + unreleased,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, string(UpdateUnreleased([]byte(tt.data), testRelease)))
+		})
+	}
+
+	// releasing moves the unreleased changes under the version
+	released := Update(UpdateUnreleased(nil, testRelease), testRelease)
+	assert.Equal(t, header+"\n## [Unreleased]\n\n"+testMarkdown, string(released))
+}
+
+func TestUpdate_name(t *testing.T) {
+	r := Release{Name: "api", Version: "1.1.0", Date: testRelease.Date}
+
+	data := "# Changelog\n\n## api [1.1.0] - 2024-05-01\n\n- stale\n\n## api [1.0.0] - 2024-01-01\n"
+	want := "# Changelog\n\n## api [1.1.0] - 2024-06-01\n\n## api [1.0.0] - 2024-01-01\n"
+	assert.Equal(t, want, string(Update([]byte(data), r)))
+}
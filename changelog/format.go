@@ -0,0 +1,199 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format is a changelog output format.
+type Format int
+
+const (
+	// FormatMarkdown renders a keep-a-changelog markdown document.
+	FormatMarkdown Format = iota
+
+	// FormatText renders a plain text document.
+	FormatText
+
+	// FormatJSON renders a JSON array of releases, newest first.
+	FormatJSON
+)
+
+// ParseFormat converts a string into a Format.
+// Valid values are "markdown", "text", and "json".
+// The empty string is equivalent to "markdown".
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "markdown", "":
+		return FormatMarkdown, nil
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	}
+
+	return FormatMarkdown, fmt.Errorf("invalid changelog format '%s'", s)
+}
+
+// FileName returns the name of a changelog file in format f.
+func (f Format) FileName() string {
+	switch f {
+	case FormatText:
+		return "CHANGELOG.txt"
+	case FormatJSON:
+		return "CHANGELOG.json"
+	}
+
+	return FileName
+}
+
+// Render returns release r in format f.
+func (f Format) Render(r Release) ([]byte, error) {
+	switch f {
+	case FormatText:
+		return []byte(r.text()), nil
+	case FormatJSON:
+		return json.MarshalIndent(r, "", "  ")
+	}
+
+	return []byte(r.Markdown()), nil
+}
+
+// Update returns the changelog in data, which is in format f, with release r
+// added. If data already contains the version of r, then it is replaced.
+func (f Format) Update(data []byte, r Release) ([]byte, error) {
+	switch f {
+	case FormatText:
+		return updateText(data, r), nil
+	case FormatJSON:
+		return updateJSON(data, r)
+	}
+
+	return Update(data, r), nil
+}
+
+// UpdateUnreleased returns the changelog in data, which is in format f, with
+// its Unreleased section replaced by the changes of r. Only markdown
+// changelogs have an Unreleased section.
+func (f Format) UpdateUnreleased(data []byte, r Release) ([]byte, error) {
+	if f != FormatMarkdown {
+		return nil, errors.New("only markdown changelogs have an unreleased section")
+	}
+
+	return UpdateUnreleased(data, r), nil
+}
+
+// text returns the plain text section for r.
+func (r Release) text() string {
+	title := r.label() + r.Version + " (" + r.Date.Format(dateFormat) + ")"
+
+	var b strings.Builder
+	b.WriteString(title + "\n" + strings.Repeat("=", len(title)) + "\n")
+	if len(r.Owners) > 0 {
+		b.WriteString("\nOwners: " + strings.Join(r.Owners, ", ") + "\n")
+	}
+	for _, s := range r.sections() {
+		b.WriteString("\n" + s.title + ":\n")
+		for _, c := range s.changes {
+			b.WriteString("  - " + c.text() + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+func (c Change) text() string {
+	var line string
+	if c.Breaking {
+		line += "BREAKING: "
+	}
+	if c.Scope != "" {
+		line += c.Scope + ": "
+	}
+	line += c.Subject
+
+	if details := c.details(c.shortHash()); details != "" {
+		line += " (" + details + ")"
+	}
+
+	return line
+}
+
+// updateText inserts r above the first release in data,
+// replacing the existing section for the same version.
+func updateText(data []byte, r Release) []byte {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	// a release title is underlined with '='
+	isTitle := func(i int) bool {
+		return i+1 < len(lines) && lines[i] != "" && lines[i+1] != "" && strings.Trim(lines[i+1], "=") == ""
+	}
+
+	start, end := len(lines), len(lines)
+	for i := range lines {
+		if !isTitle(i) {
+			continue
+		}
+
+		start, end = i, i
+		if strings.HasPrefix(lines[i], r.label()+r.Version+" (") {
+			for end = i + 2; end < len(lines) && !isTitle(end); end++ {
+			}
+		}
+		break
+	}
+
+	var b strings.Builder
+	for _, line := range lines[:start] {
+		b.WriteString(line + "\n")
+	}
+	if start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(r.text())
+
+	if end < len(lines) {
+		b.WriteString("\n")
+		for _, line := range lines[end:] {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// updateJSON adds r to the front of the JSON array of releases in data.
+func updateJSON(data []byte, r Release) ([]byte, error) {
+	var releases []Release
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &releases); err != nil {
+			return nil, fmt.Errorf("invalid JSON changelog: %w", err)
+		}
+	}
+
+	updated := []Release{r}
+	for _, rel := range releases {
+		if rel.Version != r.Version {
+			updated = append(updated, rel)
+		}
+	}
+
+	out, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, '\n'), nil
+}
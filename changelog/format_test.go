@@ -0,0 +1,114 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package changelog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testText = `1.1.0 (2024-06-01)
+==================
+
+Added:
+  - add bar (0123456)
+
+Changed:
+  - BREAKING: remove baz (1111111)
+
+Fixed:
+  - foo: fix foo (fedcba9)
+`
+
+func TestParseFormat(t *testing.T) {
+	for s, want := range map[string]Format{
+		"":         FormatMarkdown,
+		"markdown": FormatMarkdown,
+		"text":     FormatText,
+		"json":     FormatJSON,
+	} {
+		if got, err := ParseFormat(s); assert.NoError(t, err) {
+			assert.Equal(t, want, got)
+		}
+	}
+
+	_, err := ParseFormat("html")
+	assert.EqualError(t, err, "invalid changelog format 'html'")
+}
+
+func TestFormat_Render(t *testing.T) {
+	if got, err := FormatMarkdown.Render(testRelease); assert.NoError(t, err) {
+		assert.Equal(t, testMarkdown, string(got))
+	}
+
+	if got, err := FormatText.Render(testRelease); assert.NoError(t, err) {
+		assert.Equal(t, testText, string(got))
+	}
+
+	if got, err := FormatJSON.Render(testRelease); assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"version": "1.1.0",
+			"date": "2024-06-01T12:00:00Z",
+			"changes": [
+				{"type": "feat", "subject": "add bar", "hash": "0123456789abcdef"},
+				{"type": "fix", "scope": "foo", "subject": "fix foo", "hash": "fedcba9876543210"},
+				{"type": "feat", "subject": "remove baz", "breaking": true, "hash": "1111111111111111"},
+				{"type": "chore", "subject": "update tooling", "hash": "2222222222222222"}
+			]
+		}`, string(got))
+	}
+
+	owned := Release{Version: "1.0.1", Date: testRelease.Date, Owners: []string{"@org/api"}}
+	if got, err := FormatText.Render(owned); assert.NoError(t, err) {
+		assert.Equal(t, "1.0.1 (2024-06-01)\n==================\n\nOwners: @org/api\n", string(got))
+	}
+	if got, err := FormatJSON.Render(owned); assert.NoError(t, err) {
+		assert.JSONEq(t, `{"version": "1.0.1", "date": "2024-06-01T12:00:00Z", "changes": null, "owners": ["@org/api"]}`, string(got))
+	}
+}
+
+func TestFormat_Update(t *testing.T) {
+	const previous = "1.0.0 (2024-01-01)\n==================\n\nAdded:\n  - initial release\n"
+
+	if got, err := FormatText.Update(nil, testRelease); assert.NoError(t, err) {
+		assert.Equal(t, testText, string(got))
+	}
+
+	if got, err := FormatText.Update([]byte(previous), testRelease); assert.NoError(t, err) {
+		assert.Equal(t, testText+"\n"+previous, string(got))
+	}
+
+	// updating twice replaces the release
+	if got, err := FormatText.Update([]byte(testText+"\n"+previous), testRelease); assert.NoError(t, err) {
+		assert.Equal(t, testText+"\n"+previous, string(got))
+	}
+
+	data, err := FormatJSON.Update(nil, Release{Version: "1.0.0", Date: testRelease.Date})
+	require.NoError(t, err)
+
+	data, err = FormatJSON.Update(data, testRelease)
+	require.NoError(t, err)
+
+	data, err = FormatJSON.Update(data, testRelease)
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)^\[\s*\{\s*"version": "1.1.0".*"version": "1.0.0".*\]\n$`, string(data))
+	assert.Equal(t, 1, strings.Count(string(data), `"version": "1.1.0"`))
+
+	_, err = FormatJSON.Update([]byte("{"), testRelease)
+	assert.ErrorContains(t, err, "invalid JSON changelog")
+}
+
+func TestFormat_UpdateUnreleased(t *testing.T) {
+	if got, err := FormatMarkdown.UpdateUnreleased(nil, testRelease); assert.NoError(t, err) {
+		assert.Equal(t, string(UpdateUnreleased(nil, testRelease)), string(got))
+	}
+
+	for _, f := range []Format{FormatText, FormatJSON} {
+		_, err := f.UpdateUnreleased(nil, testRelease)
+		assert.EqualError(t, err, "only markdown changelogs have an unreleased section")
+	}
+}
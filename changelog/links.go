@@ -0,0 +1,107 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sassoftware/gotagger/internal/git"
+)
+
+const (
+	hashPlaceholder     = "{hash}"
+	changeIDPlaceholder = "{changeId}"
+	issuePlaceholder    = "{id}"
+)
+
+// issueRe matches issue references, such as "#12", that are not part of
+// another word or link.
+var issueRe = regexp.MustCompile(`(^|[\s(])#(\d+)\b`)
+
+// Links are URL templates for linking the commits and issues mentioned in
+// markdown changelogs. The zero value links nothing.
+type Links struct {
+	// Commit is the URL of a commit, with "{hash}" in place of its hash,
+	// such as "https://github.com/org/repo/commit/{hash}". For Gerrit,
+	// "{changeId}" may be used in place of the Change-Id of the commit
+	// instead, such as "https://review.example.com/q/{changeId}", and commits
+	// without one are not linked.
+	Commit string `json:"commit,omitempty"`
+
+	// Issue is the URL of an issue, with "{id}" in place of its number,
+	// such as "https://github.com/org/repo/issues/{id}". References such as
+	// "#12" in the subjects of changes are linked.
+	Issue string `json:"issue,omitempty"`
+}
+
+// Validate returns an error if a template of l is set but does not contain
+// its placeholder.
+func (l Links) Validate() error {
+	if l.Commit != "" && !strings.Contains(l.Commit, hashPlaceholder) && !strings.Contains(l.Commit, changeIDPlaceholder) {
+		return fmt.Errorf("invalid commit link %q: must contain %s or %s", l.Commit, hashPlaceholder, changeIDPlaceholder)
+	}
+	if l.Issue != "" && !strings.Contains(l.Issue, issuePlaceholder) {
+		return fmt.Errorf("invalid issue link %q: must contain %s", l.Issue, issuePlaceholder)
+	}
+
+	return nil
+}
+
+// DetectLinks returns the Links of the repository with the remote URL
+// rawURL, if it is hosted by GitHub or GitLab, including self-hosted
+// instances whose host name contains "github" or "gitlab". Both https and ssh
+// remote URLs are supported. The zero Links are returned for other remotes.
+func DetectLinks(rawURL string) Links {
+	host, repo, ok := git.ParseRemoteURL(rawURL)
+	if !ok {
+		return Links{}
+	}
+
+	base := "https://" + host + "/" + repo
+	switch {
+	case strings.Contains(host, "github"):
+		return Links{
+			Commit: base + "/commit/" + hashPlaceholder,
+			Issue:  base + "/issues/" + issuePlaceholder,
+		}
+	case strings.Contains(host, "gitlab"):
+		return Links{
+			Commit: base + "/-/commit/" + hashPlaceholder,
+			Issue:  base + "/-/issues/" + issuePlaceholder,
+		}
+	}
+
+	return Links{}
+}
+
+// commit returns the markdown for the abbreviated hash short of the commit
+// hash, with the Gerrit Change-Id changeID, as a link if l has a commit
+// template and the commit has what it needs.
+func (l Links) commit(hash, changeID, short string) string {
+	if l.Commit == "" || hash == "" {
+		return short
+	}
+	if strings.Contains(l.Commit, changeIDPlaceholder) && changeID == "" {
+		return short
+	}
+
+	url := strings.ReplaceAll(l.Commit, hashPlaceholder, hash)
+	url = strings.ReplaceAll(url, changeIDPlaceholder, changeID)
+	return "[" + short + "](" + url + ")"
+}
+
+// issues returns s with its issue references replaced by markdown links, if
+// l has an issue template.
+func (l Links) issues(s string) string {
+	if l.Issue == "" {
+		return s
+	}
+
+	return issueRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := issueRe.FindStringSubmatch(m)
+		return sub[1] + "[#" + sub[2] + "](" + strings.ReplaceAll(l.Issue, issuePlaceholder, sub[2]) + ")"
+	})
+}
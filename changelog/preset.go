@@ -0,0 +1,135 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package changelog
+
+import (
+	"fmt"
+
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// Preset controls how changes are grouped into sections and how those
+// sections are titled.
+type Preset int
+
+const (
+	// PresetKeepAChangelog groups changes into the Added, Changed, and Fixed
+	// sections of keep-a-changelog.
+	PresetKeepAChangelog Preset = iota
+
+	// PresetAngular groups changes the way the Angular preset of
+	// conventional-changelog does, which eases migrating from tools like
+	// semantic-release.
+	PresetAngular
+)
+
+const (
+	sectionBreakingChanges = "BREAKING CHANGES"
+	sectionBugFixes        = "Bug Fixes"
+	sectionFeatures        = "Features"
+	sectionPerformance     = "Performance Improvements"
+
+	// topicPrefix precedes the topic in the titles of topic sections.
+	topicPrefix = "Topic: "
+)
+
+// ParsePreset converts a string into a Preset.
+// Valid values are "keep-a-changelog" and "angular".
+// The empty string is equivalent to "keep-a-changelog".
+func ParsePreset(s string) (Preset, error) {
+	switch s {
+	case "keep-a-changelog", "":
+		return PresetKeepAChangelog, nil
+	case "angular":
+		return PresetAngular, nil
+	}
+
+	return PresetKeepAChangelog, fmt.Errorf("invalid changelog preset '%s'", s)
+}
+
+// sections groups the notable changes in r according to its preset,
+// omitting empty sections. If r.GroupByTopic is set, then the notable changes
+// with a topic are listed in a section for their topic instead, after the
+// other sections, although the Angular preset still lists breaking changes in
+// their own section too.
+func (r Release) sections() []section {
+	order := []string{sectionAdded, sectionChanged, sectionFixed, sectionDependencies}
+	if r.Preset == PresetAngular {
+		order = []string{sectionFeatures, sectionBugFixes, sectionPerformance, sectionDependencies, sectionBreakingChanges}
+	}
+
+	grouped := map[string][]Change{}
+	for _, c := range r.Changes {
+		titles := r.Preset.sectionsFor(c)
+		if r.GroupByTopic && c.Topic != "" && len(titles) > 0 {
+			topic := topicPrefix + c.Topic
+			if _, ok := grouped[topic]; !ok {
+				order = append(order, topic)
+			}
+			grouped[topic] = append(grouped[topic], c)
+
+			// keep only the breaking changes section
+			var rest []string
+			for _, title := range titles {
+				if title == sectionBreakingChanges {
+					rest = append(rest, title)
+				}
+			}
+			titles = rest
+		}
+
+		for _, title := range titles {
+			grouped[title] = append(grouped[title], c)
+		}
+	}
+
+	var sections []section
+	for _, title := range order {
+		if len(grouped[title]) > 0 {
+			sections = append(sections, section{title: title, changes: grouped[title]})
+		}
+	}
+
+	return sections
+}
+
+// sectionsFor returns the titles of the sections that list c.
+func (p Preset) sectionsFor(c Change) []string {
+	if p == PresetAngular {
+		var titles []string
+		switch {
+		case c.Dependency:
+			titles = append(titles, sectionDependencies)
+		case c.Type == mapper.TypeFeature:
+			titles = append(titles, sectionFeatures)
+		case c.Type == mapper.TypeBugFix:
+			titles = append(titles, sectionBugFixes)
+		case c.Type == mapper.TypePerformance:
+			titles = append(titles, sectionPerformance)
+		}
+
+		// breaking changes are listed twice,
+		// under their type and in their own section
+		if c.Breaking {
+			titles = append(titles, sectionBreakingChanges)
+		}
+
+		return titles
+	}
+
+	switch {
+	case c.Breaking:
+		return []string{sectionChanged}
+	case c.Dependency:
+		return []string{sectionDependencies}
+	case c.Type == mapper.TypeFeature:
+		return []string{sectionAdded}
+	case c.Type == mapper.TypeBugFix:
+		return []string{sectionFixed}
+	case c.Type == mapper.TypePerformance, c.Type == mapper.TypeRefactor:
+		return []string{sectionChanged}
+	}
+
+	return nil
+}
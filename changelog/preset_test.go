@@ -0,0 +1,147 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAngular = `## 1.1.0 (2024-06-01)
+
+### Features
+
+* add bar (0123456)
+* **BREAKING:** remove baz (1111111)
+
+### Bug Fixes
+
+* **foo:** fix foo (fedcba9)
+
+### BREAKING CHANGES
+
+* **BREAKING:** remove baz (1111111)
+`
+
+func TestParsePreset(t *testing.T) {
+	for s, want := range map[string]Preset{
+		"":                 PresetKeepAChangelog,
+		"keep-a-changelog": PresetKeepAChangelog,
+		"angular":          PresetAngular,
+	} {
+		if got, err := ParsePreset(s); assert.NoError(t, err) {
+			assert.Equal(t, want, got)
+		}
+	}
+
+	_, err := ParsePreset("atom")
+	assert.EqualError(t, err, "invalid changelog preset 'atom'")
+}
+
+func TestRelease_Markdown_angular(t *testing.T) {
+	r := testRelease
+	r.Preset = PresetAngular
+	r.Changes = append(r.Changes,
+		Change{Type: "perf", Subject: "faster foo"},
+		Change{Type: "build", Scope: "deps", Subject: "bump qux from 1.0.0 to 1.1.0", Dependency: true},
+	)
+
+	want := `## 1.1.0 (2024-06-01)
+
+### Features
+
+* add bar (0123456)
+* **BREAKING:** remove baz (1111111)
+
+### Bug Fixes
+
+* **foo:** fix foo (fedcba9)
+
+### Performance Improvements
+
+* faster foo
+
+### Dependencies
+
+* **deps:** bump qux from 1.0.0 to 1.1.0
+
+### BREAKING CHANGES
+
+* **BREAKING:** remove baz (1111111)
+`
+	assert.Equal(t, want, r.Markdown())
+}
+
+func TestRelease_Markdown_topics(t *testing.T) {
+	r := Release{
+		Version: "1.1.0",
+		Date:    testRelease.Date,
+		Changes: []Change{
+			{Type: "feat", Subject: "add bar", Hash: "0123456789abcdef", Topic: "bar"},
+			{Type: "fix", Subject: "fix foo", Hash: "fedcba9876543210"},
+			{Type: "feat", Subject: "remove baz", Breaking: true, Hash: "1111111111111111", Topic: "baz"},
+			{Type: "fix", Subject: "fix bar", Hash: "3333333333333333", Topic: "bar"},
+			{Type: "chore", Subject: "update tooling", Hash: "2222222222222222", Topic: "tooling"},
+		},
+	}
+
+	// topics are ignored unless grouping is enabled
+	assert.NotContains(t, r.Markdown(), topicPrefix)
+
+	r.GroupByTopic = true
+	want := `## [1.1.0] - 2024-06-01
+
+### Fixed
+
+- fix foo (fedcba9)
+
+### Topic: bar
+
+- add bar (0123456)
+- fix bar (3333333)
+
+### Topic: baz
+
+- **BREAKING:** remove baz (1111111)
+`
+	assert.Equal(t, want, r.Markdown())
+
+	// breaking changes are still listed in their own section
+	r.Preset = PresetAngular
+	want = `## 1.1.0 (2024-06-01)
+
+### Bug Fixes
+
+* fix foo (fedcba9)
+
+### BREAKING CHANGES
+
+* **BREAKING:** remove baz (1111111)
+
+### Topic: bar
+
+* add bar (0123456)
+* fix bar (3333333)
+
+### Topic: baz
+
+* **BREAKING:** remove baz (1111111)
+`
+	assert.Equal(t, want, r.Markdown())
+}
+
+func TestUpdate_angular(t *testing.T) {
+	r := testRelease
+	r.Preset = PresetAngular
+
+	const previous = "## 1.0.0 (2024-01-01)\n\n### Features\n\n* initial release\n"
+
+	got := Update([]byte("# Changelog\n\n"+previous), r)
+	assert.Equal(t, "# Changelog\n\n"+testAngular+"\n"+previous, string(got))
+
+	// updating again replaces the release
+	got = Update(got, r)
+	assert.Equal(t, "# Changelog\n\n"+testAngular+"\n"+previous, string(got))
+}
@@ -0,0 +1,147 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// TagCheck compares a version tag at HEAD with the version gotagger
+// calculates for HEAD.
+type TagCheck struct {
+	// Module is the name of the go module, if any.
+	Module string
+
+	// Path is the path to the module or path filter,
+	// relative to the root of the repository.
+	Path string
+
+	// Tag is the version tag at HEAD.
+	Tag string
+
+	// Version is the calculated version, including its prefix.
+	Version string
+}
+
+// Matches returns true if the tag at HEAD is the calculated version.
+func (c TagCheck) Matches() bool {
+	return c.Tag == c.Version
+}
+
+// CheckTags compares the version tags at HEAD with the versions gotagger
+// calculates for HEAD when the tags at HEAD are ignored. A mismatch means
+// that HEAD was tagged by hand, or by a pipeline with a different
+// configuration.
+//
+// A TagCheck is returned for every module, or path in Config.Paths if go
+// modules are ignored, that has a version tag at HEAD. If a module has more
+// than one, then the tag that matches is used, or else the highest.
+// The release options in the footers of HEAD, such as promoting a version,
+// are applied, but the commit count and dirty worktree suffix are not.
+func (g *Gotagger) CheckTags() ([]TagCheck, error) {
+	if err := g.checkCommits(); err != nil {
+		return nil, err
+	}
+
+	tags, err := g.repo.TagsAt(head)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tags) == 0 {
+		g.logger.Info("no tags at HEAD to check")
+		return nil, nil
+	}
+
+	var modules []module
+	if !g.Config.IgnoreModules {
+		if modules, err = g.findAllModules(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := g.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := g.extractReleaseOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	opts.tagsOnly = true
+	opts.ignoreTags = map[string]bool{}
+	for _, tag := range tags {
+		opts.ignoreTags[tag] = true
+	}
+
+	results, err := g.results(modules, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []TagCheck
+	for i, res := range results {
+		if tag := tagFor(results, i, tags); tag != "" {
+			checks = append(checks, TagCheck{
+				Module:  res.Module,
+				Path:    res.Path,
+				Tag:     tag,
+				Version: res.Version,
+			})
+		}
+	}
+
+	return checks, nil
+}
+
+// tagFor returns the tag in tags for results[i]. Tags of the major version of
+// another result with the same prefix, such as a module foo/v2 next to a
+// module foo, belong to that result. It prefers the tag of the calculated
+// version, then the highest version.
+func tagFor(results []Result, i int, tags []string) string {
+	res := results[i]
+
+	// the major versions that belong to other results
+	others := map[uint64]bool{}
+	for j, other := range results {
+		if v, ok := resultVersion(other); ok && j != i && other.Prefix == res.Prefix {
+			others[v.Major()] = true
+		}
+	}
+	if v, ok := resultVersion(res); ok {
+		delete(others, v.Major())
+	}
+
+	var best string
+	var bestVersion *semver.Version
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, res.Prefix) {
+			continue
+		}
+
+		v, err := semver.NewVersion(strings.TrimPrefix(tag, res.Prefix))
+		if err != nil || others[v.Major()] {
+			continue
+		}
+
+		if tag == res.Version {
+			return tag
+		}
+
+		if bestVersion == nil || v.GreaterThan(bestVersion) {
+			best, bestVersion = tag, v
+		}
+	}
+
+	return best
+}
+
+// resultVersion returns the calculated version of res without its prefix.
+func resultVersion(res Result) (*semver.Version, bool) {
+	v, err := semver.NewVersion(strings.TrimPrefix(res.Version, res.Prefix))
+	return v, err == nil
+}
@@ -0,0 +1,154 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sassoftware/gotagger"
+)
+
+// defaultReleaseNotesFile is the release notes file that -ci writes.
+const defaultReleaseNotesFile = "RELEASE_NOTES.md"
+
+// setCIDefaults sets the flags that -ci implies, unless they are set by the
+// command-line or the environment: -release, -fetch-tags and -push unless
+// -offline is set, -github-output to $GITHUB_OUTPUT, and -release-notes.
+func (g *GoTagger) setCIDefaults(fs *flag.FlagSet) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	explicit := func(name string) bool {
+		if set[name] {
+			return true
+		}
+		_, ok := g.getEnv(strings.ReplaceAll(name, "-", "_"))
+		return ok
+	}
+
+	if !explicit("release") {
+		g.tagRelease = true
+	}
+	if !g.offline {
+		if !explicit("fetch-tags") {
+			g.fetchTags = true
+		}
+		if !explicit("push") {
+			g.pushTag = true
+		}
+	}
+	if !explicit("github-output") {
+		g.githubOutput = g.osEnv("GITHUB_OUTPUT")
+	}
+	if !explicit("release-notes") {
+		g.releaseNotes = defaultReleaseNotesFile
+	}
+}
+
+// skipUnreleasedBranch stops r from creating tags if HEAD is not on one of
+// the configured release branches, so that -ci can run on every branch.
+func (g *GoTagger) skipUnreleasedBranch(r *gotagger.Gotagger) error {
+	if !r.Config.CreateTag {
+		return nil
+	}
+
+	ok, err := r.OnReleaseBranch()
+	if err != nil || ok {
+		return err
+	}
+
+	if !g.quiet {
+		g.err.Println("HEAD is not on a release branch, so no tags will be created")
+	}
+	r.Config.CreateTag = false
+	r.Config.PushTag = false
+
+	return nil
+}
+
+// writeReleaseNotes writes the changelog sections of the planned tags to
+// -release-notes, and returns the path it wrote. Nothing is written if no
+// tags are planned.
+func (g *GoTagger) writeReleaseNotes(planned []gotagger.PlannedTag) (string, error) {
+	var notes [][]byte
+	for _, tag := range planned {
+		if len(tag.Changelog) > 0 {
+			notes = append(notes, bytes.TrimSpace(tag.Changelog))
+		}
+	}
+	if g.releaseNotes == "" || len(notes) == 0 {
+		return "", nil
+	}
+
+	fn := g.releaseNotes
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(g.WorkingDir, fn)
+	}
+
+	data := append(bytes.Join(notes, []byte("\n\n")), '\n')
+	if err := os.WriteFile(fn, data, 0o644); err != nil {
+		return "", err
+	}
+
+	return g.releaseNotes, nil
+}
+
+// writeGitHubOutput appends the versions of results, the tags that were
+// created, and the release notes file to -github-output, in the format of
+// GitHub Actions step outputs.
+func (g *GoTagger) writeGitHubOutput(results []gotagger.Result, created []string, notes string) error {
+	if g.githubOutput == "" {
+		return nil
+	}
+
+	versions := make([]string, len(results))
+	for i, res := range results {
+		versions[i] = res.Version
+	}
+	var version string
+	if len(versions) > 0 {
+		version = versions[0]
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "version=%s\n", version)
+	fmt.Fprintf(&out, "versions=%s\n", strings.Join(versions, " "))
+	fmt.Fprintf(&out, "released=%t\n", len(created) > 0)
+	fmt.Fprintf(&out, "tags=%s\n", strings.Join(created, " "))
+	fmt.Fprintf(&out, "release-notes=%s\n", notes)
+
+	fn := g.githubOutput
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(g.WorkingDir, fn)
+	}
+
+	f, err := os.OpenFile(fn, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(out.String()); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// osEnv returns the value of the environment variable name.
+func (g *GoTagger) osEnv(name string) string {
+	name += "="
+	for i := len(g.Env) - 1; i >= 0; i-- {
+		if val, ok := strings.CutPrefix(g.Env[i], name); ok {
+			return val
+		}
+	}
+
+	return ""
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"runtime/pprof"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/go-logr/zerologr"
@@ -26,6 +28,7 @@ import (
 const (
 	successExitCode      = 0
 	genericErrorExitCode = 1
+	checkFailedExitCode  = 2
 
 	versionOutput = `gotagger:
  version     : %s
@@ -36,8 +39,15 @@ const (
  platform    : %s/%s
 `
 
+	// ldflagsFormat sets the version variables of gotagger itself
+	ldflagsFormat = `-ldflags "-X main.AppVersion=%s -X main.Commit=%s -X main.BuildDate=%s"`
+
+	// badgeFile is the name of the badge files written by -badge-dir
+	badgeFile = "badge.json"
+
 	defaultConfigFlag  = "gotagger.json"
 	defaultDirtyFlag   = "none"
+	defaultFormatFlag  = "text"
 	defaultModulesFlag = true
 	defaultPrefixFlag  = "v"
 	defaultRemoteFlag  = "origin"
@@ -61,17 +71,64 @@ type GoTagger struct {
 	out *log.Logger
 	err *log.Logger
 
+	// names of the environment variables that set flags,
+	// without the GOTAGGER_ prefix
+	envNames map[string]bool
+
 	// command-line options
+	all            bool
+	badgeDir       string
+	base           string
+	changelog      bool
+	check          bool
+	checkUpstream  bool
+	ci             bool
+	commit         string
+	commitsSince   bool
+	committed      bool
 	configFile     string
+	currentVersion string
 	debug          bool
 	dirtyIncrement string
+	dirtySuffix    string
+	dryRun         bool
+	fetchTags      bool
+	followTags     bool
 	force          bool
+	helpEnv        bool
+	messageFile    string
 	modules        bool
+	moduleNames    []string
+	moduleOrder    string
+	floatingTags   string
+	forceFloating  bool
+	format         string
+	githubOutput   string
+	nextTag        bool
+	nightly        bool
+	nightlyTag     string
+	offline        bool
+	outsideChanges string
 	pathFilter     string
+	promote        bool
+	pushOptions    []string
 	pushTag        bool
+	pushUsername   string
+	quiet          bool
+	ref            string
+	releaseNotes   string
 	remoteName     string
+	reproducible   bool
 	showVersion    bool
+	tagDate        string
+	tagNamespace   string
 	tagRelease     bool
+	traceFile      string
+	unreleased     bool
+	update         string
+	verbosity      int
+	verifyTags     string
+	versionFile    string
 	versionPrefix  string
 }
 
@@ -84,41 +141,149 @@ func (g *GoTagger) Run() int {
 	flags := flag.NewFlagSet(AppName, flag.ContinueOnError)
 	flags.SetOutput(g.Stderr)
 
+	flags.BoolVar(&g.all, "all", g.boolEnv("all", false), "print the name and version of every module, or of the path filter")
+	flags.StringVar(&g.badgeDir, "badge-dir", g.stringEnv("badge_dir", ""), "write a shields.io endpoint badge of the version of each module or path to badge.json in its directory under this directory")
+	flags.StringVar(&g.base, "base", g.stringEnv("base", ""), "with preview, the branch that HEAD would be merged into, such as origin/main (default: the default branch of the remote)")
+	flags.BoolVar(&g.changelog, "changelog", g.boolEnv("changelog", false), "add the changes since the latest version to the CHANGELOG.md of each module")
+	flags.BoolVar(&g.check, "check", g.boolEnv("check", false), "check that the version tags at HEAD match the calculated versions, and exit with code 2 if they do not")
+	flags.BoolVar(&g.checkUpstream, "check-upstream", g.boolEnv("check_upstream", false), "refuse to create tags unless HEAD matches its upstream branch")
+	flags.BoolVar(&g.ci, "ci", g.boolEnv("ci", false), "run a complete CI release: fetch tags, print versions, and if HEAD is a release commit on a release branch, create and push tags and write release notes and GitHub Actions outputs. implies -fetch-tags, -push, -github-output, and -release-notes, which can each be overridden")
+	flags.StringVar(&g.commit, "commit", g.stringEnv("commit", ""), "version and tag this commit, such as the commit that CI built, instead of HEAD. it must be an ancestor of HEAD unless -force is set")
+	flags.BoolVar(&g.committed, "committed-modules", g.boolEnv("committed_modules", false), "discover go modules from the committed tree instead of the worktree")
+	flags.BoolVar(&g.commitsSince, "commits-since", g.boolEnv("commits_since", false), "add the number of commits since the latest version as a pre-release identifier")
 	flags.StringVar(&g.configFile, "config", g.stringEnv("config", defaultConfigFlag), "path to the gotagger configuration file.")
+	flags.StringVar(&g.currentVersion, "current-version", g.stringEnv("current_version", ""), "latest version of modules and paths with no version tags, such as when migrating from manual versioning")
 	flags.StringVar(&g.dirtyIncrement, "dirty", g.stringEnv("dirty", defaultDirtyFlag), "how to increment the version for a dirty checkout [minor, patch, none]")
-	flags.BoolVar(&g.debug, "debug", false, "enable debug output")
+	flags.StringVar(&g.dirtySuffix, "dirty-suffix", g.stringEnv("dirty_suffix", ""), "pre-release or build metadata suffix to add to the version for a dirty checkout, such as -dirty")
+	flags.BoolVar(&g.debug, "debug", g.boolEnv("debug", false), "enable debug output, the same as -vv")
+	flags.BoolVar(&g.dryRun, "dry-run", g.boolEnv("dry_run", false), "print the tags a release would create, with their messages and changelogs, without changing anything")
+	flags.BoolVar(&g.fetchTags, "fetch-tags", g.boolEnv("fetch_tags", false), "fetch version tags from the remote, and the complete history of a shallow clone, before calculating versions")
+	flags.StringVar(&g.floatingTags, "floating-tags", g.stringEnv("floating_tags", ""), "move floating tags, such as v1 or v1.2, to each release [none, major, minor]")
+	flags.StringVar(&g.format, "format", g.stringEnv("format", defaultFormatFlag), "how to print versions [text, json, ldflags, provenance, badge]. json prints the results as described by schema.json. ldflags prints go build flags that set main.AppVersion, main.Commit, and main.BuildDate. provenance prints a JSON provenance document. badge prints a shields.io endpoint badge of each version on its own line")
+	flags.BoolVar(&g.followTags, "follow-tags", g.boolEnv("follow_tags", false), "push tags using git push --follow-tags")
 	flags.BoolVar(&g.force, "force", g.boolEnv("force", false), "force creation of a tag")
+	flags.BoolVar(&g.forceFloating, "force-floating-tags", g.boolEnv("force_floating_tags", false), "force push floating tags, replacing them on the remote")
+	flags.StringVar(&g.githubOutput, "github-output", g.stringEnv("github_output", ""), "append the versions, created tags, and release notes file to this GitHub Actions output file. with -ci, defaults to $GITHUB_OUTPUT")
+	flags.StringVar(&g.messageFile, "message-file", g.stringEnv("message_file", ""), "with validate-release, check the release commit message in this file as if it were committed on top of HEAD")
 	flags.BoolVar(&g.modules, "modules", g.boolEnv("modules", defaultModulesFlag), "enable go module versioning")
-	flags.StringVar(&g.pathFilter, "path", "", "filter commits by path")
+	flags.StringVar(&g.moduleOrder, "module-order", g.stringEnv("module_order", ""), "order in which the versions of go modules are printed [path, name]")
+	flags.BoolVar(&g.nextTag, "next-tag", g.boolEnv("next_tag", false), "print the tags that would be created for HEAD, without build suffixes, and exit")
+	flags.BoolVar(&g.nightly, "nightly", g.boolEnv("nightly", false), "add nightly pre-release identifiers with the current date, such as v1.3.0-nightly.20240615. nightly versions are not tagged")
+	flags.StringVar(&g.nightlyTag, "nightly-tag", g.stringEnv("nightly_tag", ""), "with -nightly and -release, move this tag, such as nightly, to HEAD instead of creating version tags")
+	flags.BoolVar(&g.offline, "offline", g.boolEnv("offline", false), "guarantee that no network operations are made, and fail before changing anything if one is needed, such as with -push")
+	flags.StringVar(&g.outsideChanges, "outside-changes", g.stringEnv("outside_changes", ""), "warn or fail when the commits since the latest version of a module change files outside of modules [ignore, warn, fail]")
+	flags.BoolVar(&g.helpEnv, "help-env", false, "show the environment variables that set flags")
+	flags.StringVar(&g.pathFilter, "path", g.stringEnv("path", ""), "filter commits by path")
+	flags.BoolVar(&g.promote, "promote", g.boolEnv("promote", false), "promote a 0.x.y version to 1.0.0")
 	flags.BoolVar(&g.pushTag, "push", g.boolEnv("push", false), "push the just created tag, implies -release")
+	envModuleNames := g.listEnv("module")
+	flags.Func("module", "only print the version of this module, by module path, alias, or directory. may be repeated", func(s string) error {
+		g.moduleNames = append(g.moduleNames, s)
+		return nil
+	})
+	envPushOptions := g.listEnv("push_option")
+	flags.Func("push-option", "push option to send to the remote when pushing tags. may be repeated", func(s string) error {
+		g.pushOptions = append(g.pushOptions, s)
+		return nil
+	})
+	flags.StringVar(&g.pushUsername, "push-username", g.stringEnv("push_username", ""), "user name for token authentication when pushing tags. the token is read from GOTAGGER_PUSH_TOKEN")
+	flags.BoolVar(&g.quiet, "q", false, "only print versions and errors, the same as -quiet")
+	flags.BoolVar(&g.quiet, "quiet", g.boolEnv("quiet", false), "only print versions and errors")
+	flags.StringVar(&g.ref, "ref", g.stringEnv("ref", ""), "with validate-release, the release commit to check instead of HEAD")
+	flags.StringVar(&g.releaseNotes, "release-notes", g.stringEnv("release_notes", ""), "write the changelog sections of created tags to this file. with -ci, defaults to "+defaultReleaseNotesFile)
+	flags.BoolVar(&g.reproducible, "reproducible", g.boolEnv("reproducible", false), "produce identical output on every run against the same commit, by dating outputs with its committer date and ignoring the worktree")
 	flags.StringVar(&g.remoteName, "remote", g.stringEnv("remote", defaultRemoteFlag), "name of the remote to push tags to")
 	flags.BoolVar(&g.showVersion, "version", false, "show version information")
+	flags.StringVar(&g.tagDate, "tag-date", g.stringEnv("tag_date", ""), "date of created tags: \"author\" or \"committer\" for the date of the tagged commit, or an RFC 3339 date. defaults to now")
+	flags.StringVar(&g.tagNamespace, "tag-namespace", g.stringEnv("tag_namespace", ""), "ref namespace of version tags, such as refs/releases/")
+	flags.StringVar(&g.traceFile, "trace-file", g.stringEnv("trace_file", ""), "write a JSON record of every input to the version calculation, such as tags, commits, and configuration, to this file")
 	flags.BoolVar(&g.tagRelease, "release", g.boolEnv("release", false), "tag HEAD with the current version if it is a release commit")
+	flags.BoolVar(&g.unreleased, "unreleased", g.boolEnv("unreleased", false), "with changelog, replace the Unreleased section of changelogs with the changes since the latest version")
+	flags.StringVar(&g.update, "update", g.stringEnv("update", ""), "with changelog, only update this changelog file, with the changes of the module or path that contains it")
+	flags.BoolFunc("v", "log the changes gotagger makes, such as created and pushed tags, the same as -verbosity=1", func(string) error {
+		g.verbosity = 1
+		return nil
+	})
+	flags.IntVar(&g.verbosity, "verbosity", g.intEnv("verbosity", 0), "log verbosity: 0 logs nothing, 1 logs changes, 2 adds debug output, 3 adds trace output")
+	flags.BoolFunc("vv", "log the changes gotagger makes and debug output, the same as -verbosity=2", func(string) error {
+		g.verbosity = 2
+		return nil
+	})
+	flags.StringVar(&g.verifyTags, "verify-tags", g.stringEnv("verify_tags", ""), "verify version tag signatures and skip or fail on tags that cannot be verified [none, skip, fail]")
+	flags.StringVar(&g.versionFile, "version-file", g.stringEnv("version_file", ""), "file, such as VERSION, in each module or path that holds its latest version if it has no version tags")
 	flags.StringVar(&g.versionPrefix, "prefix", g.stringEnv("prefix", defaultPrefixFlag), "set a prefix for versions")
 
 	// profiling options
-	cpuprofile := flags.String("cpuprofile", "", "write cpu profile to file")
-	memprofile := flags.String("memprofile", "", "write memory profile to file")
+	cpuprofile := flags.String("cpuprofile", g.stringEnv("cpuprofile", ""), "write cpu profile to file")
+	memprofile := flags.String("memprofile", g.stringEnv("memprofile", ""), "write memory profile to file")
+
+	if len(g.Args) > 0 && g.Args[0] == "migrate" {
+		return g.runMigrate(g.Args[1:])
+	}
+
+	if len(g.Args) > 0 && g.Args[0] == "serve" {
+		return g.runServe(g.Args[1:])
+	}
+
+	// resume, backfill, validate-release, changelog, and preview share the options of the main command
+	args := g.Args
+	resume := len(args) > 0 && args[0] == "resume"
+	backfill := len(args) > 0 && args[0] == "backfill"
+	validate := len(args) > 0 && args[0] == "validate-release"
+	changelogCmd := len(args) > 0 && args[0] == "changelog"
+	preview := len(args) > 0 && args[0] == "preview"
+	if resume || backfill || validate || changelogCmd || preview {
+		args = args[1:]
+	}
 
 	g.setUsage(flags)
-	if err := flags.Parse(g.Args); err != nil {
+	if err := flags.Parse(args); err != nil {
+		return genericErrorExitCode
+	}
+
+	// lists from the command-line replace those from the environment
+	if len(g.moduleNames) == 0 {
+		g.moduleNames = envModuleNames
+	}
+	if len(g.pushOptions) == 0 {
+		g.pushOptions = envPushOptions
+	}
+
+	if g.ci {
+		g.setCIDefaults(flags)
+	}
+
+	if g.debug && g.verbosity < 2 {
+		g.verbosity = 2
+	}
+	if g.quiet && g.verbosity > 0 {
+		g.err.Println("error: -quiet cannot be used with -v, -vv, -verbosity, or -debug")
 		return genericErrorExitCode
 	}
 
-	zerolog.SetGlobalLevel(zerolog.Disabled)
-	if g.debug {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	// logr V-levels 0, 1, and 2 map to the info, debug, and trace levels.
+	// the level is set on the logger instead of globally,
+	// so that concurrent runs do not affect each other
+	level := zerolog.Disabled
+	switch {
+	case g.verbosity == 1:
+		level = zerolog.InfoLevel
+	case g.verbosity == 2:
+		level = zerolog.DebugLevel
+	case g.verbosity > 2:
+		level = zerolog.TraceLevel
 	}
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
 	zerologr.NameFieldName = "logger"
 	zerologr.NameSeparator = "/"
 	zl := zerolog.New(zerolog.ConsoleWriter{Out: g.Stderr, TimeFormat: time.StampMicro})
-	zl = zl.With().Caller().Timestamp().Logger()
+	zl = zl.With().Caller().Timestamp().Logger().Level(level)
 
 	rootLogger := zerologr.New(&zl)
 
-	// we only really log debug messages,
-	// so force the V-level to 1
+	// the changes gotagger makes are logged at V-level 0 by the library,
+	// while the messages of main are only diagnostics,
+	// so they are logged at V-level 1
 	logger := rootLogger.WithName("main").V(1)
 
 	if *cpuprofile != "" {
@@ -161,6 +326,16 @@ func (g *GoTagger) Run() int {
 		return successExitCode
 	}
 
+	if g.helpEnv {
+		g.printEnv(flags)
+		return successExitCode
+	}
+
+	if g.format != "text" && g.format != "json" && g.format != "ldflags" && g.format != "provenance" && g.format != "badge" {
+		g.err.Printf("error: invalid format '%s'\n", g.format)
+		return genericErrorExitCode
+	}
+
 	// Find the git repo
 	path := flags.Arg(0)
 	if path == "" {
@@ -206,10 +381,34 @@ func (g *GoTagger) Run() int {
 		}
 	}
 
+	r.Config.CheckUpstream = g.checkUpstream
+	r.Config.CommitsSince = g.commitsSince
+	if g.committed {
+		r.Config.CommittedModules = true
+	}
 	r.Config.CreateTag = g.tagRelease || g.pushTag || g.force
+	r.Config.CurrentVersion = g.currentVersion
 	r.Config.Force = g.force
+	r.Config.Nightly = g.nightly
+	r.Config.NightlyTag = g.nightlyTag
+	r.Config.Offline = g.offline
+	r.Config.Reproducible = g.reproducible
+	r.Config.Promote = g.promote
 	r.Config.PushTag = g.pushTag
+	if g.followTags {
+		r.Config.PushOptions.FollowTags = true
+	}
+	if len(g.pushOptions) > 0 {
+		r.Config.PushOptions.Options = g.pushOptions
+	}
+	if token, ok := g.getEnv("push_token"); ok {
+		r.Config.PushOptions.Token = token
+	}
+	if g.pushUsername != "" {
+		r.Config.PushOptions.Username = g.pushUsername
+	}
 	r.Config.RemoteName = g.remoteName
+	r.Config.Trace = g.traceFile != ""
 
 	//nolint: gosimple // makes this consistent with other flags,
 	// and avoids hard to understand double negatives
@@ -217,7 +416,12 @@ func (g *GoTagger) Run() int {
 		r.Config.IgnoreModules = !g.modules
 	}
 	if g.versionPrefix != defaultPrefixFlag {
-		r.Config.VersionPrefix = g.versionPrefix
+		prefix, err := gotagger.NormalizeVersionPrefix(g.versionPrefix)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.VersionPrefix = prefix
 	}
 	if g.dirtyIncrement != defaultDirtyFlag {
 		inc, err := mapper.Convert(g.dirtyIncrement)
@@ -232,13 +436,218 @@ func (g *GoTagger) Run() int {
 		}
 		r.Config.DirtyWorktreeIncrement = inc
 	}
+	if g.dirtySuffix != "" {
+		if err := gotagger.ValidateDirtyWorktreeSuffix(g.dirtySuffix); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.DirtyWorktreeSuffix = g.dirtySuffix
+	}
+	if g.floatingTags != "" {
+		policy, err := gotagger.ParseFloatingTags(g.floatingTags)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.FloatingTags = policy
+	}
+	if g.forceFloating {
+		r.Config.ForcePushFloatingTags = true
+	}
+	if g.moduleOrder != "" {
+		order, err := gotagger.ParseModuleOrder(g.moduleOrder)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.ModuleOrder = order
+	}
+	if g.outsideChanges != "" {
+		policy, err := gotagger.ParseOutsideChanges(g.outsideChanges)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.OutsideChanges = policy
+	}
+	if g.tagDate != "" {
+		date, err := gotagger.NormalizeTagDate(g.tagDate)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.TagDate = date
+	}
+	if g.tagNamespace != "" {
+		ns, err := gotagger.NormalizeTagNamespace(g.tagNamespace)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.TagNamespace = ns
+	}
+	if g.verifyTags != "" {
+		policy, err := gotagger.ParseTagVerification(g.verifyTags)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.VerifyTags = policy
+	}
+	if g.versionFile != "" {
+		name, err := gotagger.NormalizeVersionFile(g.versionFile)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		r.Config.VersionFile = name
+	}
 	if g.pathFilter != "" {
 		r.Config.Paths = []string{g.pathFilter}
 	}
 
+	if g.fetchTags {
+		logger.Info("fetching tags", "remote", r.Config.RemoteName)
+		if err := r.FetchTags(); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+	}
+
+	if resume {
+		logger.Info("resuming interrupted release")
+		tags, err := r.Resume()
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+
+		for _, tag := range tags {
+			g.out.Println(tag)
+		}
+
+		return successExitCode
+	}
+
+	if backfill {
+		logger.Info("backfilling release tags")
+		tags, err := r.Backfill()
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+
+		for _, tag := range tags {
+			g.out.Println(tag.Tag, tag.Commit)
+		}
+
+		return successExitCode
+	}
+
+	if validate {
+		return g.validateRelease(r)
+	}
+
+	if changelogCmd {
+		return g.writeChangelogs(r)
+	}
+
+	if preview {
+		return g.preview(r)
+	}
+
+	if g.dryRun {
+		return g.printDryRun(r)
+	}
+
+	if g.check {
+		if r.Config.CreateTag {
+			g.err.Println("error: -check cannot be used to create tags")
+			return genericErrorExitCode
+		}
+
+		return g.checkTags(r)
+	}
+
+	if g.all || len(g.moduleNames) > 0 {
+		if r.Config.CreateTag {
+			g.err.Println("error: -all and -module cannot be used to create tags")
+			return genericErrorExitCode
+		}
+
+		var names []string
+		if len(g.moduleNames) > 0 {
+			names, err = resolveModules(r, g.moduleNames)
+			if err != nil {
+				g.err.Println("error:", err)
+				return genericErrorExitCode
+			}
+		}
+
+		results, err := r.Results(names...)
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		g.printWarnings(results)
+		if err := g.printResults(r, results); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		if err := g.writeTrace(r, results); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		if err := g.writeBadges(r, results); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+
+		return successExitCode
+	}
+
+	if g.nextTag {
+		tags, err := r.NextTags()
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+
+		for _, tag := range tags {
+			g.out.Println(tag)
+		}
+
+		return successExitCode
+	}
+
+	if g.changelog {
+		written, err := r.WriteChangelogs()
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		logger.Info("wrote changelogs", "paths", written)
+	}
+
+	if g.ci {
+		if err := g.skipUnreleasedBranch(r); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+	}
+
+	// plan the release first to know the tags and changelogs it creates
+	var planned []gotagger.PlannedTag
+	if r.Config.CreateTag && (g.githubOutput != "" || g.releaseNotes != "") {
+		if planned, err = r.DryRunAt(g.target()); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+	}
+
 	start := time.Now()
 	logger.Info("calculating version", "start", start)
-	versions, err := r.TagRepo()
+	results, err := r.TagRepoResultsAt(g.target())
 	dur := time.Since(start)
 	logger.Info("done calculating version", "duration", dur)
 
@@ -247,15 +656,468 @@ func (g *GoTagger) Run() int {
 		return genericErrorExitCode
 	}
 
-	for _, version := range versions {
-		g.out.Println(version)
+	var created []string
+	for _, res := range results {
+		if res.Tagged {
+			created = append(created, res.Tag)
+		}
+	}
+	notes, err := g.writeReleaseNotes(planned)
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+	if err := g.writeGitHubOutput(results, created, notes); err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+	g.printWarnings(results)
+	if err := g.printResults(r, results); err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+	if err := g.writeTrace(r, results); err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+	if err := g.writeBadges(r, results); err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+	if r.Config.CreateTag && !r.Config.Nightly {
+		g.printSummary(results)
 	}
 
 	return successExitCode
 }
 
+// target returns the commit to version and tag, as set by -commit.
+func (g *GoTagger) target() string {
+	if g.commit == "" {
+		return "HEAD"
+	}
+
+	return g.commit
+}
+
+// writeTrace writes the trace of results to -trace-file, if it is set.
+func (g *GoTagger) writeTrace(r *gotagger.Gotagger, results []gotagger.Result) error {
+	if g.traceFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.Trace(results), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fn := g.traceFile
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(g.WorkingDir, fn)
+	}
+
+	return os.WriteFile(fn, append(data, '\n'), 0o644)
+}
+
+// writeBadges writes a badge of each of results to badge.json in its
+// directory under -badge-dir, if it is set.
+func (g *GoTagger) writeBadges(r *gotagger.Gotagger, results []gotagger.Result) error {
+	if g.badgeDir == "" {
+		return nil
+	}
+
+	dir := g.badgeDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.WorkingDir, dir)
+	}
+
+	for i, badge := range r.Badges(results) {
+		data, err := json.Marshal(badge)
+		if err != nil {
+			return err
+		}
+
+		fn := filepath.Join(dir, results[i].Path, badgeFile)
+		if err := os.MkdirAll(filepath.Dir(fn), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fn, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printResults prints the version of each result in the -format format.
+// With -all, text results are prefixed by the module name or path.
+func (g *GoTagger) printResults(r *gotagger.Gotagger, results []gotagger.Result) error {
+	if g.format == "provenance" {
+		provenance, err := r.Provenance(results)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(provenance, "", "  ")
+		if err != nil {
+			return err
+		}
+		g.out.Println(string(data))
+
+		return nil
+	}
+
+	if g.format == "json" {
+		if results == nil {
+			results = []gotagger.Result{}
+		}
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		g.out.Println(string(data))
+
+		return nil
+	}
+
+	if g.format == "badge" {
+		for _, badge := range r.Badges(results) {
+			data, err := json.Marshal(badge)
+			if err != nil {
+				return err
+			}
+			g.out.Println(string(data))
+		}
+
+		return nil
+	}
+
+	for _, res := range results {
+		switch {
+		case g.format == "ldflags":
+			date, err := r.Date(res.Commit)
+			if err != nil {
+				return err
+			}
+			g.out.Printf(ldflagsFormat+"\n", res.Version, res.Commit, date.Format(time.DateOnly))
+		case g.all:
+			g.out.Println(resultName(res), res.Version)
+		default:
+			g.out.Println(res.Version)
+		}
+	}
+
+	return nil
+}
+
+// printWarnings prints the warnings of results to stderr, unless -quiet is
+// set. Warnings that apply to more than one result are printed once.
+func (g *GoTagger) printWarnings(results []gotagger.Result) {
+	if g.quiet {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, res := range results {
+		for _, warning := range res.Warnings {
+			if !seen[warning.Message] {
+				seen[warning.Message] = true
+				g.err.Println("warning:", warning)
+			}
+		}
+	}
+}
+
+// printSummary prints a table of what a -release run did to stderr, unless
+// -quiet is set: the latest and new version of each result, the tag that
+// was created for it, if any, and whether its tag was pushed.
+func (g *GoTagger) printSummary(results []gotagger.Result) {
+	if g.quiet {
+		return
+	}
+
+	yesNo := map[bool]string{true: "yes", false: "no"}
+
+	w := tabwriter.NewWriter(g.err.Writer(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tVERSION\tTAGGED\tPUSHED")
+	for _, res := range results {
+		latest := res.LatestTag
+		if latest == "" {
+			latest = "none"
+		}
+		// the created tag may not have all of the identifiers of the version
+		tagged := "no"
+		if res.Tagged {
+			tagged = res.Tag
+		}
+		fmt.Fprintf(w, "%s\t%s -> %s\t%s\t%s\n", resultName(res), latest, res.Version, tagged, yesNo[res.Pushed])
+	}
+	w.Flush()
+}
+
+// resultName returns the alias of the module of res, or its name, or the path
+// of res if it is not a module.
+func resultName(res gotagger.Result) string {
+	switch {
+	case res.Alias != "":
+		return res.Alias
+	case res.Module != "":
+		return res.Module
+	}
+
+	return res.Path
+}
+
+// printDryRun prints the tags that a release of HEAD would create, along with
+// their messages and changelog sections.
+func (g *GoTagger) printDryRun(r *gotagger.Gotagger) int {
+	planned, err := r.DryRunAt(g.target())
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	if len(planned) == 0 && !g.quiet {
+		g.err.Println(g.target(), "is not a release commit, so no tags would be created")
+	}
+
+	if g.format == "json" {
+		if planned == nil {
+			planned = []gotagger.PlannedTag{}
+		}
+		data, err := json.MarshalIndent(planned, "", "  ")
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		g.out.Println(string(data))
+
+		return successExitCode
+	}
+
+	changelog := r.Config.ChangelogFormat.FileName()
+	for i, tag := range planned {
+		if i > 0 {
+			g.out.Println()
+		}
+
+		g.out.Printf("tag %s\n\n%s\n", tag.Name, tag.Message)
+		for _, floating := range tag.Floating {
+			g.out.Printf("\nmove %s to %s\n", floating, tag.Name)
+		}
+		if len(tag.Owners) > 0 {
+			g.out.Printf("\nowners %s\n", strings.Join(tag.Owners, " "))
+		}
+		if len(tag.Changelog) > 0 {
+			g.out.Printf("\nchangelog %s\n\n%s", filepath.ToSlash(filepath.Join(tag.Path, changelog)), tag.Changelog)
+		}
+	}
+
+	return successExitCode
+}
+
+// writeChangelogs adds the changes since the latest version to changelogs,
+// or to their Unreleased sections with -unreleased, and prints the files it
+// wrote.
+func (g *GoTagger) writeChangelogs(r *gotagger.Gotagger) int {
+	if g.update != "" {
+		fn := g.update
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(g.WorkingDir, fn)
+		}
+
+		if err := r.UpdateChangelog(fn, g.unreleased); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		g.out.Println(g.update)
+
+		return successExitCode
+	}
+
+	var names []string
+	if len(g.moduleNames) > 0 {
+		var err error
+		if names, err = resolveModules(r, g.moduleNames); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+	}
+
+	write := r.WriteChangelogs
+	if g.unreleased {
+		write = r.WriteUnreleased
+	}
+
+	written, err := write(names...)
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	for _, fn := range written {
+		g.out.Println(fn)
+	}
+
+	return successExitCode
+}
+
+// preview prints the increment that merging HEAD into -base would make to
+// each module or path that it changes, along with its version before and
+// after the merge.
+func (g *GoTagger) preview(r *gotagger.Gotagger) int {
+	base := g.base
+	if base == "" {
+		// the default branch of the remote
+		branch, err := r.DefaultBranch()
+		if err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		if branch == "" {
+			g.err.Println("error: preview requires -base, the remote has no default branch")
+			return genericErrorExitCode
+		}
+		base = r.Config.RemoteName + "/" + branch
+	}
+
+	impacts, err := r.Preview(base)
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	if len(impacts) == 0 && !g.quiet {
+		g.err.Println("HEAD does not change any module since", base)
+	}
+
+	for _, impact := range impacts {
+		name := impact.Alias
+		if name == "" {
+			name = impact.Module
+		}
+		if name == "" {
+			name = impact.Path
+		}
+
+		base := impact.BaseVersion
+		if base == "" {
+			base = "none"
+		}
+		g.out.Println(name, impact.Increment, base, "->", impact.Version)
+	}
+
+	return successExitCode
+}
+
+// validateRelease checks the release commit given by -ref or -message-file,
+// and prints the tags it would create.
+func (g *GoTagger) validateRelease(r *gotagger.Gotagger) int {
+	if g.ref != "" && g.messageFile != "" {
+		g.err.Println("error: -ref and -message-file cannot be used together")
+		return genericErrorExitCode
+	}
+
+	var (
+		results []gotagger.Result
+		err     error
+	)
+	if g.messageFile != "" {
+		fn := g.messageFile
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(g.WorkingDir, fn)
+		}
+
+		var data []byte
+		if data, err = os.ReadFile(fn); err != nil {
+			g.err.Println("error:", err)
+			return genericErrorExitCode
+		}
+		results, err = r.ValidateReleaseMessage(string(data))
+	} else {
+		ref := g.ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+		results, err = r.ValidateRelease(ref)
+	}
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	for _, res := range results {
+		g.out.Println(res.Version)
+	}
+
+	return successExitCode
+}
+
+// checkTags prints the version tags at HEAD if they match the calculated
+// versions, or else the differences.
+func (g *GoTagger) checkTags(r *gotagger.Gotagger) int {
+	checks, err := r.CheckTags()
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	var tagged, calculated []string
+	for _, c := range checks {
+		if !c.Matches() {
+			tagged = append(tagged, c.Tag)
+			calculated = append(calculated, c.Version)
+		}
+	}
+
+	if len(tagged) > 0 {
+		g.err.Println("error: the version tags at HEAD do not match the calculated versions")
+		g.err.Println("--- tagged")
+		g.err.Println("+++ calculated")
+		for i := range tagged {
+			g.err.Println("-" + tagged[i])
+			g.err.Println("+" + calculated[i])
+		}
+		return checkFailedExitCode
+	}
+
+	for _, c := range checks {
+		g.out.Println(c.Tag)
+	}
+
+	return successExitCode
+}
+
+// resolveModules returns the module paths of the modules selected by values,
+// which are module paths, module aliases, or directories relative to the root
+// of the repository.
+func resolveModules(r *gotagger.Gotagger, values []string) ([]string, error) {
+	modules, err := r.Modules()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		found := false
+		for _, mod := range modules {
+			if value == mod.Name || value == mod.Alias || filepath.Clean(value) == mod.Path {
+				names = append(names, mod.Name)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown module %q", value)
+		}
+	}
+
+	return names, nil
+}
+
 func (g *GoTagger) boolEnv(env string, def bool) bool {
-	if val, ok := getEnv(env); ok {
+	if val, ok := g.lookupEnv(env); ok {
 		b, err := strconv.ParseBool(val)
 		if err != nil {
 			// We use fatal here since we cannot return an error.
@@ -267,21 +1129,89 @@ func (g *GoTagger) boolEnv(env string, def bool) bool {
 	return def
 }
 
+func (g *GoTagger) intEnv(env string, def int) int {
+	if val, ok := g.lookupEnv(env); ok {
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			// We use fatal here since we cannot return an error.
+			g.err.Fatalf("error: cannot parse GOTAGGER_%s as an integer value: %v\n", strings.ToUpper(env), err)
+		}
+		return i
+	}
+
+	return def
+}
+
 func (g *GoTagger) stringEnv(env, def string) string {
-	if val, ok := getEnv(env); ok {
+	if val, ok := g.lookupEnv(env); ok {
 		return val
 	}
 
 	return def
 }
 
-func getEnv(env string) (string, bool) {
-	env = "GOTAGGER_" + strings.ToUpper(env)
-	return os.LookupEnv(env)
+// listEnv returns the comma-separated values of env.
+func (g *GoTagger) listEnv(env string) (values []string) {
+	if val, ok := g.lookupEnv(env); ok {
+		for _, v := range strings.Split(val, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
+// lookupEnv returns the value of env, and records that it sets a flag.
+func (g *GoTagger) lookupEnv(env string) (string, bool) {
+	if g.envNames == nil {
+		g.envNames = make(map[string]bool)
+	}
+	g.envNames[env] = true
+
+	return g.getEnv(env)
+}
+
+// getEnv returns the value of the GOTAGGER_ environment variable env.
+func (g *GoTagger) getEnv(env string) (string, bool) {
+	env = "GOTAGGER_" + strings.ToUpper(env) + "="
+	for i := len(g.Env) - 1; i >= 0; i-- {
+		if val, ok := strings.CutPrefix(g.Env[i], env); ok {
+			return val, true
+		}
+	}
+
+	return "", false
+}
+
+// printEnv prints the environment variables that set the flags in fs.
+func (g *GoTagger) printEnv(fs *flag.FlagSet) {
+	g.out.Print("Environment variables:\n")
+	fs.VisitAll(func(f *flag.Flag) {
+		env := strings.ReplaceAll(f.Name, "-", "_")
+		if !g.envNames[env] {
+			return
+		}
+
+		g.out.Printf("  GOTAGGER_%s\n        %s (-%s)\n", strings.ToUpper(env), f.Usage, f.Name)
+		if strings.HasSuffix(f.Usage, "may be repeated") {
+			g.out.Print("        separate multiple values with commas\n")
+		}
+	})
+	g.out.Print("  GOTAGGER_PUSH_TOKEN\n        token for authenticating HTTPS pushes of tags\n")
+	g.out.Print("  GOTAGGER_SERVE_TOKEN\n        bearer token that requests to gotagger serve must send\n")
 }
 
 const (
-	usagePrefix = `Usage: %s [OPTION]... [PATH]
+	usagePrefix = `Usage: %[1]s [OPTION]... [PATH]
+  or:  %[1]s resume [OPTION]... [PATH]
+  or:  %[1]s backfill [OPTION]... [PATH]
+  or:  %[1]s validate-release [OPTION]... [PATH]
+  or:  %[1]s changelog [OPTION]... [PATH]
+  or:  %[1]s preview [-base BRANCH] [OPTION]... [PATH]
+  or:  %[1]s migrate TOOL [PATH]
+  or:  %[1]s serve [OPTION]... [ROOT]...
 Print the current version of the project to standard output.
 
 With no PATH the current directory is used.
@@ -309,6 +1239,32 @@ The -path flag causes gotagger to filter commit history by paths. This is useful
 for using gotagger with git repositories that contain multiple pieces that
 should be versioned separately. A path filter must exist and must be a
 directory.
+
+If gotagger is interrupted while creating or pushing tags, then later releases
+are refused until 'gotagger resume' is run. This creates any tags that are
+missing and pushes them if the interrupted release was pushing tags.
+
+'gotagger backfill' prints the version tags missing from past release commits,
+such as releases made before gotagger was adopted, and the commit each tag is
+for. With -release the missing tags are created, and with -push they are also
+pushed.
+
+'gotagger validate-release' checks a release commit before it is merged, such
+as the head of a pull request branch, and prints the tags it would create.
+Errors that would stop gotagger from tagging the release after it is merged
+are reported instead. Use -ref to check another commit than HEAD, or
+-message-file to check a release commit message that is not committed yet.
+
+'gotagger changelog' adds the changes since the latest version to the
+changelog of each module, and prints the files it wrote. With -unreleased, it
+rewrites only the Unreleased section of each keep-a-changelog file instead,
+and a later 'gotagger changelog' moves that section under the heading of the
+new version. Use -update to write only one changelog file.
+
+'gotagger preview' prints the increment that merging HEAD into the branch
+given by -base would make to each module that HEAD changes, such as major,
+along with its version before and after the merge. The default base is the
+default branch of the remote, such as origin/main.
 `
 )
 
@@ -5,14 +5,18 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/sassoftware/gotagger/internal/testutils"
 	"github.com/stretchr/testify/assert"
@@ -43,7 +47,9 @@ func TestGoTagger(t *testing.T) {
 	tests := []struct {
 		title            string
 		args             []string
+		env              []string
 		wantOut, wantErr string
+		wantOutContains  []string
 		wantRc           int
 		extraSetup       setupFunc
 		extraTest        testFunc
@@ -72,6 +78,34 @@ func TestGoTagger(t *testing.T) {
 			args:    []string{"-prefix", "prefix-"},
 			wantOut: "prefix-0.1.0\n",
 		},
+		{
+			title:   "path-style prefix",
+			args:    []string{"-prefix", "/releases/"},
+			wantOut: "releases/0.1.0\n",
+		},
+		{
+			title:   "tag namespace",
+			env:     []string{"GOTAGGER_TAG_NAMESPACE=refs/releases"},
+			wantOut: "v0.1.0\n",
+		},
+		{
+			title:   "invalid tag namespace",
+			args:    []string{"-tag-namespace", "releases"},
+			wantErr: "error: invalid tag namespace \"releases\": must be beneath refs/\n",
+			wantRc:  1,
+		},
+		{
+			title:   "invalid version file",
+			args:    []string{"-version-file", "../VERSION"},
+			wantErr: "error: invalid version file \"../VERSION\": must be a relative path inside the module\n",
+			wantRc:  1,
+		},
+		{
+			title:   "invalid prefix",
+			args:    []string{"-prefix", "refs/tags/v"},
+			wantErr: "error: invalid version prefix \"refs/tags/v\": must not start with refs/\n",
+			wantRc:  1,
+		},
 		{
 			title:   "no options",
 			args:    []string{},
@@ -86,19 +120,208 @@ func TestGoTagger(t *testing.T) {
 			title:     "no release commit",
 			args:      []string{"-release"},
 			wantOut:   "v1.1.0\n",
+			wantErr:   "v1.0.0 -> v1.1.0  no      no",
 			extraTest: assertNoTag("v1.1.0"),
 		},
 		{
 			title:      "release commit",
 			args:       []string{"-release"},
 			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  v1.1.0  no",
 			extraSetup: createReleaseCommit,
 			extraTest:  assertTag("v1.1.0"),
 		},
+		{
+			title:      "release commits since",
+			args:       []string{"-release", "-commits-since"},
+			wantOut:    "v1.1.0-r2\n",
+			wantErr:    "v1.0.0 -> v1.1.0-r2  v1.1.0  no",
+			extraSetup: createReleaseCommit,
+			extraTest:  assertTag("v1.1.0"),
+		},
+		{
+			title:   "release earlier commit",
+			args:    []string{"-release", "-commit", "HEAD~1"},
+			wantOut: "v1.1.0\n",
+			wantErr: "v1.0.0 -> v1.1.0  v1.1.0  no",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				createReleaseCommit(t, repo, path)
+				testutils.CommitFile(t, repo, path, "bar", "feat: add bar", []byte("bar"))
+			},
+			extraTest: assertTag("v1.1.0"),
+		},
+		{
+			title:      "release with tag date",
+			args:       []string{"-release", "-tag-date", "2020-01-02T03:04:05Z"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  v1.1.0  no",
+			extraSetup: createReleaseCommit,
+			extraTest:  assertTagDate("v1.1.0", time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)),
+		},
+		{
+			title:   "invalid tag date",
+			env:     []string{"GOTAGGER_TAG_DATE=yesterday"},
+			wantErr: "error: invalid tag date \"yesterday\": must be \"author\", \"committer\", or an RFC 3339 date\n",
+			wantRc:  1,
+		},
+		{
+			title:      "verbose release commit",
+			args:       []string{"-release", "-v"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "created tag",
+			extraSetup: createReleaseCommit,
+			extraTest:  assertTag("v1.1.0"),
+		},
+		{
+			title:   "quiet warnings",
+			args:    []string{"-q"},
+			wantOut: "v1.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				// a nested module whose path does not match its directory
+				require.NoError(t, os.Mkdir(filepath.Join(path, "example"), 0o700))
+				writeFiles(map[string]string{"go.mod": "module foo\n", "example/go.mod": "module example\n"})(t, repo, path)
+			},
+		},
+		{
+			title:   "invalid version tag",
+			wantOut: "v1.1.0\n",
+			wantErr: "warning: tag v1.0.0.1 is not a valid semantic version and is ignored\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				testutils.CreateTag(t, repo, "v1.0.0.1")
+			},
+		},
+		{
+			title:   "quiet invalid version tag",
+			args:    []string{"-q"},
+			wantOut: "v1.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				testutils.CreateTag(t, repo, "v1.0.0.1")
+			},
+		},
+		{
+			title:   "skipped module",
+			wantOut: "v1.1.0\n",
+			wantErr: "warning: example/go.mod does not declare a module path and is skipped\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				require.NoError(t, os.Mkdir(filepath.Join(path, "example"), 0o700))
+				writeFiles(map[string]string{"go.mod": "module foo\n", "example/go.mod": "go 1.22\n"})(t, repo, path)
+			},
+		},
+		{
+			title:   "quiet and verbose",
+			args:    []string{"-quiet", "-vv"},
+			wantErr: "error: -quiet cannot be used with -v, -vv, -verbosity, or -debug\n",
+			wantRc:  1,
+		},
+		{
+			title:   "verbosity from environment",
+			args:    []string{"-q"},
+			env:     []string{"GOTAGGER_VERBOSITY=1"},
+			wantErr: "error: -quiet cannot be used with -v, -vv, -verbosity, or -debug\n",
+			wantRc:  1,
+		},
+		{
+			title:           "dry run release commit",
+			args:            []string{"-dry-run", "-release"},
+			wantOutContains: []string{"tag v1.1.0\n\nRelease v1.1.0\n\nchangelog CHANGELOG.md\n\n## [1.1.0] - ", "### Added\n\n- bar ("},
+			extraSetup:      createReleaseCommit,
+			extraTest:       assertNoTag("v1.1.0"),
+		},
+		{
+			title:           "dry run floating tags",
+			args:            []string{"-dry-run", "-release", "-floating-tags=minor"},
+			wantOutContains: []string{"tag v1.1.0\n\nRelease v1.1.0\n\nmove v1 to v1.1.0\n\nmove v1.1 to v1.1.0\n"},
+			extraSetup:      createReleaseCommit,
+			extraTest:       assertNoTag("v1"),
+		},
+		{
+			title:           "ldflags format",
+			args:            []string{"-format", "ldflags"},
+			wantOutContains: []string{`-ldflags "-X main.AppVersion=v1.1.0 -X main.Commit=`, " -X main.BuildDate=" + time.Now().UTC().Format(time.DateOnly) + "\"\n"},
+		},
+		{
+			title:           "reproducible ldflags format",
+			args:            []string{"-format", "ldflags", "-reproducible"},
+			wantOutContains: []string{" -X main.BuildDate=2020-01-02\"\n"},
+			extraSetup:      commitAt("2020-01-02T03:04:05Z"),
+		},
+		{
+			title:           "reproducible provenance format",
+			env:             []string{"GOTAGGER_REPRODUCIBLE=true"},
+			args:            []string{"-format", "provenance"},
+			wantOutContains: []string{"\"dirty\": false,\n    \"timestamp\": \"2020-01-02T03:04:05Z\""},
+			extraSetup:      commitAt("2020-01-02T03:04:05Z"),
+		},
+		{
+			title:           "ldflags format for modules",
+			args:            []string{"-all"},
+			env:             []string{"GOTAGGER_FORMAT=ldflags"},
+			wantOutContains: []string{`-ldflags "-X main.AppVersion=v1.1.0 -X main.Commit=`, `-ldflags "-X main.AppVersion=sub/v0.1.0 -X main.Commit=`},
+			extraSetup:      createModules,
+		},
+		{
+			title:           "provenance format",
+			args:            []string{"-format", "provenance"},
+			wantOutContains: []string{"[\n  {\n    \"name\": \".\",\n    \"version\": \"v1.1.0\",\n    \"commit\": \"", "\"dirty\": false,\n    \"timestamp\": \""},
+		},
+		{
+			title:           "json format",
+			args:            []string{"-format", "json"},
+			wantOutContains: []string{"[\n  {\n    \"path\": \".\",\n    \"prefix\": \"v\",\n    \"version\": \"v1.1.0\",\n", "\"latestTag\": \"v1.0.0\",", "\"tagged\": false,\n    \"pushed\": false\n  }\n]\n"},
+		},
+		{
+			title:           "dry run json format",
+			args:            []string{"-dry-run", "-release", "-format", "json"},
+			wantOutContains: []string{"[\n  {\n    \"name\": \"v1.1.0\",\n    \"message\": \"Release v1.1.0\",\n    \"path\": \".\",\n    \"changelog\": \"## [1.1.0] - "},
+			extraSetup:      createReleaseCommit,
+			extraTest:       assertNoTag("v1.1.0"),
+		},
+		{
+			title:   "badge format",
+			args:    []string{"-format", "badge"},
+			wantOut: `{"schemaVersion":1,"label":"version","message":"v1.1.0","color":"blue"}` + "\n",
+		},
+		{
+			title:     "badge dir",
+			args:      []string{"-badge-dir", "badges"},
+			wantOut:   "v1.1.0\n",
+			extraTest: assertFileExists(filepath.Join("badges", "badge.json")),
+		},
+		{
+			title:   "invalid format",
+			args:    []string{"-format", "yaml"},
+			wantErr: "error: invalid format 'yaml'\n",
+			wantRc:  1,
+		},
+		{
+			title:   "invalid floating tags",
+			args:    []string{"-floating-tags=patch"},
+			wantErr: "error: invalid floating tag policy 'patch'\n",
+			wantRc:  1,
+		},
+		{
+			title:   "invalid outside changes",
+			args:    []string{"-outside-changes=error"},
+			wantErr: "error: invalid outside change policy 'error'\n",
+			wantRc:  1,
+		},
+		{
+			title:   "dry run no release commit",
+			args:    []string{"-dry-run", "-release"},
+			wantErr: "HEAD is not a release commit, so no tags would be created\n",
+		},
+		{
+			title:      "dry run interrupted release",
+			args:       []string{"-dry-run"},
+			wantErr:    "error: a previous release was interrupted, run 'gotagger resume' to finish it\n",
+			wantRc:     1,
+			extraSetup: interruptRelease("v1.1.0"),
+		},
 		{
 			title:     "push no release commit",
 			args:      []string{"-push"},
 			wantOut:   "v1.1.0\n",
+			wantErr:   "v1.0.0 -> v1.1.0  no      no",
 			extraTest: assertNoTag("v1.1.0"),
 		},
 		{
@@ -117,6 +340,238 @@ func TestGoTagger(t *testing.T) {
 			extraSetup: createReleaseCommit,
 			extraTest:  assertNoTag("v1.1.0"),
 		},
+		{
+			title:      "push follow tags",
+			args:       []string{"-push", "-follow-tags"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  v1.1.0  yes",
+			extraSetup: pushTags(setupRemote(createReleaseCommit)),
+			extraTest:  assertRemoteTag("v1.1.0"),
+		},
+		{
+			title:      "push follow tags unrelated",
+			args:       []string{"-push", "-follow-tags"},
+			wantErr:    "error: refusing to push with --follow-tags, which would also push refs/tags/v1.0.0\n",
+			wantRc:     1,
+			extraSetup: setupRemote(createReleaseCommit),
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:      "push options",
+			args:       []string{"-push", "-push-option", "ci.skip"},
+			wantErr:    "the receiving end does not support push options",
+			wantRc:     1,
+			extraSetup: setupRemote(createReleaseCommit),
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:      "push offline",
+			args:       []string{"-push", "-offline"},
+			wantErr:    "error: offline mode forbids network access: cannot push tags\n",
+			wantRc:     1,
+			extraSetup: setupRemote(createReleaseCommit),
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:      "release offline",
+			env:        []string{"GOTAGGER_OFFLINE=true"},
+			args:       []string{"-release"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  v1.1.0  no",
+			extraSetup: setupRemote(createReleaseCommit),
+		},
+		{
+			title:      "ci release commit",
+			env:        []string{"GITHUB_OUTPUT=github-output"},
+			args:       []string{"-ci"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  v1.1.0  yes",
+			extraSetup: setupRemote(createReleaseCommit),
+			extraTest: func(t *testing.T, repo *git.Repository, path string, stdout, stderr *bytes.Buffer) {
+				assertRemoteTag("v1.1.0")(t, repo, path, stdout, stderr)
+				assertFileExists("RELEASE_NOTES.md")(t, repo, path, stdout, stderr)
+
+				data, err := os.ReadFile(filepath.Join(path, "github-output"))
+				require.NoError(t, err)
+				assert.Equal(t, "version=v1.1.0\nversions=v1.1.0\nreleased=true\ntags=v1.1.0\nrelease-notes=RELEASE_NOTES.md\n", string(data))
+			},
+		},
+		{
+			title:      "ci no release commit",
+			env:        []string{"GITHUB_OUTPUT=github-output"},
+			args:       []string{"-ci"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  no      no",
+			extraSetup: setupRemote(func(*testing.T, *git.Repository, string) {}),
+			extraTest: func(t *testing.T, repo *git.Repository, path string, stdout, stderr *bytes.Buffer) {
+				assertNoTag("v1.1.0")(t, repo, path, stdout, stderr)
+				assert.NoFileExists(t, filepath.Join(path, "RELEASE_NOTES.md"))
+
+				data, err := os.ReadFile(filepath.Join(path, "github-output"))
+				require.NoError(t, err)
+				assert.Equal(t, "version=v1.1.0\nversions=v1.1.0\nreleased=false\ntags=\nrelease-notes=\n", string(data))
+			},
+		},
+		{
+			title:      "ci without push",
+			env:        []string{"GOTAGGER_PUSH=false"},
+			args:       []string{"-ci", "-fetch-tags=false", "-release-notes", "notes.md"},
+			wantOut:    "v1.1.0\n",
+			wantErr:    "v1.0.0 -> v1.1.0  v1.1.0  no",
+			extraSetup: createReleaseCommit,
+			extraTest: func(t *testing.T, repo *git.Repository, path string, stdout, stderr *bytes.Buffer) {
+				assertTag("v1.1.0")(t, repo, path, stdout, stderr)
+				assertFileExists("notes.md")(t, repo, path, stdout, stderr)
+			},
+		},
+		{
+			title:      "ci offline",
+			args:       []string{"-ci", "-offline", "-release=false"},
+			wantOut:    "v1.1.0\n",
+			extraSetup: createReleaseCommit,
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:     "changelog",
+			args:      []string{"-changelog"},
+			wantOut:   "v1.1.0\n",
+			extraTest: assertFileExists("CHANGELOG.md"),
+		},
+		{
+			title:     "changelog command",
+			args:      []string{"changelog"},
+			wantOut:   "CHANGELOG.md\n",
+			extraTest: assertFileExists("CHANGELOG.md"),
+		},
+		{
+			title:   "changelog unreleased",
+			args:    []string{"changelog", "-unreleased", "-update", "HISTORY.md"},
+			wantOut: "HISTORY.md\n",
+			extraTest: func(t *testing.T, repo *git.Repository, path string, stdout, stderr *bytes.Buffer) {
+				data, err := os.ReadFile(filepath.Join(path, "HISTORY.md"))
+				require.NoError(t, err)
+				assert.Contains(t, string(data), "## [Unreleased]\n\n### Added\n\n")
+			},
+		},
+		{
+			title:   "preview",
+			args:    []string{"preview", "-base", "HEAD~1"},
+			wantOut: ". minor v1.0.0 -> v1.1.0\n",
+		},
+		{
+			title:   "preview without base",
+			args:    []string{"preview"},
+			wantErr: "error: preview requires -base, the remote has no default branch\n",
+			wantRc:  1,
+		},
+		{
+			title:      "preview default branch",
+			args:       []string{"preview"},
+			wantOut:    ". minor v1.0.0 -> v1.1.0\n",
+			extraSetup: setDefaultBranch("trunk", "HEAD~1"),
+		},
+		{
+			title:      "migrate semantic-release",
+			args:       []string{"migrate", "semantic-release"},
+			wantOut:    "{\n  \"changelogPreset\": \"angular\",\n  \"defaultIncrement\": \"none\",\n  \"incrementMappings\": {\n    \"feat\": \"minor\",\n    \"fix\": \"patch\",\n    \"perf\": \"patch\"\n  },\n  \"releaseBranches\": [\n    \"main\"\n  ]\n}\n",
+			wantErr:    "warning: pre-release branch \"beta\" is not supported\n",
+			extraSetup: writeFile(".releaserc", `{"branches": ["main", {"name": "beta", "prerelease": true}], "plugins": ["@semantic-release/commit-analyzer"]}`),
+		},
+		{
+			title:      "migrate commitlint",
+			args:       []string{"migrate", "commitlint"},
+			wantOut:    "{\n  \"incrementMappings\": {\n    \"feat\": \"minor\",\n    \"fix\": \"patch\"\n  }\n}\n",
+			extraSetup: writeFile("commitlint.config.js", `module.exports = { rules: { 'type-enum': [2, 'always', ['feat', 'fix', 'release']] } }`),
+		},
+		{
+			title:      "migrate check commitlint",
+			args:       []string{"migrate", "-check", "commitlint"},
+			wantErr:    "error: type \"fix\" is allowed by commitlint but not mapped, so it is a none increment instead of patch\n",
+			wantRc:     1,
+			extraSetup: writeFiles(map[string]string{".commitlintrc.json": `{"rules": {"type-enum": [2, "always", ["feat", "fix"]]}}`, "gotagger.json": `{"defaultIncrement": "none", "incrementMappings": {"feat": "minor"}}`}),
+		},
+		{
+			title:      "migrate check commitlint default increment",
+			args:       []string{"migrate", "-check", "commitlint"},
+			extraSetup: writeFiles(map[string]string{".commitlintrc.json": `{"rules": {"type-enum": [2, "always", ["feat", "fix", "docs"]]}}`, "gotagger.json": `{"incrementMappings": {"feat": "minor"}}`}),
+		},
+		{
+			title:   "migrate no config",
+			args:    []string{"migrate", "semantic-release"},
+			wantErr: "error: no semantic-release configuration found in %s\n",
+			wantRc:  1,
+		},
+		{
+			title:   "migrate unknown tool",
+			args:    []string{"migrate", "goreleaser"},
+			wantErr: "error: unknown tool \"goreleaser\"\n",
+			wantRc:  1,
+		},
+		{
+			title: "resume nothing to do",
+			args:  []string{"resume"},
+		},
+		{
+			title: "backfill nothing to do",
+			args:  []string{"backfill"},
+		},
+		{
+			title:           "backfill",
+			args:            []string{"backfill"},
+			wantOutContains: []string{"v1.1.0 "},
+			extraSetup:      createReleaseCommit,
+			extraTest:       assertNoTag("v1.1.0"),
+		},
+		{
+			title:           "backfill release",
+			args:            []string{"backfill", "-release"},
+			wantOutContains: []string{"v1.1.0 "},
+			extraSetup:      createReleaseCommit,
+			extraTest:       assertTag("v1.1.0"),
+		},
+		{
+			title:      "validate release",
+			args:       []string{"validate-release"},
+			wantOut:    "v1.1.0\n",
+			extraSetup: createReleaseCommit,
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:      "validate release ref",
+			args:       []string{"validate-release", "-ref", "HEAD~1"},
+			wantErr:    "error: not a release commit: \"feat: bar\"\n",
+			wantRc:     1,
+			extraSetup: createReleaseCommit,
+		},
+		{
+			title:   "validate release message file",
+			args:    []string{"validate-release", "-message-file", "message.txt"},
+			wantOut: "v1.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				require.NoError(t, os.WriteFile(filepath.Join(path, "message.txt"), []byte("release: cut the v1.1.0 release\n"), 0o600))
+			},
+		},
+		{
+			title:   "validate release ref and message file",
+			args:    []string{"validate-release", "-ref", "HEAD", "-message-file", "message.txt"},
+			wantErr: "error: -ref and -message-file cannot be used together\n",
+			wantRc:  1,
+		},
+		{
+			title:      "resume interrupted release",
+			args:       []string{"resume"},
+			wantOut:    "v1.1.0\n",
+			extraSetup: interruptRelease("v1.1.0"),
+			extraTest:  assertTag("v1.1.0"),
+		},
+		{
+			title:      "release after interrupted release",
+			args:       []string{"-release"},
+			wantErr:    "error: a previous release was interrupted, run 'gotagger resume' to finish it\n",
+			wantRc:     1,
+			extraSetup: interruptRelease("v1.1.0"),
+			extraTest:  assertNoTag("v1.1.0"),
+		},
 		{
 			title:   "invalid flag",
 			args:    []string{"-foo"},
@@ -171,16 +626,133 @@ func TestGoTagger(t *testing.T) {
 				require.NoError(t, os.WriteFile(filepath.Join(path, "foo"), []byte("foo\n"), 0600))
 			},
 		},
+		{
+			title:   "commits since",
+			args:    []string{"-commits-since"},
+			wantOut: "v1.1.0-r1\n",
+		},
+		{
+			title:   "current version without tags",
+			args:    []string{"-prefix", "release-", "-current-version", "2.5.0"},
+			wantOut: "release-2.5.0\n",
+		},
+		{
+			title:   "current version with tags",
+			env:     []string{"GOTAGGER_CURRENT_VERSION=2.5.0"},
+			wantOut: "v1.1.0\n",
+		},
+		{
+			title:   "nightly",
+			args:    []string{"-nightly"},
+			wantOut: "v1.1.0-nightly." + time.Now().UTC().Format("20060102") + "\n",
+		},
+		{
+			title:      "nightly tag",
+			args:       []string{"-release", "-nightly-tag", "nightly"},
+			env:        []string{"GOTAGGER_NIGHTLY=true"},
+			wantOut:    "v1.1.0-nightly." + time.Now().UTC().Format("20060102") + "\n",
+			extraSetup: createReleaseCommit,
+			extraTest: func(t *testing.T, repo *git.Repository, path string, stdout *bytes.Buffer, stderr *bytes.Buffer) {
+				assertTag("nightly")(t, repo, path, stdout, stderr)
+				assertNoTag("v1.1.0")(t, repo, path, stdout, stderr)
+			},
+		},
+		{
+			title:   "dirty suffix",
+			args:    []string{"-dirty-suffix=-dirty"},
+			wantOut: "v1.1.0-dirty\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				require.NoError(t, os.WriteFile(filepath.Join(path, "foo"), []byte("foo\n"), 0600))
+			},
+		},
+		{
+			title:   "dirty suffix and increment",
+			args:    []string{"-dirty=patch", "-dirty-suffix=+dirty"},
+			wantOut: "v1.3.1+dirty\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				testutils.CreateTag(t, repo, "v1.3.0")
+				require.NoError(t, os.WriteFile(filepath.Join(path, "foo"), []byte("foo\n"), 0600))
+			},
+		},
+		{
+			title:   "next tag",
+			args:    []string{"-next-tag", "-commits-since", "-dirty-suffix=-dirty"},
+			wantOut: "v1.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				require.NoError(t, os.WriteFile(filepath.Join(path, "foo"), []byte("foo\n"), 0600))
+			},
+		},
+		{
+			title:      "next tag release commit",
+			args:       []string{"-next-tag", "-release"},
+			wantOut:    "v1.1.0\n",
+			extraSetup: createReleaseCommit,
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:   "dirty suffix clean",
+			args:    []string{"-dirty-suffix=-dirty"},
+			wantOut: "v1.1.0\n",
+		},
+		{
+			title:   "invalid dirty suffix",
+			args:    []string{"-dirty-suffix=dirty"},
+			wantErr: "error: invalid dirty worktree suffix \"dirty\": must start with '-' or '+'",
+			wantRc:  1,
+		},
 		{
 			title:   "dirty major",
 			args:    []string{"-dirty=major"},
 			wantErr: "error: -dirty value must be minor, patch, or none",
 			wantRc:  1,
 		},
+		{
+			title:   "promote flag",
+			args:    []string{"-promote"},
+			wantOut: "v1.0.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				// v0.1.0 is tagged on the "other" branch
+				w, err := repo.Worktree()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := w.Checkout(&git.CheckoutOptions{
+					Branch: plumbing.NewBranchReferenceName("other"),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			title:   "promote stable version",
+			args:    []string{"-promote"},
+			wantOut: "v1.1.0\n",
+			wantErr: "warning: cannot promote path \".\": version v1.0.0 is already stable\n",
+		},
+		{
+			title:   "verify tags skip",
+			args:    []string{"-verify-tags=skip"},
+			wantOut: "v0.1.0\n",
+			wantErr: "warning: tag v1.0.0 failed signature verification and is skipped\n",
+		},
+		{
+			title:   "verify tags fail",
+			args:    []string{"-verify-tags=fail"},
+			wantErr: "error: tag v1.0.0 failed signature verification",
+			wantRc:  1,
+		},
+		{
+			title:   "invalid verify tags",
+			args:    []string{"-verify-tags=maybe"},
+			wantErr: "error: invalid tag verification policy 'maybe'",
+			wantRc:  1,
+		},
 		{
 			title:     "force flag",
 			args:      []string{"-force"},
 			wantOut:   "v1.1.0\n",
+			wantErr:   "v1.0.0 -> v1.1.0  v1.1.0  no",
 			extraTest: assertTag("v1.1.0"),
 		},
 		{
@@ -201,6 +773,163 @@ func TestGoTagger(t *testing.T) {
 				}
 			},
 		},
+		{
+			title:   "filter from environment",
+			env:     []string{"GOTAGGER_PATH=baz"},
+			wantOut: "v0.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				// need to be on the "other" branch
+				w, err := repo.Worktree()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := w.Checkout(&git.CheckoutOptions{
+					Branch: plumbing.NewBranchReferenceName("other"),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			title:   "flag overrides environment",
+			args:    []string{"-prefix", "v"},
+			env:     []string{"GOTAGGER_PREFIX=prefix-", "GOTAGGER_COMMITS_SINCE=true"},
+			wantOut: "v1.1.0-r1\n",
+		},
+		{
+			title:      "invalid push option from environment",
+			env:        []string{"GOTAGGER_PUSH=true", "GOTAGGER_PUSH_OPTION=ci.skip, merge_request.create"},
+			wantErr:    "the receiving end does not support push options",
+			wantRc:     1,
+			extraSetup: setupRemote(createReleaseCommit),
+			extraTest:  assertNoTag("v1.1.0"),
+		},
+		{
+			title:      "module by name",
+			args:       []string{"-module", "foo/sub"},
+			wantOut:    "sub/v0.1.0\n",
+			extraSetup: createModules,
+		},
+		{
+			title:      "module by directory",
+			args:       []string{"-module", "./sub", "-module", "."},
+			wantOut:    "v1.1.0\nsub/v0.1.0\n",
+			extraSetup: createModules,
+		},
+		{
+			title:      "module from environment",
+			env:        []string{"GOTAGGER_MODULE=foo"},
+			wantOut:    "v1.1.0\n",
+			extraSetup: createModules,
+		},
+		{
+			title: "check untagged",
+			args:  []string{"-check"},
+		},
+		{
+			title:   "check matching tag",
+			args:    []string{"-check"},
+			wantOut: "v1.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				testutils.CreateTag(t, repo, "v1.1.0")
+			},
+		},
+		{
+			title:   "check different tag",
+			args:    []string{"-check"},
+			wantErr: "error: the version tags at HEAD do not match the calculated versions\n--- tagged\n+++ calculated\n-v2.0.0\n+v1.1.0\n",
+			wantRc:  2,
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				testutils.CreateTag(t, repo, "v2.0.0")
+			},
+		},
+		{
+			title:   "check release",
+			args:    []string{"-check", "-release"},
+			wantErr: "error: -check cannot be used to create tags\n",
+			wantRc:  1,
+		},
+		{
+			title:      "all modules",
+			args:       []string{"-all"},
+			wantOut:    "foo v1.1.0\nfoo/sub sub/v0.1.0\n",
+			extraSetup: createModules,
+		},
+		{
+			title:   "all modules by name",
+			args:    []string{"-all", "-module-order", "name"},
+			wantOut: "foo v1.1.0\nfoo/sub sub/v0.1.0\nfoo/sub-x sub-x/v0.1.0\nfoo/sub/v2 sub/v2.1.0\n",
+			extraSetup: func(t *testing.T, repo *git.Repository, path string) {
+				createModules(t, repo, path)
+				testutils.CommitFile(t, repo, path, filepath.Join("sub", "v2", "go.mod"), "feat: add sub/v2/go.mod", []byte("module foo/sub/v2\n"))
+				testutils.CommitFile(t, repo, path, filepath.Join("sub-x", "go.mod"), "feat: add sub-x/go.mod", []byte("module foo/sub-x\n"))
+			},
+		},
+		{
+			title:   "invalid module order",
+			args:    []string{"-module-order", "alphabetical"},
+			wantErr: "error: invalid module order 'alphabetical'\n",
+			wantRc:  1,
+		},
+		{
+			title:      "all selected modules",
+			args:       []string{"-all", "-module", "sub"},
+			wantOut:    "foo/sub sub/v0.1.0\n",
+			extraSetup: createModules,
+		},
+		{
+			title:      "all without modules",
+			args:       []string{"-all", "-modules=false"},
+			wantOut:    ". v1.1.0\n",
+			extraSetup: createModules,
+		},
+		{
+			title:      "trace file",
+			args:       []string{"-all", "-trace-file", "plan.json"},
+			wantOut:    "foo v1.1.0\nfoo/sub sub/v0.1.0\n",
+			extraSetup: createModules,
+			extraTest: func(t *testing.T, repo *git.Repository, path string, stdout, stderr *bytes.Buffer) {
+				data, err := os.ReadFile(filepath.Join(path, "plan.json"))
+				require.NoError(t, err)
+
+				var trace struct {
+					Modules []struct {
+						Module, Version, LatestTag string
+						Commits                    []struct{ Hash, Increment string }
+					}
+				}
+				require.NoError(t, json.Unmarshal(data, &trace))
+				if assert.Len(t, trace.Modules, 2) {
+					assert.Equal(t, "foo", trace.Modules[0].Module)
+					assert.Equal(t, "v1.1.0", trace.Modules[0].Version)
+					assert.Equal(t, "v1.0.0", trace.Modules[0].LatestTag)
+					assert.NotEmpty(t, trace.Modules[0].Commits)
+				}
+				assert.Contains(t, string(data), `"default": "patch"`)
+			},
+		},
+		{
+			title:      "all release",
+			args:       []string{"-all", "-release"},
+			wantErr:    "error: -all and -module cannot be used to create tags\n",
+			wantRc:     1,
+			extraSetup: createModules,
+		},
+		{
+			title:      "unknown module",
+			args:       []string{"-module", "foo/missing"},
+			wantErr:    "error: unknown module \"foo/missing\"\n",
+			wantRc:     1,
+			extraSetup: createModules,
+		},
+		{
+			title:      "module release",
+			args:       []string{"-module", "foo", "-release"},
+			wantErr:    "error: -all and -module cannot be used to create tags\n",
+			wantRc:     1,
+			extraSetup: createModules,
+		},
 		{
 			title:   "path filter does not exist",
 			args:    []string{"-path", "missing"},
@@ -259,13 +988,20 @@ func TestGoTagger(t *testing.T) {
 			}
 
 			g, stdout, stderr := newGotagger(path, tt.args)
+			g.Env = tt.env
 			assert.Equal(t, tt.wantRc, g.Run())
 			if wantErr != "" {
 				assert.Contains(t, stderr.String(), wantErr)
 			} else {
 				assert.Empty(t, stderr.String())
 			}
-			assert.Equal(t, tt.wantOut, stdout.String())
+			if len(tt.wantOutContains) > 0 {
+				for _, want := range tt.wantOutContains {
+					assert.Contains(t, stdout.String(), want)
+				}
+			} else {
+				assert.Equal(t, tt.wantOut, stdout.String())
+			}
 			if tt.extraTest != nil {
 				tt.extraTest(t, repo, path, stdout, stderr)
 			}
@@ -273,6 +1009,21 @@ func TestGoTagger(t *testing.T) {
 	}
 }
 
+func TestGoTagger_helpEnv(t *testing.T) {
+	g, stdout, stderr := newGotagger(t.TempDir(), []string{"-help-env"})
+	assert.Equal(t, 0, g.Run())
+	assert.Empty(t, stderr.String())
+
+	out := stdout.String()
+	assert.True(t, strings.HasPrefix(out, "Environment variables:\n  GOTAGGER_ALL\n"), out)
+	for _, env := range []string{"GOTAGGER_DEBUG", "GOTAGGER_PATH", "GOTAGGER_PUSH_OPTION", "GOTAGGER_PUSH_TOKEN"} {
+		assert.Contains(t, out, "  "+env+"\n")
+	}
+	for _, env := range []string{"GOTAGGER_HELP_ENV", "GOTAGGER_VERSION"} {
+		assert.NotContains(t, out, "  "+env+"\n")
+	}
+}
+
 func newGotagger(dir string, args []string) (*GoTagger, *bytes.Buffer, *bytes.Buffer) {
 	out := &bytes.Buffer{}
 	err := &bytes.Buffer{}
@@ -303,6 +1054,20 @@ func assertNoTag(tag string) testFunc {
 	}
 }
 
+func assertTagDate(tag string, want time.Time) testFunc {
+	return func(t *testing.T, repo *git.Repository, path string, stdout *bytes.Buffer, stderr *bytes.Buffer) {
+		t.Helper()
+
+		ref, err := repo.Tag(tag)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if obj, err := repo.TagObject(ref.Hash()); assert.NoError(t, err) {
+			assert.True(t, want.Equal(obj.Tagger.When), obj.Tagger.When)
+		}
+	}
+}
+
 func assertTag(tag string) testFunc {
 	return func(t *testing.T, repo *git.Repository, path string, stdout *bytes.Buffer, stderr *bytes.Buffer) {
 		t.Helper()
@@ -312,6 +1077,136 @@ func assertTag(tag string) testFunc {
 	}
 }
 
+func assertRemoteTag(tag string) testFunc {
+	return func(t *testing.T, repo *git.Repository, path string, stdout *bytes.Buffer, stderr *bytes.Buffer) {
+		t.Helper()
+
+		remote, err := repo.Remote("origin")
+		require.NoError(t, err)
+
+		remoteRepo, err := git.PlainOpen(remote.Config().URLs[0])
+		require.NoError(t, err)
+
+		_, err = remoteRepo.Tag(tag)
+		assert.NoError(t, err)
+	}
+}
+
+// interruptRelease returns a setupFunc that creates a release commit and
+// leaves behind the journal of a release of tags that never finished.
+func interruptRelease(tags ...string) setupFunc {
+	return func(t *testing.T, repo *git.Repository, path string) {
+		t.Helper()
+
+		createReleaseCommit(t, repo, path)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+
+		data, err := json.Marshal(map[string]interface{}{
+			"commit": head.Hash().String(),
+			"tags":   tags,
+		})
+		require.NoError(t, err)
+
+		dir := filepath.Join(path, ".git", "gotagger")
+		require.NoError(t, os.MkdirAll(dir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "journal.json"), data, 0o600))
+	}
+}
+
+// writeFile returns a setupFunc that writes data to the file fn.
+func writeFile(fn, data string) setupFunc {
+	return writeFiles(map[string]string{fn: data})
+}
+
+// writeFiles returns a setupFunc that writes the data of each file name in
+// files.
+func writeFiles(files map[string]string) setupFunc {
+	return func(t *testing.T, repo *git.Repository, path string) {
+		t.Helper()
+
+		for fn, data := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(path, fn), []byte(data), 0o600))
+		}
+	}
+}
+
+// setupRemote returns a setupFunc that adds a bare "origin" remote after
+// calling setup.
+func setupRemote(setup setupFunc) setupFunc {
+	return func(t *testing.T, repo *git.Repository, path string) {
+		t.Helper()
+
+		setup(t, repo, path)
+
+		remotePath := t.TempDir()
+		_, err := git.PlainInit(remotePath, true)
+		require.NoError(t, err)
+
+		_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remotePath}})
+		require.NoError(t, err)
+	}
+}
+
+// pushTags returns a setupFunc that runs setup, and then pushes the existing
+// tags to origin.
+func pushTags(setup setupFunc) setupFunc {
+	return func(t *testing.T, repo *git.Repository, path string) {
+		t.Helper()
+
+		setup(t, repo, path)
+
+		out, err := exec.Command("git", "-C", path, "push", "--quiet", "origin", "--tags").CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+// setDefaultBranch returns a setupFunc that points the remote branch
+// origin/branch at rev, and makes it the default branch of origin.
+func setDefaultBranch(branch, rev string) setupFunc {
+	return func(t *testing.T, repo *git.Repository, path string) {
+		t.Helper()
+
+		for _, args := range [][]string{
+			{"update-ref", "refs/remotes/origin/" + branch, rev},
+			{"symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/" + branch},
+		} {
+			out, err := exec.Command("git", append([]string{"-C", path}, args...)...).CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+}
+
+// createModules commits a root module foo and a submodule foo/sub.
+func createModules(t *testing.T, repo *git.Repository, path string) {
+	t.Helper()
+
+	testutils.CommitFile(t, repo, path, "go.mod", "feat: add go.mod", []byte("module foo\n"))
+	testutils.CommitFile(t, repo, path, filepath.Join("sub", "go.mod"), "feat: add sub/go.mod", []byte("module foo/sub\n"))
+}
+
+// commitAt returns a setup function that commits a change to foo.go with the
+// committer date date, and then dirties the worktree.
+func commitAt(date string) setupFunc {
+	return func(t *testing.T, repo *git.Repository, path string) {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(filepath.Join(path, "foo.go"), []byte("package foo\n"), 0o600))
+		for _, args := range [][]string{
+			{"add", "foo.go"},
+			{"commit", "-m", "fix: fix foo"},
+		} {
+			cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+			cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+
+		require.NoError(t, os.WriteFile(filepath.Join(path, "foo.go"), []byte("package bar\n"), 0o600))
+	}
+}
+
 func createReleaseCommit(t *testing.T, repo *git.Repository, path string) {
 	t.Helper()
 
@@ -0,0 +1,144 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/sassoftware/gotagger"
+	"github.com/sassoftware/gotagger/internal/migrate"
+)
+
+const migrateUsage = `Usage: %s migrate [OPTION]... TOOL [PATH]
+Print a gotagger configuration converted from the configuration of another
+tool to standard output. Settings that cannot be converted are reported on
+standard error.
+
+With no PATH the current directory is used.
+
+Tools:
+  commitlint
+        map every type allowed by the type-enum rule of .commitlintrc,
+        .commitlintrc.json, commitlint.config.js, or the commitlint key of
+        package.json. With -check, report differences between the allowed
+        types and the mappings in the gotagger configuration instead.
+  semantic-release
+        convert .releaserc, .releaserc.json, release.config.js, or the
+        release key of package.json.
+
+Only JSON configuration, or javascript that exports an object literal, is
+supported.
+
+Options:
+`
+
+// runMigrate runs the migrate subcommand.
+func (g *GoTagger) runMigrate(args []string) int {
+	flags := flag.NewFlagSet(AppName+" migrate", flag.ContinueOnError)
+	flags.SetOutput(g.Stderr)
+	flags.Usage = func() {
+		g.err.Printf(migrateUsage, AppName)
+		flags.PrintDefaults()
+	}
+
+	check := flags.Bool("check", false, "check the gotagger configuration instead of printing a new one")
+	configFile := flags.String("config", defaultConfigFlag, "path to the gotagger configuration file to check, relative to PATH")
+
+	if err := flags.Parse(args); err != nil {
+		return genericErrorExitCode
+	}
+
+	dir := flags.Arg(1)
+	if dir == "" {
+		dir = g.WorkingDir
+	}
+
+	var (
+		cfg      migrate.Config
+		warnings []string
+		err      error
+	)
+	switch tool := flags.Arg(0); tool {
+	case "commitlint":
+		var rules migrate.CommitlintRules
+		if rules, err = migrate.Commitlint(dir); err != nil {
+			break
+		}
+
+		if len(rules.Scopes) > 0 {
+			warnings = append(warnings, "scope-enum is ignored, increments only depend on the commit type")
+		}
+
+		if *check {
+			for _, warning := range warnings {
+				g.err.Println("warning:", warning)
+			}
+			return g.checkCommitlint(rules, filepath.Join(dir, *configFile))
+		}
+
+		cfg, err = rules.Config()
+	case "semantic-release":
+		if *check {
+			g.err.Println("error: -check is not supported for semantic-release")
+			return genericErrorExitCode
+		}
+		cfg, warnings, err = migrate.SemanticRelease(dir)
+	case "":
+		flags.Usage()
+		return genericErrorExitCode
+	default:
+		g.err.Printf("error: unknown tool %q\n", tool)
+		return genericErrorExitCode
+	}
+
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	for _, warning := range warnings {
+		g.err.Println("warning:", warning)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	g.out.Println(string(data))
+
+	return successExitCode
+}
+
+// checkCommitlint reports differences between the types allowed by rules and
+// the increment mappings in the gotagger configuration file fn.
+func (g *GoTagger) checkCommitlint(rules migrate.CommitlintRules, fn string) int {
+	cfg := gotagger.NewDefaultConfig()
+
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	if err := cfg.ParseJSON(data); err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	problems := rules.Check(cfg.CommitTypeTable)
+	for _, problem := range problems {
+		g.err.Println("error:", problem)
+	}
+
+	if len(problems) > 0 {
+		return genericErrorExitCode
+	}
+
+	return successExitCode
+}
@@ -0,0 +1,322 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sassoftware/gotagger"
+)
+
+const (
+	defaultListenFlag = "localhost:8080"
+
+	serveUsage = `Usage: %s serve [OPTION]... [ROOT]...
+Serve the versions of git repositories over HTTP.
+
+Each ROOT is the path to a git repository, optionally named as NAME=PATH.
+The name defaults to the last element of the path. With no ROOT the current
+directory is served. Each repository is configured by the gotagger
+configuration file in its root, if there is one.
+
+Endpoints:
+  GET /version?root=NAME&path=DIR&module=MODULE
+        print the versions of the repository NAME as JSON. root may be
+        omitted if there is only one ROOT. path is a directory of the
+        repository that filters the modules, as when gotagger is run in
+        that directory. module may be repeated.
+  POST /tag?root=NAME&path=DIR&push=true
+        tag HEAD of the repository NAME if it is a release commit, push the
+        tags if push is true, and print the versions as JSON. Only enabled
+        by -allow-tag.
+
+  GET /openapi.json
+        print the OpenAPI definition of these endpoints.
+
+If GOTAGGER_SERVE_TOKEN is set, then every request must authenticate with
+an "Authorization: Bearer TOKEN" header.
+
+Options:
+`
+)
+
+// openAPI is the OpenAPI definition of the endpoints of gotagger serve.
+// Keep it in sync with the handlers and response types.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+// serveResult is the JSON form of a gotagger.Result.
+type serveResult struct {
+	Module    string         `json:"module,omitempty"`
+	Path      string         `json:"path"`
+	Version   string         `json:"version"`
+	Commit    string         `json:"commit"`
+	LatestTag string         `json:"latestTag,omitempty"`
+	Warnings  []serveWarning `json:"warnings,omitempty"`
+}
+
+// serveWarning is the JSON form of a gotagger.Warning.
+type serveWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// serveResponse is the body of a successful response.
+type serveResponse struct {
+	Results []serveResult `json:"results"`
+}
+
+// serveError is the body of a failed response.
+type serveError struct {
+	Error string `json:"error"`
+}
+
+// server answers version requests for the repositories in roots.
+type server struct {
+	// roots maps the names of the repositories to their paths
+	roots map[string]string
+
+	// configFile is the path of the configuration file,
+	// relative to the root of each repository
+	configFile string
+
+	// allowTag enables POST /tag
+	allowTag bool
+
+	// token, if set, must be sent as a bearer token
+	token string
+
+	// tagging serializes releases, which must not run concurrently
+	tagging sync.Mutex
+}
+
+// runServe runs the serve subcommand.
+func (g *GoTagger) runServe(args []string) int {
+	flags := flag.NewFlagSet(AppName+" serve", flag.ContinueOnError)
+	flags.SetOutput(g.Stderr)
+	flags.Usage = func() {
+		g.err.Printf(serveUsage, AppName)
+		flags.PrintDefaults()
+	}
+
+	allowTag := flags.Bool("allow-tag", false, "enable POST /tag, which creates and pushes tags")
+	configFile := flags.String("config", defaultConfigFlag, "path to the gotagger configuration file, relative to each ROOT")
+	listen := flags.String("listen", defaultListenFlag, "address to listen on")
+
+	if err := flags.Parse(args); err != nil {
+		return genericErrorExitCode
+	}
+
+	s := &server{
+		roots:      map[string]string{},
+		configFile: *configFile,
+		allowTag:   *allowTag,
+	}
+	s.token, _ = g.getEnv("serve_token")
+
+	roots := flags.Args()
+	if len(roots) == 0 {
+		roots = []string{g.WorkingDir}
+	}
+	for _, root := range roots {
+		name, dir, ok := strings.Cut(root, "=")
+		if !ok {
+			dir = root
+			name = filepath.Base(filepath.Clean(root))
+		}
+
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(g.WorkingDir, dir)
+		}
+
+		if _, exists := s.roots[name]; exists {
+			g.err.Printf("error: duplicate root %q\n", name)
+			return genericErrorExitCode
+		}
+		s.roots[name] = dir
+	}
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	g.err.Println("listening on", *listen)
+	if err := srv.ListenAndServe(); err != nil {
+		g.err.Println("error:", err)
+		return genericErrorExitCode
+	}
+
+	return successExitCode
+}
+
+// handler returns the HTTP handler of the server.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /version", s.version)
+	mux.HandleFunc("POST /tag", s.tag)
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				writeJSON(w, http.StatusUnauthorized, serveError{Error: "missing or invalid token"})
+				return
+			}
+		}
+
+		mux.ServeHTTP(w, req)
+	})
+}
+
+// version handles GET /version.
+func (s *server) version(w http.ResponseWriter, req *http.Request) {
+	r, status, err := s.open(req)
+	if err != nil {
+		writeJSON(w, status, serveError{Error: err.Error()})
+		return
+	}
+
+	var names []string
+	if values := req.URL.Query()["module"]; len(values) > 0 {
+		if names, err = resolveModules(r, values); err != nil {
+			writeJSON(w, http.StatusBadRequest, serveError{Error: err.Error()})
+			return
+		}
+	}
+
+	results, err := r.Results(names...)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, serveError{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newServeResponse(results))
+}
+
+// tag handles POST /tag.
+func (s *server) tag(w http.ResponseWriter, req *http.Request) {
+	if !s.allowTag {
+		writeJSON(w, http.StatusForbidden, serveError{Error: "tagging is not enabled"})
+		return
+	}
+
+	r, status, err := s.open(req)
+	if err != nil {
+		writeJSON(w, status, serveError{Error: err.Error()})
+		return
+	}
+
+	r.Config.CreateTag = true
+	if push := req.URL.Query().Get("push"); push != "" {
+		if r.Config.PushTag, err = strconv.ParseBool(push); err != nil {
+			writeJSON(w, http.StatusBadRequest, serveError{Error: fmt.Sprintf("invalid push value %q", push)})
+			return
+		}
+	}
+
+	s.tagging.Lock()
+	defer s.tagging.Unlock()
+
+	results, err := r.TagRepoResults()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gotagger.ErrInterruptedRelease) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, serveError{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newServeResponse(results))
+}
+
+// open returns a Gotagger for the root and path of req, and the status
+// to respond with if there is an error.
+func (s *server) open(req *http.Request) (*gotagger.Gotagger, int, error) {
+	query := req.URL.Query()
+
+	name := query.Get("root")
+	if name == "" && len(s.roots) == 1 {
+		for n := range s.roots {
+			name = n
+		}
+	}
+
+	root, ok := s.roots[name]
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("unknown root %q", name)
+	}
+
+	dir := root
+	if p := query.Get("path"); p != "" {
+		if !filepath.IsLocal(p) {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid path %q: must be a directory of the repository", p)
+		}
+		dir = filepath.Join(root, p)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid path %q: not a directory", query.Get("path"))
+	}
+
+	r, err := gotagger.New(dir)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, s.configFile))
+	if err != nil && !(s.configFile == defaultConfigFlag && errors.Is(err, os.ErrNotExist)) {
+		return nil, http.StatusInternalServerError, err
+	}
+	if err == nil {
+		if err := r.Config.ParseJSON(data); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	return r, http.StatusOK, nil
+}
+
+func newServeResponse(results []gotagger.Result) serveResponse {
+	resp := serveResponse{Results: make([]serveResult, len(results))}
+	for i, res := range results {
+		resp.Results[i] = serveResult{
+			Module:    res.Module,
+			Path:      filepath.ToSlash(res.Path),
+			Version:   res.Version,
+			Commit:    res.Commit,
+			LatestTag: res.LatestTag,
+		}
+		for _, w := range res.Warnings {
+			resp.Results[i].Warnings = append(resp.Results[i].Warnings, serveWarning{Code: w.Code, Message: w.Message})
+		}
+	}
+
+	return resp
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,214 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sassoftware/gotagger/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	tests := []struct {
+		title      string
+		method     string
+		target     string
+		token      string
+		allowTag   bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			title:      "version",
+			method:     http.MethodGet,
+			target:     "/version",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"results":[{"path":".","version":"v1.1.0","commit":"` + head.Hash().String() + `","latestTag":"v1.0.0"}]}`,
+		},
+		{
+			title:      "version by root",
+			method:     http.MethodGet,
+			target:     "/version?root=repo",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"results":[{"path":".","version":"v1.1.0","commit":"` + head.Hash().String() + `","latestTag":"v1.0.0"}]}`,
+		},
+		{
+			title:      "unknown root",
+			method:     http.MethodGet,
+			target:     "/version?root=other",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"unknown root \"other\""}`,
+		},
+		{
+			title:      "path outside of root",
+			method:     http.MethodGet,
+			target:     "/version?path=../other",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid path \"../other\": must be a directory of the repository"}`,
+		},
+		{
+			title:      "path is not a directory",
+			method:     http.MethodGet,
+			target:     "/version?path=missing",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid path \"missing\": not a directory"}`,
+		},
+		{
+			title:      "unknown module",
+			method:     http.MethodGet,
+			target:     "/version?module=missing",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"unknown module \"missing\""}`,
+		},
+		{
+			title:      "tagging disabled",
+			method:     http.MethodPost,
+			target:     "/tag",
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"error":"tagging is not enabled"}`,
+		},
+		{
+			title:      "invalid push",
+			method:     http.MethodPost,
+			target:     "/tag?push=maybe",
+			allowTag:   true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid push value \"maybe\""}`,
+		},
+		{
+			title:      "missing token",
+			method:     http.MethodGet,
+			target:     "/version",
+			token:      "secret",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"error":"missing or invalid token"}`,
+		},
+		{
+			title:      "wrong method",
+			method:     http.MethodPost,
+			target:     "/version",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			s := &server{
+				roots:      map[string]string{"repo": path},
+				configFile: defaultConfigFlag,
+				allowTag:   tt.allowTag,
+				token:      tt.token,
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			rec := httptest.NewRecorder()
+			s.handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_tag(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+	createReleaseCommit(t, repo, path)
+
+	s := &server{
+		roots:      map[string]string{"repo": path},
+		configFile: defaultConfigFlag,
+		allowTag:   true,
+		token:      "secret",
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/tag", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp serveResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Results, 1) {
+		assert.Equal(t, "v1.1.0", resp.Results[0].Version)
+	}
+
+	_, err := repo.Tag("v1.1.0")
+	assert.NoError(t, err)
+}
+
+func TestServer_openAPI(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+
+	s := &server{roots: map[string]string{"repo": path}, configFile: defaultConfigFlag}
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var spec struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+
+	// every operation is handled
+	for p, operations := range spec.Paths {
+		for method := range operations {
+			rec := httptest.NewRecorder()
+			s.handler().ServeHTTP(rec, httptest.NewRequest(strings.ToUpper(method), p, nil))
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "%s %s", method, p)
+		}
+	}
+
+	// the schemas match the responses
+	jsonNames := func(v any) []string {
+		var names []string
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	propertyNames := func(schema string) []string {
+		var names []string
+		for name := range spec.Components.Schemas[schema].Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	assert.Equal(t, jsonNames(serveResult{}), propertyNames("Result"))
+	assert.Equal(t, jsonNames(serveWarning{}), propertyNames("Warning"))
+	assert.Equal(t, jsonNames(serveResponse{}), propertyNames("Results"))
+	assert.Equal(t, jsonNames(serveError{}), propertyNames("Error"))
+}
@@ -0,0 +1,148 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sassoftware/gotagger/internal/git"
+	"github.com/sassoftware/gotagger/internal/gitignore"
+)
+
+// codeOwnersFiles are the places a CODEOWNERS file is looked for, relative to
+// the root of the repository, in the order GitHub looks for them.
+var codeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// codeOwners maps files to their owners, as listed in a CODEOWNERS file.
+// The zero value has no owners.
+type codeOwners struct {
+	rules []ownerRule
+}
+
+// ownerRule is a line of a CODEOWNERS file.
+type ownerRule struct {
+	pattern gitignore.Pattern
+	owners  []string
+}
+
+// codeOwners returns the codeOwners of the repository if Config.CodeOwners is
+// set. The CODEOWNERS file is read from the same place as go modules are
+// discovered: Config.FS if set, the tree of HEAD if Config.CommittedModules
+// is set, or else the worktree.
+func (g *Gotagger) codeOwners() (codeOwners, error) {
+	if !g.Config.CodeOwners {
+		return codeOwners{}, nil
+	}
+
+	for _, name := range codeOwnersFiles {
+		var (
+			data []byte
+			err  error
+		)
+		switch {
+		case g.Config.FS != nil:
+			data, err = fs.ReadFile(g.Config.FS, name)
+		case g.Config.CommittedModules:
+			data, err = g.repo.ReadFileAt(head, name)
+		default:
+			data, err = os.ReadFile(filepath.Join(g.repo.Path, filepath.FromSlash(name)))
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return codeOwners{}, err
+		}
+
+		g.logger.Info("found code owners", "path", name)
+		return parseCodeOwners(data), nil
+	}
+
+	g.logger.Info("no CODEOWNERS file found")
+	return codeOwners{}, nil
+}
+
+// parseCodeOwners parses the contents of a CODEOWNERS file. Each line is a
+// gitignore style pattern followed by its owners, and blank lines, comments,
+// and GitLab section headers are skipped.
+func parseCodeOwners(data []byte) codeOwners {
+	var c codeOwners
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") ||
+			strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+
+		c.rules = append(c.rules, ownerRule{
+			pattern: gitignore.ParsePattern(fields[0]),
+			owners:  fields[1:],
+		})
+	}
+
+	return c
+}
+
+// owners returns the owners of the file name, relative to the root of the
+// repository. The last matching line of the CODEOWNERS file wins, so a file
+// matched by a line without owners has none.
+func (c codeOwners) owners(name string) []string {
+	parts := strings.Split(filepath.ToSlash(name), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.Match(parts, false) != gitignore.NoMatch {
+			return c.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// changedOwners returns the sorted owners of the files changed by commits
+// for which owned returns true.
+func (c codeOwners) changedOwners(commits []git.Commit, owned func(name string) bool) []string {
+	if len(c.rules) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	add := func(name string) {
+		if name == "" || !owned(name) {
+			return
+		}
+		for _, owner := range c.owners(name) {
+			seen[owner] = struct{}{}
+		}
+	}
+	for _, commit := range commits {
+		for _, change := range commit.Changes {
+			add(change.SourceName)
+			add(change.DestName)
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+
+	owners := make([]string, 0, len(seen))
+	for owner := range seen {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	return owners
+}
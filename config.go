@@ -3,34 +3,518 @@ package gotagger
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/sassoftware/gotagger/changelog"
 	"github.com/sassoftware/gotagger/mapper"
 )
 
 type config struct {
-	DefaultIncrement         string            `json:"defaultIncrement"`
-	IncrementDirtyWorktree   string            `json:"incrementDirtyWorktree"`
-	ExcludeModules           []string          `json:"excludeModules"`
-	IgnoreModules            bool              `json:"ignoreModules"`
-	IncrementMappings        map[string]string `json:"incrementMappings"`
-	IncrementPreReleaseMinor bool              `json:"incrementPreReleaseMinor"`
-	VersionPrefix            *string           `json:"versionPrefix"`
+	ChangelogFormat             string                          `json:"changelogFormat"`
+	ChangelogLinks              changelog.Links                 `json:"changelogLinks"`
+	ChangelogPreset             string                          `json:"changelogPreset"`
+	ChangelogStats              bool                            `json:"changelogStats"`
+	ChangelogTopics             bool                            `json:"changelogTopics"`
+	CodeOwners                  bool                            `json:"codeOwners"`
+	CommitCache                 bool                            `json:"commitCache"`
+	CommitTypeAliases           map[string]string               `json:"commitTypeAliases"`
+	CommittedModules            bool                            `json:"committedModules"`
+	DefaultBranch               string                          `json:"defaultBranch"`
+	DefaultIncrement            string                          `json:"defaultIncrement"`
+	DependencyUpdates           *dependencyConfig               `json:"dependencyUpdates"`
+	IncrementDirtyWorktree      string                          `json:"incrementDirtyWorktree"`
+	DirtyWorktreeSuffix         string                          `json:"dirtyWorktreeSuffix"`
+	ExcludeCommits              *CommitFilter                   `json:"excludeCommits"`
+	ExcludeFiles                []string                        `json:"excludeFiles"`
+	ExcludeModules              []string                        `json:"excludeModules"`
+	ExistingTags                string                          `json:"existingTags"`
+	FloatingTags                string                          `json:"floatingTags"`
+	FollowSymlinks              bool                            `json:"followSymlinks"`
+	ForcePushFloatingTags       bool                            `json:"forcePushFloatingTags"`
+	IgnoreModules               bool                            `json:"ignoreModules"`
+	IncrementMappings           map[string]string               `json:"incrementMappings"`
+	IncrementMerges             string                          `json:"incrementMerges"`
+	IncrementPreReleaseBreaking string                          `json:"incrementPreReleaseBreaking"`
+	IncrementPreReleaseFeature  string                          `json:"incrementPreReleaseFeature"`
+	IncrementPreReleaseMinor    bool                            `json:"incrementPreReleaseMinor"`
+	ModuleAliases               map[string]string               `json:"moduleAliases"`
+	ModuleChangelogs            bool                            `json:"moduleChangelogs"`
+	ModuleIncrementMappings     map[string]moduleMappingsConfig `json:"moduleIncrementMappings"`
+	ModuleOrder                 string                          `json:"moduleOrder"`
+	OutsideChanges              string                          `json:"outsideChanges"`
+	PushFollowTags              bool                            `json:"pushFollowTags"`
+	PushOptions                 []string                        `json:"pushOptions"`
+	PushUsername                string                          `json:"pushUsername"`
+	ReleaseBranches             []string                        `json:"releaseBranches"`
+	RequireExplicitModules      bool                            `json:"requireExplicitModules"`
+	RequirePromote              bool                            `json:"requirePromote"`
+	StrictCommitTypes           bool                            `json:"strictCommitTypes"`
+	TagDate                     string                          `json:"tagDate"`
+	TagLimit                    int                             `json:"tagLimit"`
+	TagNamespace                string                          `json:"tagNamespace"`
+	UmbrellaVersion             bool                            `json:"umbrellaVersion"`
+	UnchangedReleases           string                          `json:"unchangedReleases"`
+	VerifyTags                  string                          `json:"verifyTags"`
+	VersionFile                 string                          `json:"versionFile"`
+	VersionPrefix               *string                         `json:"versionPrefix"`
+}
+
+// moduleMappingsConfig is the configuration file form of a commit type table
+// in ModuleCommitTypeTables.
+type moduleMappingsConfig struct {
+	DefaultIncrement  string            `json:"defaultIncrement"`
+	IncrementMappings map[string]string `json:"incrementMappings"`
+}
+
+// dependencyConfig is the configuration file form of DependencyUpdates.
+type dependencyConfig struct {
+	Authors   []string `json:"authors"`
+	Increment string   `json:"increment"`
+	Scopes    []string `json:"scopes"`
+}
+
+// PushOptions control how gotagger pushes tags.
+type PushOptions struct {
+	// FollowTags pushes the tags with git push --follow-tags, which also
+	// pushes every annotated tag reachable from the tagged commits that the
+	// remote is missing. The push is refused if any of those tags are not
+	// part of the release, since they could not be removed if the release
+	// fails. HEAD and the current branch are never pushed.
+	FollowTags bool
+
+	// Options is a list of push options to send to the remote,
+	// for example "ci.skip". See git push --push-option.
+	Options []string
+
+	// Token is a credential used to authenticate HTTPS pushes.
+	// It is passed to git using the http.extraHeader configuration,
+	// instead of relying on ambient credentials such as a netrc file.
+	Token string
+
+	// Username is the user name used with Token. Defaults to
+	// "x-access-token".
+	Username string
+}
+
+// TagVerification is a policy for version tags whose signatures cannot be
+// verified.
+type TagVerification int
+
+const (
+	// TagVerificationNone disables tag signature verification.
+	TagVerificationNone TagVerification = iota
+
+	// TagVerificationSkip ignores version tags that fail verification.
+	TagVerificationSkip
+
+	// TagVerificationFail returns an error if the latest version tag fails
+	// verification.
+	TagVerificationFail
+)
+
+// ParseTagVerification converts a string into a TagVerification policy.
+// Valid values are "none", "skip", and "fail". The empty string is
+// equivalent to "none".
+func ParseTagVerification(s string) (TagVerification, error) {
+	switch s {
+	case "none", "":
+		return TagVerificationNone, nil
+	case "skip":
+		return TagVerificationSkip, nil
+	case "fail":
+		return TagVerificationFail, nil
+	}
+
+	return TagVerificationNone, fmt.Errorf("invalid tag verification policy '%s'", s)
+}
+
+const (
+	// TagDateAuthor dates tags with the author date of the tagged commit.
+	TagDateAuthor = "author"
+
+	// TagDateCommitter dates tags with the committer date of the tagged
+	// commit.
+	TagDateCommitter = "committer"
+)
+
+// ExistingTagPolicy controls what happens when a tag that would be created
+// already exists, such as when a release is run again.
+type ExistingTagPolicy int
+
+const (
+	// ExistingTagsFail returns an error listing every existing tag and the
+	// commit it points to.
+	ExistingTagsFail ExistingTagPolicy = iota
+
+	// ExistingTagsSkip does not create tags that already exist and point to
+	// the commit being tagged, so that a release can be run again. Tags that
+	// point to other commits are still an error.
+	ExistingTagsSkip
+)
+
+// ParseExistingTags converts a string into an ExistingTagPolicy.
+// Valid values are "fail" and "skip". The empty string is equivalent to
+// "fail".
+func ParseExistingTags(s string) (ExistingTagPolicy, error) {
+	switch s {
+	case "fail", "":
+		return ExistingTagsFail, nil
+	case "skip":
+		return ExistingTagsSkip, nil
+	}
+
+	return ExistingTagsFail, fmt.Errorf("invalid existing tag policy '%s'", s)
+}
+
+// FloatingTagPolicy controls which floating alias tags, such as v1 or v1.2,
+// are moved to each new release.
+type FloatingTagPolicy int
+
+const (
+	// FloatingTagsNone does not create floating tags.
+	FloatingTagsNone FloatingTagPolicy = iota
+
+	// FloatingTagsMajor moves a major version tag, such as v1, to each
+	// release.
+	FloatingTagsMajor
+
+	// FloatingTagsMinor moves both a major and a minor version tag, such as
+	// v1 and v1.2, to each release.
+	FloatingTagsMinor
+)
+
+// ParseFloatingTags converts a string into a FloatingTagPolicy.
+// Valid values are "none", "major", and "minor". The empty string is
+// equivalent to "none".
+func ParseFloatingTags(s string) (FloatingTagPolicy, error) {
+	switch s {
+	case "none", "":
+		return FloatingTagsNone, nil
+	case "major":
+		return FloatingTagsMajor, nil
+	case "minor":
+		return FloatingTagsMinor, nil
+	}
+
+	return FloatingTagsNone, fmt.Errorf("invalid floating tag policy '%s'", s)
+}
+
+// OutsideChangePolicy controls what happens when the commits since the latest
+// version of a module change files that do not belong to any module, such as
+// CI configuration at the root of a repository without a root module.
+type OutsideChangePolicy int
+
+const (
+	// OutsideChangesIgnore ignores changes to files outside of modules.
+	OutsideChangesIgnore OutsideChangePolicy = iota
+
+	// OutsideChangesWarn adds a warning to the result of each module whose
+	// commits change files outside of modules.
+	OutsideChangesWarn
+
+	// OutsideChangesFail returns an error if the commits of a module change
+	// files outside of modules.
+	OutsideChangesFail
+)
+
+// ParseOutsideChanges converts a string into an OutsideChangePolicy.
+// Valid values are "ignore", "warn", and "fail". The empty string is
+// equivalent to "ignore".
+func ParseOutsideChanges(s string) (OutsideChangePolicy, error) {
+	switch s {
+	case "ignore", "":
+		return OutsideChangesIgnore, nil
+	case "warn":
+		return OutsideChangesWarn, nil
+	case "fail":
+		return OutsideChangesFail, nil
+	}
+
+	return OutsideChangesIgnore, fmt.Errorf("invalid outside change policy '%s'", s)
+}
+
+// UnchangedReleasePolicy controls what happens when a release commit releases
+// a module without any commits that increment its version, so that its next
+// version is its latest version.
+type UnchangedReleasePolicy int
+
+const (
+	// UnchangedReleasesFail returns an error before anything is tagged.
+	UnchangedReleasesFail UnchangedReleasePolicy = iota
+
+	// UnchangedReleasesSkip does not tag the modules that did not change.
+	UnchangedReleasesSkip
+
+	// UnchangedReleasesPatch increments the patch version of the modules
+	// that did not change.
+	UnchangedReleasesPatch
+)
+
+// ParseUnchangedReleases converts a string into an UnchangedReleasePolicy.
+// Valid values are "fail", "skip", and "patch". The empty string is
+// equivalent to "fail".
+func ParseUnchangedReleases(s string) (UnchangedReleasePolicy, error) {
+	switch s {
+	case "fail", "":
+		return UnchangedReleasesFail, nil
+	case "skip":
+		return UnchangedReleasesSkip, nil
+	case "patch":
+		return UnchangedReleasesPatch, nil
+	}
+
+	return UnchangedReleasesFail, fmt.Errorf("invalid unchanged release policy '%s'", s)
+}
+
+// ModuleOrder controls the order in which the versions and results of go
+// modules are returned.
+type ModuleOrder int
+
+const (
+	// ModuleOrderPath orders modules by their directory, as a depth-first
+	// walk of the repository finds them: the root module first, and each
+	// module right before the modules nested in it.
+	ModuleOrderPath ModuleOrder = iota
+
+	// ModuleOrderName orders modules by their module path, such as foo/bar
+	// before foo/v2.
+	ModuleOrderName
+)
+
+// ParseModuleOrder converts a string into a ModuleOrder.
+// Valid values are "path" and "name". The empty string is equivalent to
+// "path".
+func ParseModuleOrder(s string) (ModuleOrder, error) {
+	switch s {
+	case "path", "":
+		return ModuleOrderPath, nil
+	case "name":
+		return ModuleOrderName, nil
+	}
+
+	return ModuleOrderPath, fmt.Errorf("invalid module order '%s'", s)
 }
 
 // Config represents how to tag a repo.
 //
 // If no default is mentioned, the option defaults to go's zero-value.
 type Config struct {
+	// ChangelogFormat is the format of the changelogs written by
+	// WriteChangelogs, which also determines their file names.
+	// Defaults to changelog.FormatMarkdown.
+	ChangelogFormat changelog.Format
+
+	// ChangelogLinks are URL templates for linking commit hashes and issue
+	// references, such as "#12", in markdown changelogs. Templates that are
+	// not set are detected from the URL of RemoteName if it is hosted by
+	// GitHub or GitLab, so set them for other self-hosted servers.
+	ChangelogLinks changelog.Links
+
+	// ChangelogPreset controls how changes are grouped into sections in the
+	// changelogs written by WriteChangelogs.
+	// Defaults to changelog.PresetKeepAChangelog.
+	ChangelogPreset changelog.Preset
+
+	// ChangelogStats controls whether changelog entries show how many lines
+	// each commit added and removed, as in "fix foo (0123456, +12/-3)".
+	// Counting lines makes listing commits slower.
+	ChangelogStats bool
+
+	// ChangelogTopics controls whether changelog entries are grouped by the
+	// Gerrit topic they were merged in, as found from merges with subjects
+	// such as `Merge changes from topic "foo"`. Finding topics runs extra git
+	// commands.
+	ChangelogTopics bool
+
+	// CheckUpstream controls whether gotagger refuses to create tags unless
+	// HEAD is the same commit as its upstream branch. This prevents tagging
+	// commits from a stale or diverged branch. The upstream branch is not
+	// fetched first.
+	CheckUpstream bool
+
+	// CodeOwners controls whether the owners of the files changed since the
+	// latest version, as listed in a CODEOWNERS file, are added to results,
+	// planned tags, provenance, and changelogs, so that release notifications
+	// can be routed to them. The file is looked for in .github, the root of
+	// the repository, docs, and .gitlab.
+	CodeOwners bool
+
+	// CommitCache controls whether parsed commit messages are saved in the
+	// git directory, so that later runs, such as other steps of the same
+	// pipeline, do not parse them again. Commits are always cached in memory
+	// while gotagger runs.
+	CommitCache bool
+
+	// CommitParser parses commit messages. Set it to version repositories
+	// whose commits do not follow the conventional commit format. Defaults to
+	// ConventionalCommitParser. Messages parsed by other parsers are not
+	// saved in the commit cache.
+	CommitParser CommitParser
+
+	// CommitTypeAliases maps commit types to the types they are aliases of,
+	// such as "bugfix" to "fix" and "feature" to "feat". Aliases are applied
+	// when commits are parsed, so aliased commits are versioned, checked by
+	// StrictCommitTypes, and listed in changelogs as the type they alias.
+	// The release type cannot be aliased.
+	CommitTypeAliases map[string]string
+
+	// CommitsSince controls whether the number of commits since the latest
+	// version is added to the version as a pre-release identifier, as in
+	// v1.2.3-r14. The count is calculated separately for each module.
+	CommitsSince bool
+
+	// CommittedModules controls whether go modules are discovered from the
+	// tree of the commit being versioned, as listed by git ls-tree, instead
+	// of the worktree. Uncommitted go.mod files are ignored, and committed
+	// go.mod files that were deleted from the worktree are still found.
+	// Ignored if FS is set.
+	CommittedModules bool
+
 	// CreateTag represents whether to create the tag.
 	CreateTag bool
 
+	// CurrentVersion is the latest version of modules and paths that have no
+	// version tags, such as the version of a project that is migrating from
+	// manual versioning. It is treated as the version of the commit being
+	// versioned, so no commits are counted since it. It takes precedence
+	// over VersionFile.
+	CurrentVersion string
+
+	// DefaultBranch is the name of the default branch of the repository, such
+	// as "main", which the preview command compares HEAD with when no base
+	// is given. If it is not set, then it is detected from the HEAD of
+	// RemoteName, as set by git clone or git remote set-head.
+	DefaultBranch string
+
+	// DependencyUpdates recognizes dependency update commits, such as those
+	// made by Dependabot or Renovate. If it is nil, then they are versioned
+	// like any other commit.
+	DependencyUpdates *DependencyUpdates
+
+	// ExcludeCommits matches commits, such as those of automation accounts,
+	// that are ignored when calculating versions and writing changelogs.
+	ExcludeCommits CommitFilter
+
+	// ExcludeFiles is a list of patterns, in gitignore syntax, of files whose
+	// changes are ignored when matching commits to the modules and paths they
+	// change, such as generated code like "**/*.pb.go". Patterns are relative
+	// to the root of the repository. A commit that only changes excluded
+	// files does not affect any version.
+	ExcludeFiles []string
+
 	// ExcludeModules is a list of module names or paths to exclude.
 	ExcludeModules []string
 
+	// ExistingTags controls what TagRepo does when a tag it would create
+	// already exists. Normally every existing tag is reported in an error
+	// before anything is tagged.
+	ExistingTags ExistingTagPolicy
+
+	// FS is the file system that go modules are discovered from. Paths in FS
+	// must be relative to the root of the repository. Defaults to the
+	// repository worktree.
+	FS fs.FS
+
+	// FloatingTags controls which floating alias tags, such as v1 or v1.2,
+	// are moved to point at each new release, as is common for GitHub
+	// Actions. Aliases are only moved for versions without a pre-release or
+	// build suffix, and never to a version lower than the highest release
+	// they cover. When FloatingTags is set, tags such as v1 and v1.2 are no
+	// longer treated as versions.
+	FloatingTags FloatingTagPolicy
+
+	// ForcePushFloatingTags allows floating tags to replace the tags of the
+	// same name on the remote when they are pushed. Without it, pushing a
+	// floating tag that already exists on the remote fails.
+	ForcePushFloatingTags bool
+
+	// FollowSymlinks controls whether module discovery walks into symlinked
+	// directories. By default they are skipped, so that a module is not
+	// found more than once. When set, each directory is only walked once,
+	// which also prevents symlink cycles. Directories are compared by their
+	// path with symlinks resolved, so a custom FS must have a ReadLink method.
+	FollowSymlinks bool
+
 	// IgnoreModules controls whether gotagger will ignore the existence of
 	// go.mod files when determining how to version a project.
 	IgnoreModules bool
 
+	// MergeIncrement is how much merge commits whose messages are not
+	// conventional commits increment the version, such as the "Merge branch"
+	// commits of merge-heavy histories. The default, mapper.IncrementNone,
+	// ignores them. When it is set, merge commits are considered to change
+	// the files that they change in their first parent.
+	// Conventional merge commits increment the version by their type.
+	MergeIncrement mapper.Increment
+
+	// ModuleAliases maps the names of modules to short aliases, such as
+	// "api" for "github.com/org/repo/services/api". Aliases can be used
+	// instead of module names in Modules footers and to select modules, and
+	// are shown in output and changelog headings.
+	ModuleAliases map[string]string
+
+	// ModuleChangelogs controls whether a release commit must update the
+	// changelog file in the directory of every module it releases.
+	// Use WriteChangelogs to update these files before committing.
+	ModuleChangelogs bool
+
+	// Nightly controls whether nightly pre-release identifiers with the
+	// current UTC date are added to versions, as in v1.3.0-nightly.20240615.
+	// Nothing is added to a version with no commits since the latest version.
+	// TagRepo never creates version tags for nightly versions, but moves
+	// NightlyTag instead.
+	Nightly bool
+
+	// NightlyTag is the name of a tag, such as nightly, that TagRepo moves to
+	// HEAD when Nightly and CreateTag are set, and pushes if PushTag is set.
+	// The tag replaces any tag of the same name on the remote.
+	NightlyTag string
+
+	// Offline guarantees that gotagger does not use the network, for
+	// air-gapped build environments. Git is run with every transport
+	// disabled and without fetching the missing objects of partial clones,
+	// and operations that would need the network, such as pushing tags with
+	// PushTag, fail with ErrOffline before changing anything.
+	Offline bool
+
+	// OutsideChanges controls what happens when the commits since the latest
+	// version of a module change files that do not belong to any module.
+	// Files in excluded modules, and files excluded by ExcludeFiles or the
+	// ignore file, are not outside of modules.
+	OutsideChanges OutsideChangePolicy
+
+	// ReleaseBranches is a list of glob patterns, such as "main" or
+	// "release/*". If set, then tags are only created if HEAD is on a branch
+	// that matches one of the patterns.
+	ReleaseBranches []string
+
+	// RequireExplicitModules controls whether a release commit in a project
+	// with go modules must list the modules to release in a Modules footer.
+	// Normally a release commit without a Modules footer releases the root
+	// module.
+	RequireExplicitModules bool
+
+	// RequirePromote controls whether TagRepo refuses to tag a 0.x.y version
+	// that breaking changes move to 1.0.0, unless the release is promoted by
+	// Promote or a "Promote: stable" footer. Such versions are always reported
+	// in the Warnings of the Result.
+	RequirePromote bool
+
+	// StrictCommitTypes controls how commits with an unknown type, such as a
+	// misspelled "faet", are handled. Normally they use the default increment
+	// of CommitTypeTable. When StrictCommitTypes is set, they do not
+	// increment the version, and are reported in the Warnings of the Result.
+	// Types are known if they are in CommitTypeTable or are conventional
+	// commit types. Commits that do not follow the conventional commit
+	// standard at all are not affected.
+	StrictCommitTypes bool
+
 	// RemoteName represents the name of the remote repository. Defaults to origin.
 	RemoteName string
 
@@ -38,9 +522,91 @@ type Config struct {
 	// to 1 for breaking changes.
 	PreMajor bool
 
+	// PreMajorBreakingIncrement controls how a 0.x.y version is incremented
+	// for breaking changes when PreMajor is set. The default,
+	// mapper.IncrementNone, increments based on the commit type.
+	PreMajorBreakingIncrement mapper.Increment
+
+	// PreMajorFeatureIncrement controls how a 0.x.y version is incremented
+	// for commits whose type maps to a minor increment when PreMajor is set.
+	// The default, mapper.IncrementNone, uses the commit type mapping.
+	PreMajorFeatureIncrement mapper.Increment
+
+	// Promote controls whether TagRepo will promote a 0.x.y version to 1.0.0,
+	// regardless of the commits since the latest version. This is equivalent
+	// to adding a "Promote: stable" footer to the release commit.
+	Promote bool
+
 	// PushTag represents whether to push the tag to the remote git repository.
 	PushTag bool
 
+	// PushOptions control how tags are pushed.
+	PushOptions PushOptions
+
+	// Reproducible controls whether outputs depend only on the commit being
+	// versioned, so that every run against it produces identical artifacts
+	// for attestations. Dates, such as the timestamps of traces and
+	// provenance, the dates of changelogs, and nightly versions, are the
+	// committer date of the commit instead of the current time, and the
+	// worktree is ignored, so DirtyWorktreeSuffix and DirtyWorktreeIncrement
+	// have no effect and provenance is never dirty.
+	Reproducible bool
+
+	// TagDate is the date that created tags record as the date they were
+	// created, for reproducible releases: TagDateAuthor or TagDateCommitter
+	// for the author or committer date of the tagged commit, or an RFC 3339
+	// date, such as "2024-06-01T12:00:00Z". The default, the empty string,
+	// uses the current time. Use NormalizeTagDate to validate it.
+	TagDate string
+
+	// TagLimit limits how many version tags are read for each module or
+	// path. Git sorts the tags by version, so only the TagLimit highest
+	// versions are considered. Floating tags, ignored tags, and other names
+	// that are not versions do not count towards the limit. This speeds up
+	// repositories with a very large number of tags. Zero, the default,
+	// reads every tag.
+	TagLimit int
+
+	// TagNamespace is the ref namespace that version tags are listed from,
+	// created in, and pushed to, such as refs/releases/ for mirrors that keep
+	// release tags outside of refs/tags/. Defaults to refs/tags/.
+	//
+	// Tags outside of refs/tags/ cannot be signed, and revisions passed to
+	// gotagger must use the full ref name of these tags.
+	TagNamespace string
+
+	// Trace controls whether each Result records how its version was
+	// calculated, such as the tags that were considered and the increment of
+	// each commit, for Gotagger.Trace.
+	Trace bool
+
+	// UmbrellaVersion controls whether the version of the root module is an
+	// umbrella version, which is incremented by the changes to every module
+	// since the latest version of the root module, giving the repository a
+	// single product version alongside the versions of its modules. Breaking
+	// changes to other modules increment the root module by their commit
+	// type instead, since a new major version needs a new module path.
+	UmbrellaVersion bool
+
+	// UnchangedReleases controls what TagRepo does when a release commit
+	// releases a module whose commits since its latest version do not
+	// increment the version, such as a module with only chore commits.
+	// Normally this is an error, since the latest version is already tagged.
+	UnchangedReleases UnchangedReleasePolicy
+
+	// VerifyTags controls whether gotagger verifies the signatures of version
+	// tags using git verify-tag, and what to do with tags that fail
+	// verification. Which keys are trusted is controlled by git's
+	// configuration.
+	VerifyTags TagVerification
+
+	// VersionFile is the path of a file, such as VERSION, relative to each
+	// module or path, that holds the latest version of modules and paths that
+	// have no version tags. The version is read from the commit being
+	// versioned, and is treated as the version of the last commit that
+	// changed the file, so only later commits are counted.
+	VersionFile string
+
 	// VersionPrefix is a string that will be added to the front of the version. Defaults to 'v'.
 	VersionPrefix string
 
@@ -48,15 +614,33 @@ type Config struct {
 	// if there are no new commits, but the worktree is "dirty".
 	DirtyWorktreeIncrement mapper.Increment
 
+	// DirtyWorktreeSuffix is a pre-release or build metadata suffix, such as
+	// "-dirty" or "+dirty", that is appended to the version if the worktree
+	// is "dirty".
+	DirtyWorktreeSuffix string
+
 	// CommitTypeTable used for looking up version increments based on the commit type.
 	CommitTypeTable mapper.Table
 
+	// ModuleCommitTypeTables maps the names or aliases of go modules to the
+	// commit type tables used for them instead of CommitTypeTable, such as
+	// one that maps every type to no increment for an experimental module.
+	// Breaking changes always increment the major version.
+	ModuleCommitTypeTables map[string]mapper.Table
+
+	// ModuleOrder controls the order of the versions and results of go
+	// modules, such as those returned by ModuleVersions and TagRepo.
+	// Defaults to ModuleOrderPath. The order of paths in Config.Paths is
+	// kept.
+	ModuleOrder ModuleOrder
+
 	// Force controls whether gotagger will create a tag even if HEAD is not a "release" commit.
 	Force bool
 
 	// Paths is a list of sub-paths within the repo to restrict the git
-	// history used to calculate a version. The versions returned will be
-	// prefixed with their path.
+	// history used to calculate a version. Paths are relative to the
+	// directory passed to New. The versions returned will be prefixed with
+	// their path.
 	Paths []string
 
 	/* TODO
@@ -93,29 +677,192 @@ func (c *Config) ParseJSON(data []byte) error {
 		c.DirtyWorktreeIncrement = inc
 	}
 
+	// validate dirty worktree suffix
+	if err := ValidateDirtyWorktreeSuffix(cfg.DirtyWorktreeSuffix); err != nil {
+		return err
+	}
+	c.DirtyWorktreeSuffix = cfg.DirtyWorktreeSuffix
+
+	// validate merge increment
+	inc, err = mapper.Convert(cfg.IncrementMerges)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid merge increment: %s", cfg.IncrementMerges)
+	case inc == mapper.IncrementMajor:
+		return fmt.Errorf("major version increments are not allowed for merge commits")
+	default:
+		c.MergeIncrement = inc
+	}
+
+	if len(cfg.CommitTypeAliases) > 0 {
+		if err := validateCommitTypeAliases(cfg.CommitTypeAliases); err != nil {
+			return err
+		}
+		c.CommitTypeAliases = cfg.CommitTypeAliases
+	}
+
+	if len(cfg.ModuleAliases) > 0 {
+		if err := validateModuleAliases(cfg.ModuleAliases); err != nil {
+			return err
+		}
+		c.ModuleAliases = cfg.ModuleAliases
+	}
+
+	if cfg.ExcludeCommits != nil {
+		for _, pattern := range append(cfg.ExcludeCommits.Authors, cfg.ExcludeCommits.Committers...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid excluded commit pattern %q: %w", pattern, err)
+			}
+		}
+		c.ExcludeCommits = *cfg.ExcludeCommits
+	}
+
+	if cfg.DependencyUpdates != nil {
+		if c.DependencyUpdates, err = cfg.DependencyUpdates.convert(); err != nil {
+			return err
+		}
+	}
+
+	// validate pre-release increments
+	if c.PreMajorBreakingIncrement, err = convertPreReleaseIncrement("breaking", cfg.IncrementPreReleaseBreaking); err != nil {
+		return err
+	}
+	if c.PreMajorFeatureIncrement, err = convertPreReleaseIncrement("feature", cfg.IncrementPreReleaseFeature); err != nil {
+		return err
+	}
+
+	if c.VerifyTags, err = ParseTagVerification(cfg.VerifyTags); err != nil {
+		return err
+	}
+
+	if c.ExistingTags, err = ParseExistingTags(cfg.ExistingTags); err != nil {
+		return err
+	}
+
+	if c.FloatingTags, err = ParseFloatingTags(cfg.FloatingTags); err != nil {
+		return err
+	}
+
+	if c.OutsideChanges, err = ParseOutsideChanges(cfg.OutsideChanges); err != nil {
+		return err
+	}
+
+	if c.ModuleOrder, err = ParseModuleOrder(cfg.ModuleOrder); err != nil {
+		return err
+	}
+
+	if c.UnchangedReleases, err = ParseUnchangedReleases(cfg.UnchangedReleases); err != nil {
+		return err
+	}
+
+	if c.TagDate, err = NormalizeTagDate(cfg.TagDate); err != nil {
+		return err
+	}
+
+	if cfg.TagLimit < 0 {
+		return fmt.Errorf("tagLimit must not be negative: %d", cfg.TagLimit)
+	}
+	c.TagLimit = cfg.TagLimit
+
+	if cfg.TagNamespace != "" {
+		if c.TagNamespace, err = NormalizeTagNamespace(cfg.TagNamespace); err != nil {
+			return err
+		}
+	}
+
+	if c.ChangelogFormat, err = changelog.ParseFormat(cfg.ChangelogFormat); err != nil {
+		return err
+	}
+	if c.ChangelogPreset, err = changelog.ParsePreset(cfg.ChangelogPreset); err != nil {
+		return err
+	}
+	if err := cfg.ChangelogLinks.Validate(); err != nil {
+		return err
+	}
+	c.ChangelogLinks = cfg.ChangelogLinks
+
 	// version prefix is a pointer
 	// so the config file can set it to ""
 	// and we can preserve the default of "v"
 	if cfg.VersionPrefix != nil {
-		c.VersionPrefix = *cfg.VersionPrefix
+		if c.VersionPrefix, err = NormalizeVersionPrefix(*cfg.VersionPrefix); err != nil {
+			return err
+		}
+	}
+
+	if cfg.VersionFile != "" {
+		if c.VersionFile, err = NormalizeVersionFile(cfg.VersionFile); err != nil {
+			return err
+		}
+	}
+
+	for _, pattern := range cfg.ReleaseBranches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid release branch pattern %q: %w", pattern, err)
+		}
+	}
+
+	if c.CommitTypeTable, err = newCommitTypeTable(cfg.IncrementMappings, cfg.DefaultIncrement); err != nil {
+		return err
+	}
+
+	if len(cfg.ModuleIncrementMappings) > 0 {
+		c.ModuleCommitTypeTables = make(map[string]mapper.Table, len(cfg.ModuleIncrementMappings))
+		for name, m := range cfg.ModuleIncrementMappings {
+			table, err := newCommitTypeTable(m.IncrementMappings, m.DefaultIncrement)
+			if err != nil {
+				return fmt.Errorf("invalid increment mappings for module %s: %w", name, err)
+			}
+			c.ModuleCommitTypeTables[name] = table
+		}
 	}
 
+	// copy over static values
+	c.ChangelogStats = cfg.ChangelogStats
+	c.ChangelogTopics = cfg.ChangelogTopics
+	c.CodeOwners = cfg.CodeOwners
+	c.CommitCache = cfg.CommitCache
+	c.CommittedModules = cfg.CommittedModules
+	c.DefaultBranch = cfg.DefaultBranch
+	c.ExcludeFiles = cfg.ExcludeFiles
+	c.ExcludeModules = cfg.ExcludeModules
+	c.FollowSymlinks = cfg.FollowSymlinks
+	c.ForcePushFloatingTags = cfg.ForcePushFloatingTags
+	c.IgnoreModules = cfg.IgnoreModules
+	c.ModuleChangelogs = cfg.ModuleChangelogs
+	c.PreMajor = cfg.IncrementPreReleaseMinor
+	c.PushOptions.FollowTags = cfg.PushFollowTags
+	c.PushOptions.Options = cfg.PushOptions
+	c.PushOptions.Username = cfg.PushUsername
+	c.ReleaseBranches = cfg.ReleaseBranches
+	c.RequireExplicitModules = cfg.RequireExplicitModules
+	c.RequirePromote = cfg.RequirePromote
+	c.StrictCommitTypes = cfg.StrictCommitTypes
+	c.UmbrellaVersion = cfg.UmbrellaVersion
+
+	return nil
+}
+
+// newCommitTypeTable returns the commit type table for the configured
+// mappings of commit types to increments and the default increment, which
+// defaults to patch.
+func newCommitTypeTable(mappings map[string]string, defaultIncrement string) (mapper.Table, error) {
 	// we do not allow configuring the release type,
 	// as it means something particular to gotagger
-	if _, ok := cfg.IncrementMappings["release"]; ok {
-		return fmt.Errorf("release mapping is not allowed")
+	if _, ok := mappings["release"]; ok {
+		return mapper.Table{}, fmt.Errorf("release mapping is not allowed")
 	}
 
 	// generate the commit type table from the parsed mappings
 	var table mapper.Mapper
-	for typ, inc := range cfg.IncrementMappings {
+	for typ, inc := range mappings {
 		conversion, err := mapper.Convert(inc)
 		if err != nil {
-			return err
+			return mapper.Table{}, err
 		}
 
 		if conversion == mapper.IncrementMajor {
-			return fmt.Errorf("major version increments cannot be mapped to commit types. use the commit spec directives for this")
+			return mapper.Table{}, fmt.Errorf("major version increments cannot be mapped to commit types. use the commit spec directives for this")
 		}
 
 		if table == nil {
@@ -123,28 +870,214 @@ func (c *Config) ParseJSON(data []byte) error {
 		}
 
 		table[typ] = conversion
-		continue
 	}
 
 	// default increment to patch
-	if cfg.DefaultIncrement == "" {
-		cfg.DefaultIncrement = "patch"
+	if defaultIncrement == "" {
+		defaultIncrement = "patch"
 	}
-	def, err := mapper.Convert(cfg.DefaultIncrement)
+	def, err := mapper.Convert(defaultIncrement)
 	if err != nil {
-		return err
+		return mapper.Table{}, err
 	}
 
-	c.CommitTypeTable = mapper.NewTable(table, def)
+	return mapper.NewTable(table, def), nil
+}
 
-	// copy over static values
-	c.ExcludeModules = cfg.ExcludeModules
-	c.IgnoreModules = cfg.IgnoreModules
-	c.PreMajor = cfg.IncrementPreReleaseMinor
+// convert returns the DependencyUpdates of d. The authors and scopes of
+// NewDependencyUpdates are used unless they are set, and the increment
+// defaults to patch.
+func (d *dependencyConfig) convert() (*DependencyUpdates, error) {
+	deps := NewDependencyUpdates()
+	if d.Authors != nil {
+		for _, pattern := range d.Authors {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid dependency author pattern %q: %w", pattern, err)
+			}
+		}
+		deps.Authors = d.Authors
+	}
+	if d.Scopes != nil {
+		deps.Scopes = d.Scopes
+	}
+
+	if d.Increment != "" {
+		inc, err := mapper.Convert(d.Increment)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("invalid dependency update increment: %s", d.Increment)
+		case inc == mapper.IncrementMajor:
+			return nil, fmt.Errorf("major version increments are not allowed for dependency updates")
+		}
+		deps.Increment = inc
+	}
+
+	return deps, nil
+}
+
+// ValidateDirtyWorktreeSuffix returns an error if suffix is not a valid
+// semver pre-release or build metadata suffix.
+func ValidateDirtyWorktreeSuffix(suffix string) error {
+	if suffix == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(suffix, "-") && !strings.HasPrefix(suffix, "+") {
+		return fmt.Errorf("invalid dirty worktree suffix %q: must start with '-' or '+'", suffix)
+	}
+
+	if _, err := semver.StrictNewVersion("0.0.0" + suffix); err != nil {
+		return fmt.Errorf("invalid dirty worktree suffix %q: %w", suffix, err)
+	}
 
 	return nil
 }
 
+// NormalizeVersionFile returns name, the path of a version file, cleaned and
+// with forward slashes. An error is returned if name is not a relative path
+// that stays inside the module or path it is relative to.
+func NormalizeVersionFile(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid version file %q: must be a relative path inside the module", name)
+	}
+
+	return path.Clean(filepath.ToSlash(name)), nil
+}
+
+// PrefixError is returned when a version prefix cannot be used in a tag name.
+type PrefixError struct {
+	// Prefix is the invalid version prefix.
+	Prefix string
+
+	// Reason describes why Prefix is invalid.
+	Reason string
+}
+
+func (e *PrefixError) Error() string {
+	return fmt.Sprintf("invalid version prefix %q: %s", e.Prefix, e.Reason)
+}
+
+// NormalizeVersionPrefix returns prefix with the leading "/" and "./"
+// and repeated slashes of a path-style prefix removed. A *PrefixError is
+// returned if the tags it would create are not valid tag names, as defined by
+// git check-ref-format, or if it starts with "refs/" or "-".
+func NormalizeVersionPrefix(prefix string) (string, error) {
+	invalid := func(reason string) (string, error) {
+		return "", &PrefixError{Prefix: prefix, Reason: reason}
+	}
+
+	if strings.IndexFunc(prefix, unicode.IsSpace) >= 0 {
+		return invalid("must not contain whitespace")
+	}
+
+	normalized := prefix
+	for strings.HasPrefix(normalized, "/") || strings.HasPrefix(normalized, "./") {
+		normalized = strings.TrimPrefix(strings.TrimPrefix(normalized, "."), "/")
+	}
+	for strings.Contains(normalized, "//") {
+		normalized = strings.ReplaceAll(normalized, "//", "/")
+	}
+
+	switch {
+	case strings.HasPrefix(normalized, "refs/"):
+		return invalid("must not start with refs/")
+	case strings.HasPrefix(normalized, "-"):
+		return invalid("must not start with '-'")
+	}
+
+	if reason := refNameProblem(normalized + "0.0.0"); reason != "" {
+		return invalid(reason)
+	}
+
+	return normalized, nil
+}
+
+// NormalizeTagDate returns the tag date s, as in Config.TagDate, with an
+// RFC 3339 date converted to UTC. An error is returned if s is not empty,
+// TagDateAuthor, TagDateCommitter, or an RFC 3339 date.
+func NormalizeTagDate(s string) (string, error) {
+	switch s {
+	case "", TagDateAuthor, TagDateCommitter:
+		return s, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid tag date %q: must be %q, %q, or an RFC 3339 date", s, TagDateAuthor, TagDateCommitter)
+	}
+
+	return date.UTC().Format(time.RFC3339), nil
+}
+
+// NormalizeTagNamespace returns the ref namespace ns with a trailing slash.
+// An error is returned if ns is not a valid ref namespace beneath refs/,
+// such as refs/releases/.
+func NormalizeTagNamespace(ns string) (string, error) {
+	normalized := strings.TrimSuffix(ns, "/") + "/"
+
+	var reason string
+	switch {
+	case !strings.HasPrefix(normalized, "refs/") || normalized == "refs/":
+		reason = "must be beneath refs/"
+	case strings.IndexFunc(ns, unicode.IsSpace) >= 0:
+		reason = "must not contain whitespace"
+	default:
+		reason = refNameProblem(normalized + "v0.0.0")
+	}
+
+	if reason != "" {
+		return "", fmt.Errorf("invalid tag namespace %q: %s", ns, reason)
+	}
+
+	return normalized, nil
+}
+
+// refNameProblem describes why name is not a valid ref name, as defined by
+// git check-ref-format, or returns the empty string if it is valid.
+func refNameProblem(name string) string {
+	switch {
+	case strings.Contains(name, ".."):
+		return "must not contain '..'"
+	case strings.Contains(name, "@{"):
+		return "must not contain '@{'"
+	case strings.Contains(name, "//"):
+		return "must not contain '//'"
+	}
+
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return unicode.IsControl(r) || strings.ContainsRune(`~^:?*[\`, r)
+	}); i >= 0 {
+		return fmt.Sprintf("must not contain %q", name[i:i+1])
+	}
+
+	components := strings.Split(name, "/")
+	for _, component := range components[:len(components)-1] {
+		switch {
+		case strings.HasPrefix(component, "."):
+			return fmt.Sprintf("path component %q must not start with '.'", component)
+		case strings.HasSuffix(component, ".lock"):
+			return fmt.Sprintf("path component %q must not end with '.lock'", component)
+		}
+	}
+	if strings.HasPrefix(components[len(components)-1], ".") {
+		return "must not start with '.' after a '/'"
+	}
+
+	return ""
+}
+
+func convertPreReleaseIncrement(name, inc string) (mapper.Increment, error) {
+	conversion, err := mapper.Convert(inc)
+	switch {
+	case err != nil:
+		return mapper.IncrementNone, fmt.Errorf("invalid pre-release %s increment: %s", name, inc)
+	case conversion == mapper.IncrementMajor:
+		return mapper.IncrementNone, fmt.Errorf("major version increments are not allowed for pre-release %s changes", name)
+	}
+
+	return conversion, nil
+}
+
 // NewDefaultConfig returns a Config with default options set.
 //
 // If an option is not mentioned, then the default is the zero-value for its type.
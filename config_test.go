@@ -3,6 +3,7 @@ package gotagger
 import (
 	"testing"
 
+	"github.com/sassoftware/gotagger/changelog"
 	"github.com/sassoftware/gotagger/mapper"
 	"github.com/stretchr/testify/assert"
 )
@@ -163,11 +164,571 @@ func TestConfig_ParseJSON(t *testing.T) {
 				),
 			},
 		},
+		{
+			title:          "path-style version prefix",
+			configFileData: `{"versionPrefix":"./releases//v"}`,
+			want: Config{
+				RemoteName:    "origin",
+				VersionPrefix: "releases/v",
+				CommitTypeTable: mapper.NewTable(
+					mapper.Mapper{
+						mapper.TypeFeature: mapper.IncrementMinor,
+					},
+					mapper.IncrementPatch,
+				),
+			},
+		},
+		{
+			title:          "invalid version prefix",
+			configFileData: `{"versionPrefix":"refs/tags/v"}`,
+			wantErr:        `invalid version prefix "refs/tags/v": must not start with refs/`,
+		},
+		{
+			title:          "floating tags",
+			configFileData: `{"floatingTags":"major","forcePushFloatingTags":true}`,
+			want: Config{
+				FloatingTags:          FloatingTagsMajor,
+				ForcePushFloatingTags: true,
+				RemoteName:            "origin",
+				VersionPrefix:         "v",
+				CommitTypeTable: mapper.NewTable(
+					mapper.Mapper{
+						mapper.TypeFeature: mapper.IncrementMinor,
+					},
+					mapper.IncrementPatch,
+				),
+			},
+		},
+		{
+			title:          "invalid floating tags",
+			configFileData: `{"floatingTags":"patch"}`,
+			wantErr:        "invalid floating tag policy 'patch'",
+		},
+		{
+			title:          "existing tags",
+			configFileData: `{"existingTags":"skip"}`,
+			want: Config{
+				ExistingTags:    ExistingTagsSkip,
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid existing tags",
+			configFileData: `{"existingTags":"replace"}`,
+			wantErr:        "invalid existing tag policy 'replace'",
+		},
+		{
+			title:          "outside changes",
+			configFileData: `{"outsideChanges":"warn"}`,
+			want: Config{
+				OutsideChanges:  OutsideChangesWarn,
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid outside changes",
+			configFileData: `{"outsideChanges":"error"}`,
+			wantErr:        "invalid outside change policy 'error'",
+		},
+		{
+			title:          "unchanged releases",
+			configFileData: `{"unchangedReleases":"skip"}`,
+			want: Config{
+				RemoteName:        "origin",
+				VersionPrefix:     "v",
+				UnchangedReleases: UnchangedReleasesSkip,
+				CommitTypeTable:   mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid unchanged releases",
+			configFileData: `{"unchangedReleases":"major"}`,
+			wantErr:        "invalid unchanged release policy 'major'",
+		},
+		{
+			title:          "module order",
+			configFileData: `{"moduleOrder":"name"}`,
+			want: Config{
+				ModuleOrder:     ModuleOrderName,
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid module order",
+			configFileData: `{"moduleOrder":"alphabetical"}`,
+			wantErr:        "invalid module order 'alphabetical'",
+		},
+		{
+			title:          "umbrella version",
+			configFileData: `{"umbrellaVersion":true}`,
+			want: Config{
+				RemoteName:      "origin",
+				UmbrellaVersion: true,
+				VersionPrefix:   "v",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "module aliases",
+			configFileData: `{"moduleAliases":{"foo/services/api":"api"}}`,
+			want: Config{
+				ModuleAliases:   map[string]string{"foo/services/api": "api"},
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "duplicate module aliases",
+			configFileData: `{"moduleAliases":{"foo/services/api":"api","foo/api":"api"}}`,
+			wantErr:        `modules foo/api and foo/services/api have the same alias "api"`,
+		},
+		{
+			title:          "invalid module alias",
+			configFileData: `{"moduleAliases":{"foo/services/api":"the api"}}`,
+			wantErr:        `invalid alias "the api" for module foo/services/api: must not contain commas or spaces`,
+		},
+		{
+			title:          "module alias of another module",
+			configFileData: `{"moduleAliases":{"foo/services/api":"foo/api","foo/api":"api"}}`,
+			wantErr:        `alias "foo/api" for module foo/services/api is the name of another module`,
+		},
+		{
+			title:          "commit type aliases",
+			configFileData: `{"commitTypeAliases":{"bugfix":"fix","feature":"feat"}}`,
+			want: Config{
+				CommitTypeAliases: map[string]string{"bugfix": "fix", "feature": "feat"},
+				RemoteName:        "origin",
+				VersionPrefix:     "v",
+				CommitTypeTable:   mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "release commit type alias",
+			configFileData: `{"commitTypeAliases":{"rel":"release"}}`,
+			wantErr:        "release commit type cannot be aliased",
+		},
+		{
+			title:          "invalid commit type alias",
+			configFileData: `{"commitTypeAliases":{"bug fix":"fix"}}`,
+			wantErr:        `invalid commit type "bug fix" in alias bug fix: must only contain letters, digits, and underscores`,
+		},
+		{
+			title:          "chained commit type aliases",
+			configFileData: `{"commitTypeAliases":{"bugfix":"bug","bug":"fix"}}`,
+			wantErr:        "commit type alias bugfix is an alias of bug, which is also an alias",
+		},
+		{
+			title:          "module increment mappings",
+			configFileData: `{"moduleIncrementMappings":{"foo/experimental":{"defaultIncrement":"none","incrementMappings":{"feat":"none"}}}}`,
+			want: Config{
+				ModuleCommitTypeTables: map[string]mapper.Table{
+					"foo/experimental": mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementNone}, mapper.IncrementNone),
+				},
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid module increment mappings",
+			configFileData: `{"moduleIncrementMappings":{"foo/experimental":{"incrementMappings":{"release":"none"}}}}`,
+			wantErr:        "invalid increment mappings for module foo/experimental: release mapping is not allowed",
+		},
+		{
+			title:          "tag namespace",
+			configFileData: `{"tagNamespace":"refs/releases"}`,
+			want: Config{
+				RemoteName:    "origin",
+				TagNamespace:  "refs/releases/",
+				VersionPrefix: "v",
+				CommitTypeTable: mapper.NewTable(
+					mapper.Mapper{
+						mapper.TypeFeature: mapper.IncrementMinor,
+					},
+					mapper.IncrementPatch,
+				),
+			},
+		},
+		{
+			title:          "invalid tag namespace",
+			configFileData: `{"tagNamespace":"releases/"}`,
+			wantErr:        `invalid tag namespace "releases/": must be beneath refs/`,
+		},
 		{
 			title:          "major dirty worktree increment",
 			configFileData: `{"incrementDirtyWorktree": "major"}`,
 			wantErr:        "major version increments are not allowed for dirty worktrees",
 		},
+		{
+			title:          "dirty worktree suffix",
+			configFileData: `{"dirtyWorktreeSuffix": "-dirty"}`,
+			want: Config{
+				RemoteName:          "origin",
+				VersionPrefix:       "v",
+				DirtyWorktreeSuffix: "-dirty",
+				CommitTypeTable:     mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid dirty worktree suffix",
+			configFileData: `{"dirtyWorktreeSuffix": "-dirty!"}`,
+			wantErr:        `invalid dirty worktree suffix "-dirty!": Invalid Prerelease string`,
+		},
+		{
+			title:          "push options",
+			configFileData: `{"pushFollowTags": true, "pushOptions": ["ci.skip"], "pushUsername": "oauth2"}`,
+			want: Config{
+				RemoteName:    "origin",
+				VersionPrefix: "v",
+				PushOptions: PushOptions{
+					FollowTags: true,
+					Options:    []string{"ci.skip"},
+					Username:   "oauth2",
+				},
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "release branches",
+			configFileData: `{"releaseBranches": ["main", "release/*"]}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ReleaseBranches: []string{"main", "release/*"},
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "default branch",
+			configFileData: `{"defaultBranch": "trunk"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				DefaultBranch:   "trunk",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "changelog format",
+			configFileData: `{"changelogFormat": "json"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ChangelogFormat: changelog.FormatJSON,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid changelog format",
+			configFileData: `{"changelogFormat": "html"}`,
+			wantErr:        "invalid changelog format 'html'",
+		},
+		{
+			title:          "changelog preset",
+			configFileData: `{"changelogPreset": "angular"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ChangelogPreset: changelog.PresetAngular,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid changelog preset",
+			configFileData: `{"changelogPreset": "atom"}`,
+			wantErr:        "invalid changelog preset 'atom'",
+		},
+		{
+			title:          "changelog stats",
+			configFileData: `{"changelogStats": true}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ChangelogStats:  true,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "changelog topics",
+			configFileData: `{"changelogTopics": true}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ChangelogTopics: true,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "code owners",
+			configFileData: `{"codeOwners": true}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CodeOwners:      true,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "changelog links",
+			configFileData: `{"changelogLinks": {"commit": "https://git.example.com/foo/commit/{hash}"}}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ChangelogLinks:  changelog.Links{Commit: "https://git.example.com/foo/commit/{hash}"},
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid changelog links",
+			configFileData: `{"changelogLinks": {"issue": "https://git.example.com/foo/issues"}}`,
+			wantErr:        `invalid issue link "https://git.example.com/foo/issues": must contain {id}`,
+		},
+		{
+			title:          "commit cache",
+			configFileData: `{"commitCache": true}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				CommitCache:     true,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "committed modules",
+			configFileData: `{"committedModules": true}`,
+			want: Config{
+				RemoteName:       "origin",
+				VersionPrefix:    "v",
+				CommittedModules: true,
+				CommitTypeTable:  mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "follow symlinks",
+			configFileData: `{"followSymlinks": true}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				FollowSymlinks:  true,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "module changelogs",
+			configFileData: `{"moduleChangelogs": true}`,
+			want: Config{
+				RemoteName:       "origin",
+				VersionPrefix:    "v",
+				ModuleChangelogs: true,
+				CommitTypeTable:  mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "require explicit modules",
+			configFileData: `{"requireExplicitModules": true}`,
+			want: Config{
+				RemoteName:             "origin",
+				VersionPrefix:          "v",
+				RequireExplicitModules: true,
+				CommitTypeTable:        mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "require promote",
+			configFileData: `{"requirePromote": true}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				RequirePromote:  true,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "strict commit types",
+			configFileData: `{"strictCommitTypes": true}`,
+			want: Config{
+				RemoteName:        "origin",
+				VersionPrefix:     "v",
+				StrictCommitTypes: true,
+				CommitTypeTable:   mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid release branch",
+			configFileData: `{"releaseBranches": ["release/["]}`,
+			wantErr:        `invalid release branch pattern "release/[": syntax error in pattern`,
+		},
+		{
+			title:          "tag date",
+			configFileData: `{"tagDate": "2024-06-01T14:00:00+02:00"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				TagDate:         "2024-06-01T12:00:00Z",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid tag date",
+			configFileData: `{"tagDate": "yesterday"}`,
+			wantErr:        `invalid tag date "yesterday": must be "author", "committer", or an RFC 3339 date`,
+		},
+		{
+			title:          "tag limit",
+			configFileData: `{"tagLimit": 100}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				TagLimit:        100,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "negative tag limit",
+			configFileData: `{"tagLimit": -1}`,
+			wantErr:        "tagLimit must not be negative: -1",
+		},
+		{
+			title:          "verify tags",
+			configFileData: `{"verifyTags": "skip"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				VerifyTags:      TagVerificationSkip,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid verify tags",
+			configFileData: `{"verifyTags": "always"}`,
+			wantErr:        "invalid tag verification policy 'always'",
+		},
+		{
+			title:          "dependency updates",
+			configFileData: `{"dependencyUpdates": {}}`,
+			want: Config{
+				RemoteName:        "origin",
+				VersionPrefix:     "v",
+				DependencyUpdates: NewDependencyUpdates(),
+				CommitTypeTable:   mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "custom dependency updates",
+			configFileData: `{"dependencyUpdates": {"authors": ["deps-bot@example.com"], "scopes": [], "increment": "none"}}`,
+			want: Config{
+				RemoteName:    "origin",
+				VersionPrefix: "v",
+				DependencyUpdates: &DependencyUpdates{
+					Authors:   []string{"deps-bot@example.com"},
+					Scopes:    []string{},
+					Increment: mapper.IncrementNone,
+				},
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid dependency author",
+			configFileData: `{"dependencyUpdates": {"authors": ["[bot@example.com"]}}`,
+			wantErr:        `invalid dependency author pattern "[bot@example.com": syntax error in pattern`,
+		},
+		{
+			title:          "major dependency increment",
+			configFileData: `{"dependencyUpdates": {"increment": "major"}}`,
+			wantErr:        "major version increments are not allowed for dependency updates",
+		},
+		{
+			title:          "exclude commits",
+			configFileData: `{"excludeCommits": {"authors": ["ci-bot@*"], "committers": ["*@ci.example.com"], "trailers": ["Automated-By"]}}`,
+			want: Config{
+				RemoteName:    "origin",
+				VersionPrefix: "v",
+				ExcludeCommits: CommitFilter{
+					Authors:    []string{"ci-bot@*"},
+					Committers: []string{"*@ci.example.com"},
+					Trailers:   []string{"Automated-By"},
+				},
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "invalid exclude commits pattern",
+			configFileData: `{"excludeCommits": {"committers": ["[ci"]}}`,
+			wantErr:        `invalid excluded commit pattern "[ci": syntax error in pattern`,
+		},
+		{
+			title:          "exclude files",
+			configFileData: `{"excludeFiles": ["**/*.pb.go", "zz_generated*"]}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				ExcludeFiles:    []string{"**/*.pb.go", "zz_generated*"},
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "merge increment",
+			configFileData: `{"incrementMerges": "patch"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				MergeIncrement:  mapper.IncrementPatch,
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "major merge increment",
+			configFileData: `{"incrementMerges": "major"}`,
+			wantErr:        "major version increments are not allowed for merge commits",
+		},
+		{
+			title:          "invalid merge increment",
+			configFileData: `{"incrementMerges": "some"}`,
+			wantErr:        "invalid merge increment: some",
+		},
+		{
+			title:          "version file",
+			configFileData: `{"versionFile": "./build/VERSION"}`,
+			want: Config{
+				RemoteName:      "origin",
+				VersionPrefix:   "v",
+				VersionFile:     "build/VERSION",
+				CommitTypeTable: mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "version file outside of module",
+			configFileData: `{"versionFile": "../VERSION"}`,
+			wantErr:        `invalid version file "../VERSION": must be a relative path inside the module`,
+		},
+		{
+			title:          "pre-release increments",
+			configFileData: `{"incrementPreReleaseBreaking": "minor", "incrementPreReleaseFeature": "patch"}`,
+			want: Config{
+				RemoteName:                "origin",
+				VersionPrefix:             "v",
+				PreMajorBreakingIncrement: mapper.IncrementMinor,
+				PreMajorFeatureIncrement:  mapper.IncrementPatch,
+				CommitTypeTable:           mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor}, mapper.IncrementPatch),
+			},
+		},
+		{
+			title:          "major pre-release breaking increment",
+			configFileData: `{"incrementPreReleaseBreaking": "major"}`,
+			wantErr:        "major version increments are not allowed for pre-release breaking changes",
+		},
+		{
+			title:          "invalid pre-release feature increment",
+			configFileData: `{"incrementPreReleaseFeature": "huge"}`,
+			wantErr:        "invalid pre-release feature increment: huge",
+		},
 		{
 			title:          "default config",
 			configFileData: `{}`,
@@ -201,3 +762,114 @@ func TestConfig_ParseJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeVersionPrefix(t *testing.T) {
+	tests := []struct {
+		prefix     string
+		want       string
+		wantReason string
+	}{
+		{prefix: "", want: ""},
+		{prefix: "v", want: "v"},
+		{prefix: "release-", want: "release-"},
+		{prefix: "releases/v", want: "releases/v"},
+		{prefix: "/v", want: "v"},
+		{prefix: "./releases//v", want: "releases/v"},
+		{prefix: "release v", wantReason: "must not contain whitespace"},
+		{prefix: "v\n", wantReason: "must not contain whitespace"},
+		{prefix: "refs/tags/v", wantReason: "must not start with refs/"},
+		{prefix: "/refs/tags/v", wantReason: "must not start with refs/"},
+		{prefix: "-v", wantReason: "must not start with '-'"},
+		{prefix: "v..", wantReason: "must not contain '..'"},
+		{prefix: "v@{", wantReason: "must not contain '@{'"},
+		{prefix: "v~", wantReason: `must not contain "~"`},
+		{prefix: "v:", wantReason: `must not contain ":"`},
+		{prefix: "v\\", wantReason: `must not contain "\\"`},
+		{prefix: ".releases/v", wantReason: `path component ".releases" must not start with '.'`},
+		{prefix: "releases.lock/v", wantReason: `path component "releases.lock" must not end with '.lock'`},
+		{prefix: "releases/.v", wantReason: "must not start with '.' after a '/'"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.prefix, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NormalizeVersionPrefix(tt.prefix)
+			if tt.wantReason == "" {
+				if assert.NoError(t, err) {
+					assert.Equal(t, tt.want, got)
+				}
+				return
+			}
+
+			var perr *PrefixError
+			if assert.ErrorAs(t, err, &perr) {
+				assert.Equal(t, tt.prefix, perr.Prefix)
+				assert.Equal(t, tt.wantReason, perr.Reason)
+			}
+		})
+	}
+}
+
+func TestNormalizeTagDate(t *testing.T) {
+	tests := []struct {
+		date    string
+		want    string
+		wantErr string
+	}{
+		{date: "", want: ""},
+		{date: "author", want: TagDateAuthor},
+		{date: "committer", want: TagDateCommitter},
+		{date: "2024-06-01T12:00:00Z", want: "2024-06-01T12:00:00Z"},
+		{date: "2024-06-01T08:00:00-04:00", want: "2024-06-01T12:00:00Z"},
+		{date: "2024-06-01", wantErr: `invalid tag date "2024-06-01": must be "author", "committer", or an RFC 3339 date`},
+		{date: "Author", wantErr: `invalid tag date "Author": must be "author", "committer", or an RFC 3339 date`},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeTagDate(tt.date)
+		if tt.wantErr != "" {
+			assert.EqualError(t, err, tt.wantErr, tt.date)
+			continue
+		}
+
+		if assert.NoError(t, err, tt.date) {
+			assert.Equal(t, tt.want, got, tt.date)
+		}
+	}
+}
+
+func TestNormalizeTagNamespace(t *testing.T) {
+	tests := []struct {
+		ns      string
+		want    string
+		wantErr string
+	}{
+		{ns: "refs/tags/", want: "refs/tags/"},
+		{ns: "refs/releases", want: "refs/releases/"},
+		{ns: "refs/mirror/releases/", want: "refs/mirror/releases/"},
+		{ns: "refs/", wantErr: `invalid tag namespace "refs/": must be beneath refs/`},
+		{ns: "releases", wantErr: `invalid tag namespace "releases": must be beneath refs/`},
+		{ns: "refs/my releases", wantErr: `invalid tag namespace "refs/my releases": must not contain whitespace`},
+		{ns: "refs//releases", wantErr: `invalid tag namespace "refs//releases": must not contain '//'`},
+		{ns: "refs/releases.lock", wantErr: `invalid tag namespace "refs/releases.lock": path component "releases.lock" must not end with '.lock'`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.ns, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NormalizeTagNamespace(tt.ns)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
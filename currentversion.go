@@ -0,0 +1,78 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// currentVersion returns the latest version of the module or path in dir,
+// which has no version tags, according to Config.CurrentVersion or
+// Config.VersionFile, and the hash of the commit that has that version.
+// moduleName is the name of the go module in dir, if any. It returns a nil
+// version if neither is set, or if dir has no version file.
+func (g *Gotagger) currentVersion(dir, moduleName string, opts releaseOptions) (*semver.Version, string, error) {
+	var (
+		version, source, hash string
+		err                   error
+	)
+	switch {
+	case g.Config.CurrentVersion != "":
+		version, source = g.Config.CurrentVersion, "current version"
+		if hash, err = g.repo.CommitHash(opts.base()); err != nil {
+			return nil, "", err
+		}
+	case g.Config.VersionFile != "":
+		name := path.Join(slashPath(dir), g.Config.VersionFile)
+		data, err := g.repo.ReadFileAt(opts.base(), name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", nil
+		} else if err != nil {
+			return nil, "", err
+		}
+		version, source = strings.TrimSpace(string(data)), "version in "+name
+
+		// the version was released by the last commit that changed the file
+		commits, err := g.repo.RevList(opts.base(), "", name)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(commits) == 0 {
+			return nil, "", nil
+		}
+		hash = commits[0].Hash
+	default:
+		return nil, "", nil
+	}
+
+	v, err := semver.NewVersion(strings.TrimPrefix(version, g.Config.VersionPrefix))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s %q: %w", source, version, err)
+	}
+
+	if moduleName != "" && !isModuleMajor(v, moduleName) {
+		return nil, "", fmt.Errorf("%s %s is not a valid version of module %s", source, version, moduleName)
+	}
+
+	return v, hash, nil
+}
+
+// isModuleMajor returns true if v has a major version that module
+// moduleName can be released as: v0 or v1 without a major version suffix,
+// or the major version of the suffix, as in foo/v2.
+func isModuleMajor(v *semver.Version, moduleName string) bool {
+	major := majorVersion(moduleName)
+	if major == "" {
+		return v.Major() <= 1
+	}
+
+	return strconv.FormatUint(v.Major(), 10) == strings.TrimPrefix(major, "v")
+}
@@ -0,0 +1,66 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"github.com/sassoftware/gotagger/internal/git"
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// DefaultDependencyAuthors are the author email patterns of the Dependabot
+// and Renovate bots.
+var DefaultDependencyAuthors = []string{
+	`*dependabot\[bot\]@users.noreply.github.com`,
+	`*renovate\[bot\]@users.noreply.github.com`,
+	"bot@renovateapp.com",
+}
+
+// DefaultDependencyScopes are the commit scopes that Dependabot and Renovate
+// use for dependency updates, as in build(deps) or chore(deps-dev).
+var DefaultDependencyScopes = []string{"deps", "deps-dev"}
+
+// DependencyUpdates recognizes commits that update dependencies, such as
+// those made by Dependabot or Renovate. Dependency updates increment the
+// version by their own increment, instead of by their commit type, and are
+// listed in their own changelog section.
+type DependencyUpdates struct {
+	// Authors is a list of patterns, as in path.Match, that match the
+	// email addresses of the authors of dependency updates. Matching
+	// ignores case.
+	Authors []string
+
+	// Scopes is a list of commit scopes of dependency updates.
+	Scopes []string
+
+	// Increment is how much a dependency update increments the version.
+	Increment mapper.Increment
+}
+
+// NewDependencyUpdates returns a DependencyUpdates that recognizes the
+// commits of Dependabot and Renovate, and increments the patch version for
+// them.
+func NewDependencyUpdates() *DependencyUpdates {
+	return &DependencyUpdates{
+		Authors:   append([]string(nil), DefaultDependencyAuthors...),
+		Scopes:    append([]string(nil), DefaultDependencyScopes...),
+		Increment: mapper.IncrementPatch,
+	}
+}
+
+// matches returns true if c is a dependency update.
+func (d *DependencyUpdates) matches(c git.Commit) bool {
+	if d == nil {
+		return false
+	}
+
+	if c.Scope != "" {
+		for _, scope := range d.Scopes {
+			if c.Scope == scope {
+				return true
+			}
+		}
+	}
+
+	return matchEmail(d.Authors, c.AuthorEmail)
+}
@@ -0,0 +1,83 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"path"
+	"strings"
+
+	"github.com/sassoftware/gotagger/internal/git"
+)
+
+// CommitFilter matches commits by their author, committer, or trailers, such
+// as the commits of automation accounts.
+type CommitFilter struct {
+	// Authors is a list of patterns, as in path.Match, that match the email
+	// addresses of authors. Matching ignores case.
+	Authors []string `json:"authors"`
+
+	// Committers is a list of patterns, as in path.Match, that match the
+	// email addresses of committers. Matching ignores case.
+	Committers []string `json:"committers"`
+
+	// Trailers is a list of commit message trailers, such as "Skip-Version"
+	// to match any value, or "Skip-Version: true" to match a single value.
+	// Trailer names ignore case.
+	Trailers []string `json:"trailers"`
+}
+
+// matches returns true if c matches any of the patterns or trailers of f.
+func (f CommitFilter) matches(c git.Commit) bool {
+	if matchEmail(f.Authors, c.AuthorEmail) || matchEmail(f.Committers, c.CommitterEmail) {
+		return true
+	}
+
+	for _, trailer := range f.Trailers {
+		name, value, hasValue := strings.Cut(trailer, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		for _, footer := range c.Footers {
+			if strings.EqualFold(footer.Title, name) && (!hasValue || strings.TrimSpace(footer.Text) == value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// excludeCommits returns commits without those that Config.ExcludeCommits
+// matches, which do not affect versions or changelogs.
+func (g *Gotagger) excludeCommits(commits []git.Commit) []git.Commit {
+	f := g.Config.ExcludeCommits
+	if len(f.Authors) == 0 && len(f.Committers) == 0 && len(f.Trailers) == 0 {
+		return commits
+	}
+
+	kept := make([]git.Commit, 0, len(commits))
+	for _, c := range commits {
+		if f.matches(c) {
+			g.logger.Info("excluding commit", "commit", c.Hash, "author", c.AuthorEmail)
+			continue
+		}
+		kept = append(kept, c)
+	}
+
+	return kept
+}
+
+// matchEmail returns true if email matches one of patterns, ignoring case.
+func matchEmail(patterns []string, email string) bool {
+	if email == "" {
+		return false
+	}
+
+	email = strings.ToLower(email)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), email); ok {
+			return true
+		}
+	}
+
+	return false
+}
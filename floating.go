@@ -0,0 +1,153 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// floatingVersionRegex matches the version of a floating tag, such as the 1
+// of v1 or the 1.2 of v1.2.
+var floatingVersionRegex = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// floatingTag is a floating alias tag, such as v1, and the version tag it
+// points to.
+type floatingTag struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+// floatingTags returns the floating tags to move to the version tags of
+// results, according to Config.FloatingTags. Floating tags are not moved for
+// versions with a pre-release or build suffix, or to a version lower than the
+// highest release they cover, such as a patch release of an older minor
+// version.
+func (g *Gotagger) floatingTags(results []Result) ([]floatingTag, error) {
+	if g.Config.FloatingTags == FloatingTagsNone {
+		return nil, nil
+	}
+
+	var floating []floatingTag
+	for _, res := range results {
+		v, ok := resultVersion(res)
+		if !ok || v.Prerelease() != "" || v.Metadata() != "" {
+			g.logger.Info("not moving floating tags to a pre-release", "version", res.Version)
+			continue
+		}
+
+		names := []string{fmt.Sprintf("%s%d", res.Prefix, v.Major())}
+		if g.Config.FloatingTags == FloatingTagsMinor {
+			names = append(names, fmt.Sprintf("%s%d.%d", res.Prefix, v.Major(), v.Minor()))
+		}
+
+		for _, name := range names {
+			highest, err := g.highestRelease(res.Prefix, name+".")
+			if err != nil {
+				return nil, err
+			}
+
+			if highest != nil && v.LessThan(highest) {
+				g.logger.Info("not moving floating tag to an older version", "tag", name, "version", res.Version, "highest", highest.String())
+				continue
+			}
+
+			floating = append(floating, floatingTag{Name: name, Tag: res.Version})
+		}
+	}
+
+	return floating, nil
+}
+
+// highestRelease returns the highest version, without a pre-release or build
+// suffix, of all of the tags that start with tagPrefix, whatever commit they
+// point to. prefix is the version prefix of the tags. It returns nil if there
+// are no such tags.
+func (g *Gotagger) highestRelease(prefix, tagPrefix string) (*semver.Version, error) {
+	tags, err := g.repo.LatestTags("", 0, tagPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var highest *semver.Version
+	for _, tag := range tags {
+		version := strings.TrimPrefix(tag, prefix)
+		if floatingVersionRegex.MatchString(version) {
+			continue
+		}
+
+		v, err := semver.NewVersion(version)
+		if err != nil || v.Prerelease() != "" || v.Metadata() != "" {
+			continue
+		}
+
+		if highest == nil || v.GreaterThan(highest) {
+			highest = v
+		}
+	}
+
+	return highest, nil
+}
+
+// filterFloatingTags returns tags without the floating tags that have prefix,
+// if Config.FloatingTags is set, so that tags such as v1 are not mistaken for
+// versions.
+func (g *Gotagger) filterFloatingTags(tags []string, prefix string) []string {
+	if g.Config.FloatingTags == FloatingTagsNone {
+		return tags
+	}
+
+	filtered := tags[:0:0]
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, prefix) || !floatingVersionRegex.MatchString(strings.TrimPrefix(tag, prefix)) {
+			filtered = append(filtered, tag)
+		}
+	}
+
+	return filtered
+}
+
+// moveFloatingTags points the floating tags at the commit hash. It returns
+// the objects the tags pointed to before, which restoreFloatingTags uses to
+// undo the moves. If a tag cannot be moved, then the tags already moved are
+// restored.
+func (g *Gotagger) moveFloatingTags(hash string, floating []floatingTag) (map[string]string, error) {
+	previous := make(map[string]string, len(floating))
+	for _, tag := range floating {
+		prev, err := g.repo.MoveTag(hash, tag.Name, tagMessage(tag.Tag))
+		if err != nil {
+			return nil, g.restoreFloatingTags(previous, err)
+		}
+
+		previous[tag.Name] = prev
+		g.events.Info("moved floating tag", "tag", tag.Name, "version", tag.Tag, "commit", hash)
+	}
+
+	return previous, nil
+}
+
+// restoreFloatingTags points floating tags back at the objects returned by
+// moveFloatingTags, and returns err along with any errors restoring them.
+func (g *Gotagger) restoreFloatingTags(previous map[string]string, err error) error {
+	for name, prev := range previous {
+		if rerr := g.repo.RestoreTag(name, prev); rerr != nil {
+			err = fmt.Errorf("%w\n%s", err, rerr)
+		}
+	}
+
+	return err
+}
+
+// floatingTagNames returns the names of the floating tags.
+func floatingTagNames(floating []floatingTag) []string {
+	names := make([]string, len(floating))
+	for i, tag := range floating {
+		names[i] = tag.Name
+	}
+
+	return names
+}
@@ -4,57 +4,236 @@
 package gotagger
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-logr/logr"
+	"github.com/sassoftware/gotagger/changelog"
 	"github.com/sassoftware/gotagger/internal/git"
 	"github.com/sassoftware/gotagger/mapper"
 	"golang.org/x/mod/modfile"
 )
 
 const (
-	filepathSep    = string(filepath.Separator)
 	goMod          = "go.mod"
 	goModSep       = "/"
 	head           = "HEAD"
+	includeFooter  = "Include-Nested"
+	minimumFooter  = "Minimum-Increment"
+	modulesFooter  = "Modules"
+	promoteFooter  = "Promote"
+	promoteStable  = "stable"
 	rootModulePath = "."
+	trainFooter    = "Release-Train"
 )
 
+// defaultPushUsername is the user name used for token authentication.
+// GitHub requires this value, and most other servers ignore it.
+const defaultPushUsername = "x-access-token"
+
 var (
 	ErrNoSubmodule = errors.New("no submodule found")
 	ErrNotRelease  = errors.New("HEAD is not a release commit")
+
+	// ErrEmptyRepository is returned when versioning a repository that has
+	// no commits yet, such as one that was just initialized.
+	ErrEmptyRepository = errors.New("repository has no commits")
 )
 
+// Gotagger calculates versions for, and tags, a git repository.
+//
+// A Gotagger is safe for concurrent use by multiple goroutines, such as a
+// server calculating the versions of many modules in parallel, as long as
+// Config is not changed and SetLogger is not called while other methods are
+// running. Methods only read Config. Methods that change the repository,
+// such as TagRepo, Resume, and WriteChangelogs, must not run concurrently
+// with each other.
 type Gotagger struct {
 	Config Config
 
 	repo   *git.Repository
 	logger logr.Logger
+
+	// events logs the changes gotagger makes, such as creating and pushing
+	// tags, at V-level 0, so they can be shown without the debug output.
+	events logr.Logger
+
+	// subdir is the directory gotagger was created for, relative to the
+	// root of the repository, or "." for the root.
+	subdir string
 }
 
+// New returns a Gotagger for the git repository containing path.
+//
+// If path is a subdirectory of the repository, then it acts as an implicit
+// filter: only the go modules under path, and the module containing path,
+// are versioned. For projects without go modules, Config.Paths are relative
+// to path, and only the history of path is used by default.
 func New(path string) (*Gotagger, error) {
 	r, err := git.New(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Gotagger{
+	subdir, err := relativePath(r.Path, path)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gotagger{
 		Config: NewDefaultConfig(),
 		logger: logr.Discard(),
+		events: logr.Discard(),
 		repo:   r,
-	}, nil
+		subdir: subdir,
+	}
+	r.TagNamespace = g.tagNamespace
+	r.MergeChanges = g.mergeChanges
+	r.ChangeStats = g.changeStats
+	r.TypeAlias = g.typeAlias
+	r.Offline = g.offline
+	r.TaggerDate = g.taggerDate
+	r.ParseMessage = g.parseMessage
+
+	return g, nil
+}
+
+// tagNamespace returns the ref namespace of version tags.
+func (g *Gotagger) tagNamespace() string {
+	return g.Config.TagNamespace
+}
+
+// taggerDate returns the date that tags of the commit hash are created with,
+// as set by Config.TagDate, or the empty string for the current time.
+func (g *Gotagger) taggerDate(hash string) (string, error) {
+	var date time.Time
+	var err error
+	switch g.Config.TagDate {
+	case "":
+		return "", nil
+	case TagDateAuthor:
+		date, err = g.repo.AuthorDate(hash)
+	case TagDateCommitter:
+		date, err = g.repo.CommitDate(hash)
+	default:
+		return g.Config.TagDate, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not find the %s date of %s: %w", g.Config.TagDate, hash, err)
+	}
+
+	return date.Format(time.RFC3339), nil
+}
+
+// changeStats returns true if the lines changed by each commit are counted.
+func (g *Gotagger) changeStats() bool {
+	return g.Config.ChangelogStats
+}
+
+// mergeChanges returns true if merge commits can increment the version,
+// so they must be matched to the modules and paths they change.
+func (g *Gotagger) mergeChanges() bool {
+	return g.Config.MergeIncrement != mapper.IncrementNone
+}
+
+// relativePath returns the path of dir relative to root, resolving any
+// symlinks so that the two can be compared.
+func relativePath(root, dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		// not inside the worktree, as with a bare repository
+		return rootModulePath, nil
+	}
+
+	return rel, nil
+}
+
+// Result describes the version calculated for a single go module or path.
+type Result struct {
+	// Module is the name of the go module, if any.
+	Module string `json:"module,omitempty"`
+
+	// Alias is the alias of the module in Config.ModuleAliases, if any.
+	Alias string `json:"alias,omitempty"`
+
+	// Path is the path to the module or path filter,
+	// relative to the root of the repository.
+	Path string `json:"path"`
+
+	// Prefix is the prefix of the version tag,
+	// including the module prefix and the VersionPrefix.
+	Prefix string `json:"prefix"`
+
+	// Version is the calculated version, including Prefix.
+	Version string `json:"version"`
+
+	// Commit is the hash of the commit that was versioned, usually HEAD.
+	Commit string `json:"commit"`
+
+	// LatestTag is the tag of the latest version, if there is one.
+	LatestTag string `json:"latestTag,omitempty"`
+
+	// LatestHash is the hash of the commit tagged with the latest version.
+	LatestHash string `json:"latestHash,omitempty"`
+
+	// LatestDate is when the latest version was tagged. For lightweight tags
+	// this is the date of the tagged commit.
+	LatestDate time.Time `json:"latestDate"`
+
+	// Owners are the owners of the files changed since the latest version,
+	// as listed in the CODEOWNERS file of the repository. It is only set if
+	// Config.CodeOwners is set.
+	Owners []string `json:"owners,omitempty"`
+
+	// Trace records how the version was calculated. It is only set if
+	// Config.Trace is set.
+	Trace *ModuleTrace `json:"trace,omitempty"`
+
+	// Warnings are problems found while calculating the version that are
+	// not errors, such as commits with an unknown type when
+	// Config.StrictCommitTypes is set, tags that are not valid versions,
+	// or a shallow clone.
+	Warnings []Warning `json:"warnings,omitempty"`
+
+	// Tag is the tag of the release of Version, which never includes the
+	// commit count, nightly identifiers, or dirty worktree suffix. It is
+	// only set by TagRepo when a release is tagged.
+	Tag string `json:"tag,omitempty"`
+
+	// Tagged is true if TagRepo created Tag.
+	Tagged bool `json:"tagged"`
+
+	// Pushed is true if TagRepo pushed Tag to Config.RemoteName.
+	Pushed bool `json:"pushed"`
 }
 
 // ModuleVersions returns the current version for all go modules in the repository
-// in the order they were found by a depth-first, lexicographically sorted search.
+// in the order they were found by a depth-first, lexicographically sorted search,
+// or by module path if Config.ModuleOrder is ModuleOrderName.
 //
 // For example, in a repository with a root go.mod and a submodule foo/bar, the
 // slice returned would be: []string{"v0.1.0", "bar/v0.1.0"}
@@ -67,11 +246,382 @@ func (g *Gotagger) ModuleVersions(names ...string) ([]string, error) {
 		return nil, err
 	}
 
-	return g.versions(modules, nil)
+	return g.versions(modules, nil, releaseOptions{})
+}
+
+// ModuleVersionsBetween returns the version that the module name would be
+// released as at toRef, using its latest version as of fromRef as the base
+// version. This answers "what would the version be if I released at toRef",
+// and can be used to audit historical releases: for example, fromRef "v1.3.0"
+// and toRef "v1.4.0" recalculates v1.4.0 from the commits since v1.3.0.
+//
+// An empty fromRef defaults to toRef, and an empty toRef defaults to HEAD.
+// Modules are discovered from the tree of toRef, unless toRef is HEAD. If
+// name is empty, then the first module is used, as with Version. The worktree
+// is only considered when toRef is HEAD.
+func (g *Gotagger) ModuleVersionsBetween(name, fromRef, toRef string) (string, error) {
+	rel, err := g.releaseBetween(name, fromRef, toRef)
+	if err != nil {
+		return "", err
+	}
+
+	return rel.Version, nil
+}
+
+// ChangelogBetween renders the changes to the module name between fromTag and
+// toTag in Config.ChangelogFormat, as the section for the version of toTag.
+// This regenerates the notes of a release that was already tagged, such as
+// fromTag "v1.3.0" and toTag "v1.4.0", using the same grouping as
+// WriteChangelogs.
+//
+// The refs and name are handled as in ModuleVersionsBetween, except that an
+// empty fromTag defaults to the parent of toTag, so that the changes since the
+// previous version are rendered. If toTag is not a version tag of the module,
+// then the section is for the version the module would be released as at
+// toTag, dated now.
+func (g *Gotagger) ChangelogBetween(fromTag, toTag, name string) ([]byte, error) {
+	if fromTag == "" && toTag != "" {
+		// the previous version is the latest one before toTag
+		fromTag = toTag + "^"
+	}
+
+	rel, err := g.releaseBetween(name, fromTag, toTag)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := g.Date(rel.target)
+	if err != nil {
+		return nil, err
+	}
+	if toTag != "" && strings.HasPrefix(toTag, rel.Prefix) {
+		if _, err := semver.NewVersion(strings.TrimPrefix(toTag, rel.Prefix)); err == nil {
+			rel.Version = toTag
+			if date, err = g.repo.TagDate(toTag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	links, err := g.changelogLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := g.newChangelogRelease(rel, date, links)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Config.ChangelogFormat.Render(cl)
+}
+
+// ReleaseNotes returns the release notes embedded in the message of the
+// annotated tag: the message without its subject line, such as
+// "Release v1.4.0". The notes are empty if the tag only has a subject.
+// An error is returned if tag does not exist or is a lightweight tag.
+func (g *Gotagger) ReleaseNotes(tag string) (string, error) {
+	message, err := g.repo.TagMessage(tag)
+	if err != nil {
+		return "", err
+	}
+
+	_, notes, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(notes), nil
+}
+
+// releaseBetween returns the release of the module name at toRef, based on
+// its latest version as of fromRef.
+func (g *Gotagger) releaseBetween(name, fromRef, toRef string) (release, error) {
+	if toRef == "" {
+		toRef = head
+	}
+
+	// tags of trees and blobs cannot be versioned
+	for _, ref := range []string{fromRef, toRef} {
+		if ref == "" {
+			continue
+		}
+		if _, err := g.repo.CommitHash(ref); err != nil {
+			return release{}, err
+		}
+	}
+
+	var modules []module
+	if !g.Config.IgnoreModules {
+		var include []string
+		if name != "" {
+			include = []string{name}
+		}
+
+		m, err := g.findModulesAt(toRef, include)
+		if err != nil {
+			return release{}, err
+		}
+		if name != "" && len(m) == 0 {
+			return release{}, fmt.Errorf("%w: %s", ErrNoSubmodule, name)
+		}
+		modules = m
+	}
+
+	releases, err := g.releases(modules, nil, releaseOptions{from: fromRef, to: toRef})
+	if err != nil {
+		return release{}, err
+	}
+
+	return firstRelease(releases, modules), nil
+}
+
+// Module describes a go module found in the repository.
+type Module struct {
+	// Path is the path to the directory containing the go.mod file,
+	// relative to the root of the repository.
+	Path string
+
+	// Name is the module path declared in the go.mod file.
+	Name string
+
+	// Alias is the alias of the module in Config.ModuleAliases, if any.
+	Alias string
+
+	// Prefix is the prefix of the module's version tags, such as "bar/",
+	// not including the VersionPrefix. It is empty for the root module.
+	Prefix string
+}
+
+// Modules returns every go module in the repository, in the same order as
+// ModuleVersions. Modules excluded by Config.ExcludeModules are not returned.
+//
+// If module names are passed in, then only those modules are returned.
+func (g *Gotagger) Modules(names ...string) ([]Module, error) {
+	modules, err := g.findAllModules(names)
+	if err != nil {
+		return nil, err
+	}
+
+	mods := make([]Module, len(modules))
+	for i, mod := range modules {
+		mods[i] = Module{
+			Path:   mod.path,
+			Name:   mod.name,
+			Alias:  g.moduleAlias(mod.name),
+			Prefix: mod.prefix,
+		}
+	}
+
+	return mods, nil
+}
+
+// Results returns a Result for every go module in the repository,
+// in the same order as ModuleVersions, or for every path in Config.Paths if
+// go modules are ignored.
+//
+// If module names are passed in, then only the results for those modules are
+// returned.
+func (g *Gotagger) Results(names ...string) ([]Result, error) {
+	var (
+		modules  []module
+		warnings []Warning
+	)
+	if !g.Config.IgnoreModules {
+		m, w, err := g.findModuleWarnings(head, names)
+		if err != nil {
+			return nil, err
+		}
+		modules, warnings = m, w
+	}
+
+	releases, err := g.releases(modules, nil, releaseOptions{})
+	if err != nil {
+		return nil, err
+	}
+	addModuleWarnings(releases, warnings)
+
+	return releaseResults(releases), nil
+}
+
+// WriteChangelogs adds the changes since the latest version to the changelog
+// file in the directory of every go module in the repository, or of every
+// path in Config.Paths if go modules are ignored. The format and name of the
+// file are determined by Config.ChangelogFormat. The file is created if it
+// does not exist, and modules without changes are skipped.
+//
+// WriteChangelogs returns the paths of the files it wrote, relative to the
+// root of the repository.
+//
+// If module names are passed in, then only the changelogs for those modules
+// are written.
+func (g *Gotagger) WriteChangelogs(names ...string) ([]string, error) {
+	return g.writeChangelogs(names, false)
+}
+
+// writeChangelogs writes the changelogs of the modules names, or of every
+// module or path, as WriteChangelogs does, or their Unreleased sections if
+// unreleased is true.
+func (g *Gotagger) writeChangelogs(names []string, unreleased bool) ([]string, error) {
+	var modules []module
+	if !g.Config.IgnoreModules {
+		m, err := g.findAllModules(names)
+		if err != nil {
+			return nil, err
+		}
+		modules = m
+	}
+
+	releases, err := g.releases(modules, nil, releaseOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, rel := range releases {
+		fn := path.Join(slashPath(rel.Path), g.Config.ChangelogFormat.FileName())
+		ok, err := g.writeChangelog(fn, rel, unreleased)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			written = append(written, fn)
+		}
+	}
+
+	return written, nil
+}
+
+// writeChangelog adds the changes of rel to the changelog file fn, a
+// slash-separated path relative to the root of the repository, or replaces
+// its Unreleased section with them if unreleased is true. It returns false if
+// nothing was written, because rel has no changes, and there is no
+// Unreleased section to empty.
+func (g *Gotagger) writeChangelog(fn string, rel release, unreleased bool) (bool, error) {
+	full := filepath.Join(g.repo.Path, filepath.FromSlash(fn))
+	data, err := os.ReadFile(full)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	// an unreleased section without changes is emptied,
+	// but not created
+	if len(rel.commits) == 0 && !(unreleased && exists) {
+		g.logger.Info("no changes for changelog", "path", rel.Path)
+		return false, nil
+	}
+
+	links, err := g.changelogLinks()
+	if err != nil {
+		return false, err
+	}
+
+	now, err := g.Date(head)
+	if err != nil {
+		return false, err
+	}
+
+	g.logger.Info("writing changelog", "path", fn, "version", rel.Version, "unreleased", unreleased)
+	cl, err := g.newChangelogRelease(rel, now, links)
+	if err != nil {
+		return false, err
+	}
+
+	format := g.Config.ChangelogFormat
+	if unreleased {
+		data, err = format.UpdateUnreleased(data, cl)
+	} else {
+		data, err = format.Update(data, cl)
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not update %s: %w", fn, err)
+	}
+
+	if err := os.WriteFile(full, data, 0o644); err != nil {
+		return false, err
+	}
+	g.events.Info("wrote changelog", "path", fn, "version", rel.Version, "unreleased", unreleased)
+
+	return true, nil
+}
+
+// newChangelogRelease returns the changelog.Release for rel, linked with
+// links. The Gerrit topics of the changes are found if
+// Config.ChangelogTopics is set.
+func (g *Gotagger) newChangelogRelease(rel release, date time.Time, links changelog.Links) (changelog.Release, error) {
+	var topics map[string]string
+	if g.Config.ChangelogTopics && rel.target != "" {
+		var err error
+		if topics, err = g.repo.Topics(rel.target, rel.base); err != nil {
+			return changelog.Release{}, fmt.Errorf("could not find topics: %w", err)
+		}
+	}
+
+	changes := make([]changelog.Change, len(rel.commits))
+	for i, c := range rel.commits {
+		changes[i] = changelog.Change{
+			Type:        c.Type,
+			Scope:       c.Scope,
+			Subject:     c.Subject,
+			Breaking:    c.Breaking,
+			Hash:        c.Hash,
+			ChangeID:    c.ChangeID,
+			Topic:       topics[c.Hash],
+			PullRequest: c.PullRequest,
+		}
+		changes[i].Insertions, changes[i].Deletions = c.LineStats()
+
+		// dependency updates are notable even if they are not
+		// conventional commits, such as "Bump foo from 1.0.0 to 1.1.0"
+		if g.Config.DependencyUpdates.matches(c) {
+			changes[i].Dependency = true
+			if changes[i].Subject == "" {
+				changes[i].Subject = c.Title
+			}
+		}
+	}
+
+	return changelog.Release{
+		Name:         rel.Alias,
+		Version:      strings.TrimPrefix(rel.Version, rel.Prefix),
+		Date:         date,
+		Changes:      changes,
+		Owners:       rel.Owners,
+		Preset:       g.Config.ChangelogPreset,
+		Links:        links,
+		GroupByTopic: g.Config.ChangelogTopics,
+	}, nil
+}
+
+// changelogLinks returns the links for changelogs: Config.ChangelogLinks,
+// with the templates that are not set detected from the URL of
+// Config.RemoteName.
+func (g *Gotagger) changelogLinks() (changelog.Links, error) {
+	links := g.Config.ChangelogLinks
+	if links.Commit != "" && links.Issue != "" {
+		return links, nil
+	}
+
+	remoteURL, err := g.repo.RemoteURL(g.Config.RemoteName)
+	if err != nil {
+		return changelog.Links{}, err
+	}
+
+	detected := changelog.DetectLinks(remoteURL)
+	if links.Commit == "" {
+		links.Commit = detected.Commit
+	}
+	if links.Issue == "" {
+		links.Issue = detected.Issue
+	}
+
+	return links, nil
 }
 
+// SetLogger updates the Gotagger's logger. Changes to the repository, such as
+// created and pushed tags, are logged at V-level 0, and everything else at
+// V-level 1 or higher.
 func (g *Gotagger) SetLogger(l logr.Logger) {
-	// we only really log debug messages,
+	g.events = l.WithName("gotagger")
+
+	// everything else is a debug message,
 	// so set the default V-level to 1
 	l = l.V(1)
 	l.Info("updating logger")
@@ -87,71 +637,715 @@ func (g *Gotagger) SetLogger(l logr.Logger) {
 // If the current commit contains one or more Modules footers, then tags are
 // created for each module listed. In this case if the root module is not
 // explicitly included in a Modules footer then it will not be included.
+//
+// If the current commit contains a Release-Train footer, then tags are
+// created for every module that changed since its latest version.
+//
+// The versions of go modules are returned in the same order as
+// ModuleVersions.
 func (g *Gotagger) TagRepo() ([]string, error) {
-	// get all modules, if any, unless we're explicitly ignoring them
-	var modules []module
-	if !g.Config.IgnoreModules {
-		m, err := g.findAllModules(nil)
-		if err != nil {
+	return g.TagRepoAt(head)
+}
+
+// TagRepoAt is like TagRepo, but versions and tags the commit rev instead of
+// HEAD, such as the commit that a CI pipeline built after the branch has moved
+// on. Tags are only created for rev if it is an ancestor of HEAD, or if
+// Config.Force is set.
+func (g *Gotagger) TagRepoAt(rev string) ([]string, error) {
+	results, err := g.TagRepoResultsAt(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultVersions(results), nil
+}
+
+// TagRepoResults is like TagRepo, but returns a Result for each version,
+// including any warnings found while calculating it.
+func (g *Gotagger) TagRepoResults() ([]Result, error) {
+	return g.TagRepoResultsAt(head)
+}
+
+// TagRepoResultsAt is like TagRepoAt, but returns a Result for each version,
+// including any warnings found while calculating it.
+func (g *Gotagger) TagRepoResultsAt(rev string) ([]Result, error) {
+	if g.Config.CreateTag {
+		// fail before anything is tagged if the tags could not be pushed
+		if g.Config.PushTag {
+			if err := g.checkOnline("push tags"); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := g.checkTarget(rev); err != nil {
 			return nil, err
 		}
-		modules = m
 	}
 
-	// get the current HEAD commit
-	c, err := g.repo.Head()
+	c, releases, err := g.planRelease(rev, false)
 	if err != nil {
 		return nil, err
 	}
+	results := releaseResults(releases)
+	versions := resultVersions(results)
 
-	var commitModules []module
-	if len(modules) > 0 {
-		// there are go modules, so validate that if this is a release commit it is correct
-		commitModules, err = extractCommitModules(c, modules)
+	// nightly versions are never tagged, but the nightly tag follows them
+	if g.Config.Nightly {
+		if !g.Config.CreateTag {
+			return results, nil
+		}
+		return results, g.moveNightlyTag(c.Hash, versions)
+	}
+
+	// determine if we should create and push a tag or not
+	if (g.Config.Force || c.Type == mapper.TypeRelease) && g.Config.CreateTag {
+		// the commit count and the worktree are only part of the versions
+		// that are printed, so tag the versions that NextTags returns
+		_, tagged, err := g.planRelease(rev, true)
+		if err != nil {
+			return nil, err
+		}
+		tagResults := releaseResults(tagged)
+		versions := resultVersions(tagResults)
+
+		existing, err := g.checkRelease(c, tagResults)
+		if err != nil {
+			return nil, err
+		}
+
+		floating, err := g.floatingTags(tagResults)
+		if err != nil {
+			return nil, err
+		}
+
+		// record the release before touching any tags,
+		// so that it can be resumed if we are interrupted
+		j := &journal{
+			Commit:        c.Hash,
+			Tags:          versions,
+			Floating:      floating,
+			ForceFloating: g.Config.ForcePushFloatingTags,
+			Push:          g.Config.PushTag,
+			Remote:        g.Config.RemoteName,
+		}
+		if err := g.writeJournal(j); err != nil {
+			return nil, fmt.Errorf("could not write release journal: %w", err)
+		}
+
+		// create tag
+		tags := make([]string, 0, len(versions))
+		for i, ver := range versions {
+			if existing[ver] {
+				g.logger.Info("tag already exists", "tag", ver, "commit", c.Hash)
+				continue
+			}
+			if err := g.repo.CreateTag(c.Hash, ver, tagMessage(ver), false); err != nil {
+				// clean up tags we already created
+				return nil, g.abortRelease(tags, fmt.Errorf("could not create tag %s: %w", ver, err))
+			}
+			g.events.Info("created tag", "tag", ver, "commit", c.Hash)
+			tags = append(tags, ver)
+			if res := findResult(results, tagResults[i]); res != nil {
+				res.Tag, res.Tagged = ver, true
+			}
+		}
+
+		previous, err := g.moveFloatingTags(c.Hash, floating)
 		if err != nil {
+			return nil, g.abortRelease(tags, err)
+		}
+
+		// push tags
+		if g.Config.PushTag {
+			if err := g.pushTags(j); err != nil {
+				// currently pushes are not atomic so some of the tags may be
+				// pushed while others fail. we delete all of the local tags to
+				// be safe
+				return nil, g.abortRelease(tags, g.restoreFloatingTags(previous, err))
+			}
+			// existing tags of the release are pushed too
+			for _, tagged := range tagResults {
+				if res := findResult(results, tagged); res != nil {
+					res.Tag, res.Pushed = tagged.Version, true
+				}
+			}
+		}
+
+		if err := g.removeJournal(); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// findResult returns the result of results that is for the same module or
+// path as res, or nil if there is none.
+func findResult(results []Result, res Result) *Result {
+	for i := range results {
+		if results[i].Module == res.Module && slashPath(results[i].Path) == slashPath(res.Path) {
+			return &results[i]
+		}
+	}
+
+	return nil
+}
+
+// PlannedTag is a tag that TagRepo would create, and what would be published
+// with it.
+type PlannedTag struct {
+	// Name is the name of the tag, including any module prefix.
+	Name string `json:"name"`
+
+	// Message is the message of the annotated tag.
+	Message string `json:"message"`
+
+	// Path is the path to the module or path filter,
+	// relative to the root of the repository.
+	Path string `json:"path"`
+
+	// Changelog is the section for the version in the changelog,
+	// rendered in Config.ChangelogFormat. It is empty if there are no
+	// changes since the latest version.
+	Changelog []byte `json:"changelog,omitempty"`
+
+	// Floating are the floating tags, such as v1, that would be moved to
+	// this tag, as set by Config.FloatingTags.
+	Floating []string `json:"floating,omitempty"`
+
+	// Owners are the owners of the files changed since the latest version,
+	// if Config.CodeOwners is set.
+	Owners []string `json:"owners,omitempty"`
+}
+
+// MarshalJSON returns the JSON encoding of t, with its changelog as text.
+func (t PlannedTag) MarshalJSON() ([]byte, error) {
+	type plain PlannedTag
+	return json.Marshal(struct {
+		plain
+		Changelog string `json:"changelog,omitempty"`
+	}{plain(t), string(t.Changelog)})
+}
+
+// UnmarshalJSON decodes a PlannedTag encoded by MarshalJSON.
+func (t *PlannedTag) UnmarshalJSON(data []byte) error {
+	type plain PlannedTag
+	var v struct {
+		*plain
+		Changelog string `json:"changelog,omitempty"`
+	}
+	v.plain = (*plain)(t)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.Changelog = nil
+	if v.Changelog != "" {
+		t.Changelog = []byte(v.Changelog)
+	}
+
+	return nil
+}
+
+// DryRun returns the tags that TagRepo would create for HEAD, whether or not
+// Config.CreateTag is set, along with their messages and changelog sections,
+// without changing the repository. No tags are returned unless HEAD is a
+// release commit or Config.Force is set, and none are returned if
+// Config.Nightly is set.
+//
+// The same checks are made as by TagRepo before it creates tags, such as
+// Config.ReleaseBranches and tag collisions, and any failure is returned.
+func (g *Gotagger) DryRun() ([]PlannedTag, error) {
+	return g.DryRunAt(head)
+}
+
+// DryRunAt is like DryRun, but returns the tags that TagRepoAt would create
+// for the commit rev.
+func (g *Gotagger) DryRunAt(rev string) ([]PlannedTag, error) {
+	if err := g.checkTarget(rev); err != nil {
+		return nil, err
+	}
+
+	c, releases, err := g.planRelease(rev, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.Config.Nightly {
+		g.logger.Info("nightly versions are not tagged, no tags would be created")
+		return nil, nil
+	}
+
+	if !g.Config.Force && c.Type != mapper.TypeRelease {
+		g.logger.Info("not a release commit, no tags would be created")
+		return nil, nil
+	}
+
+	results := releaseResults(releases)
+	if _, err := g.checkRelease(c, results); err != nil {
+		return nil, err
+	}
+
+	floating, err := g.floatingTags(results)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := g.changelogLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := g.Date(c.Hash)
+	if err != nil {
+		return nil, err
+	}
+	planned := make([]PlannedTag, len(releases))
+	for i, rel := range releases {
+		planned[i] = PlannedTag{
+			Name:    rel.Version,
+			Message: tagMessage(rel.Version),
+			Path:    rel.Path,
+			Owners:  rel.Owners,
+		}
+		for _, tag := range floating {
+			if tag.Tag == rel.Version {
+				planned[i].Floating = append(planned[i].Floating, tag.Name)
+			}
+		}
+
+		if len(rel.commits) > 0 {
+			cl, err := g.newChangelogRelease(rel, now, links)
+			if err != nil {
+				return nil, err
+			}
+			if planned[i].Changelog, err = g.Config.ChangelogFormat.Render(cl); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return planned, nil
+}
+
+// checkRelease returns an error if the results of the release commit c
+// should not be tagged. Otherwise it returns the tags that already exist at c
+// and should not be created, as allowed by Config.ExistingTags.
+func (g *Gotagger) checkRelease(c git.Commit, results []Result) (map[string]bool, error) {
+	// Config.VersionPrefix may be set without ParseJSON
+	if prefix, err := NormalizeVersionPrefix(g.Config.VersionPrefix); err != nil {
+		return nil, err
+	} else if prefix != g.Config.VersionPrefix {
+		return nil, &PrefixError{Prefix: g.Config.VersionPrefix, Reason: fmt.Sprintf("use %q instead", prefix)}
+	}
+
+	if j, err := g.readJournal(); err != nil {
+		return nil, err
+	} else if j != nil {
+		return nil, ErrInterruptedRelease
+	}
+
+	if len(g.Config.ReleaseBranches) > 0 {
+		if err := g.checkReleaseBranch(); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.Config.CheckUpstream {
+		if err := g.checkUpstream(); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.Config.RequirePromote {
+		if err := checkPromoted(results); err != nil {
 			return nil, err
 		}
+	}
+
+	if g.Config.ModuleChangelogs && c.Type == mapper.TypeRelease {
+		if err := checkChangelogs(c, results, g.Config.ChangelogFormat.FileName()); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.checkTagCollisions(resultVersions(results), c.Hash)
+}
+
+// checkPromoted returns an error if breaking changes move any of results from
+// a 0.x.y version to 1.0.0 without a promotion.
+func checkPromoted(results []Result) error {
+	for _, res := range results {
+		for _, w := range res.Warnings {
+			if w.Code == WarningUnpromotedStable {
+				return fmt.Errorf("refusing to tag: %s", w.Message)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tagMessage returns the message of the annotated tag for a release.
+func tagMessage(tag string) string {
+	return "Release " + tag
+}
+
+// NextTags returns the tags that TagRepo would create for HEAD, including
+// module prefixes, without creating them. Unlike the versions returned by
+// TagRepo, they never include the commit count, nightly identifiers, or dirty
+// worktree suffix, since those are only printed and are never tagged.
+func (g *Gotagger) NextTags() ([]string, error) {
+	_, releases, err := g.planRelease(head, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultVersions(releaseResults(releases)), nil
+}
+
+// planRelease returns the commit rev and the releases that TagRepoAt tags.
+// If tagsOnly is true, then the versions only include what is tagged.
+func (g *Gotagger) planRelease(rev string, tagsOnly bool) (git.Commit, []release, error) {
+	if err := g.checkCommits(); err != nil {
+		return git.Commit{}, nil, err
+	}
+
+	// get all modules, if any, unless we're explicitly ignoring them
+	var (
+		modules  []module
+		warnings []Warning
+	)
+	if !g.Config.IgnoreModules {
+		m, w, err := g.findModuleWarnings(rev, nil)
+		if err != nil {
+			return git.Commit{}, nil, err
+		}
+		modules, warnings = m, w
+	}
+
+	c, err := g.repo.CommitAt(rev)
+	if err != nil {
+		return git.Commit{}, nil, err
+	}
+
+	opts, err := g.extractReleaseOptions(c)
+	if err != nil {
+		return git.Commit{}, nil, err
+	}
+	opts.to = rev
+	opts.tagsOnly = tagsOnly
+
+	commitModules, err := g.releaseModules(c, modules, &opts, true)
+	if err != nil {
+		return git.Commit{}, nil, err
+	}
+
+	releases, err := g.releases(modules, commitModules, opts)
+	if err != nil {
+		return git.Commit{}, nil, err
+	}
+	addModuleWarnings(releases, warnings)
+
+	// nightly versions are never tagged
+	if (g.Config.Force || c.Type == mapper.TypeRelease) && !g.Config.Nightly {
+		if releases, err = g.applyUnchangedReleases(c, releases); err != nil {
+			return git.Commit{}, nil, err
+		}
+	}
+
+	return c, releases, nil
+}
+
+// applyUnchangedReleases applies Config.UnchangedReleases to the releases of the
+// commit c whose version is their latest version, because none of their
+// commits increment it. Releases that are already tagged on c are left to the
+// tag collision check.
+func (g *Gotagger) applyUnchangedReleases(c git.Commit, releases []release) ([]release, error) {
+	kept := releases[:0]
+	for _, rel := range releases {
+		if rel.LatestTag == "" || rel.LatestHash == c.Hash {
+			kept = append(kept, rel)
+			continue
+		}
 
-		if err := g.validateCommit(c, modules, commitModules); err != nil {
+		latest, err := semver.NewVersion(strings.TrimPrefix(rel.LatestTag, rel.Prefix))
+		if err != nil {
+			return nil, err
+		}
+		version, err := semver.NewVersion(strings.TrimPrefix(rel.Version, rel.Prefix))
+		if err != nil {
 			return nil, err
 		}
+
+		// suffixes such as the commit count are not an increment
+		if latest.Major() != version.Major() || latest.Minor() != version.Minor() || latest.Patch() != version.Patch() {
+			kept = append(kept, rel)
+			continue
+		}
+
+		name := rel.Module
+		if name == "" {
+			name = fmt.Sprintf("path %q", rel.Path)
+		}
+
+		switch g.Config.UnchangedReleases {
+		case UnchangedReleasesSkip:
+			g.logger.Info("skipping unchanged release", "module", rel.Module, "path", rel.Path, "tag", rel.LatestTag)
+		case UnchangedReleasesPatch:
+			g.logger.Info("incrementing patch version of unchanged release", "module", rel.Module, "path", rel.Path, "tag", rel.LatestTag)
+			rel.Version = rel.Prefix + latest.IncPatch().String()
+			kept = append(kept, rel)
+		default:
+			return nil, fmt.Errorf("refusing to tag: no commits increment the version of %s since %s", name, rel.LatestTag)
+		}
+	}
+
+	return kept, nil
+}
+
+// checkTarget returns an error if the commit rev is not HEAD or an ancestor
+// of it, unless Config.Force is set, so that a commit from another branch is
+// not tagged by mistake.
+func (g *Gotagger) checkTarget(rev string) error {
+	if rev == head || g.Config.Force {
+		return nil
+	}
+
+	if err := g.checkCommits(); err != nil {
+		return err
+	}
+
+	if ok, err := g.repo.IsAncestor(rev, head); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("refusing to tag: %s is not an ancestor of HEAD", rev)
+	}
+
+	return nil
+}
+
+// checkCommits returns ErrEmptyRepository if HEAD has no commits.
+func (g *Gotagger) checkCommits() error {
+	if g.repo.IsUnborn() {
+		return ErrEmptyRepository
+	}
+
+	return nil
+}
+
+// abortRelease deletes tags and the release journal after err stopped a
+// release, and returns err along with any cleanup errors.
+func (g *Gotagger) abortRelease(tags []string, err error) error {
+	if terr := g.repo.DeleteTags(tags); terr != nil {
+		err = fmt.Errorf("%w\n%s", err, terr)
+	} else if len(tags) > 0 {
+		g.events.Info("deleted tags after failed release", "tags", tags)
+	}
+	if jerr := g.removeJournal(); jerr != nil {
+		err = fmt.Errorf("%w\n%s", err, jerr)
+	}
+	return err
+}
+
+// pushTags pushes the tags and floating tags of the release j to its remote
+// using the configured push options.
+func (g *Gotagger) pushTags(j *journal) error {
+	if err := g.checkOnline("push tags"); err != nil {
+		return err
+	}
+
+	opts := git.PushOptions{
+		FollowTags: g.Config.PushOptions.FollowTags,
+		Options:    g.Config.PushOptions.Options,
+		Token:      g.Config.PushOptions.Token,
+		Username:   g.Config.PushOptions.Username,
+	}
+	if opts.Username == "" {
+		opts.Username = defaultPushUsername
+	}
+
+	tags := j.Tags
+	if j.ForceFloating {
+		opts.ForceTags = floatingTagNames(j.Floating)
+	} else {
+		tags = append(tags[:len(tags):len(tags)], floatingTagNames(j.Floating)...)
+	}
+
+	if err := g.repo.PushTagsWithOptions(tags, j.Remote, opts); err != nil {
+		return err
+	}
+	g.events.Info("pushed tags", "tags", append(tags[:len(tags):len(tags)], opts.ForceTags...), "remote", j.Remote)
+
+	return nil
+}
+
+// OnReleaseBranch returns true if HEAD is on a branch that matches one of
+// Config.ReleaseBranches, or if no release branches are configured.
+func (g *Gotagger) OnReleaseBranch() (bool, error) {
+	if len(g.Config.ReleaseBranches) == 0 {
+		return true, nil
+	}
+
+	branch, err := g.repo.CurrentBranch()
+	if err != nil || branch == "" {
+		return false, err
+	}
+
+	return g.isReleaseBranch(branch)
+}
+
+// DefaultBranch returns Config.DefaultBranch, or else the default branch of
+// the remote Config.RemoteName. It is empty if the remote does not have a
+// known default branch.
+func (g *Gotagger) DefaultBranch() (string, error) {
+	if g.Config.DefaultBranch != "" {
+		return g.Config.DefaultBranch, nil
+	}
+
+	branch, err := g.repo.DefaultBranch(g.Config.RemoteName)
+	if err != nil {
+		return "", err
+	}
+	g.logger.Info("detected default branch", "remote", g.Config.RemoteName, "branch", branch)
+
+	return branch, nil
+}
+
+// checkReleaseBranch returns an error if HEAD is not on a branch that matches
+// one of the configured release branch patterns.
+func (g *Gotagger) checkReleaseBranch() error {
+	branch, err := g.repo.CurrentBranch()
+	if err != nil {
+		return err
+	}
+
+	patterns := strings.Join(g.Config.ReleaseBranches, ", ")
+	if branch == "" {
+		return fmt.Errorf("refusing to tag: HEAD is not on a branch, releases are only allowed from: %s", patterns)
+	}
+
+	if ok, err := g.isReleaseBranch(branch); err != nil || ok {
+		return err
+	}
+
+	return fmt.Errorf("refusing to tag: branch %s is not a release branch, releases are only allowed from: %s", branch, patterns)
+}
+
+// isReleaseBranch returns true if branch matches one of the configured
+// release branch patterns.
+func (g *Gotagger) isReleaseBranch(branch string) (bool, error) {
+	for _, pattern := range g.Config.ReleaseBranches {
+		if ok, err := path.Match(pattern, branch); err != nil {
+			return false, fmt.Errorf("invalid release branch pattern %q: %w", pattern, err)
+		} else if ok {
+			g.logger.Info("branch matches release branch pattern", "branch", branch, "pattern", pattern)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FetchTags fetches the version tags of the remote Config.RemoteName, so that
+// versions are calculated from every release, such as in a CI job that checks
+// out a single commit.
+func (g *Gotagger) FetchTags() error {
+	if err := g.checkOnline("fetch tags"); err != nil {
+		return err
+	}
+
+	return g.repo.FetchTags(g.Config.RemoteName)
+}
+
+// checkChangelogs returns an error listing the changelog named name of every
+// result that was not updated by commit c.
+func checkChangelogs(c git.Commit, results []Result, name string) error {
+	changed := map[string]struct{}{}
+	for _, change := range c.Changes {
+		changed[change.SourceName] = struct{}{}
+		changed[change.DestName] = struct{}{}
+	}
+
+	var missing []string
+	for _, res := range results {
+		fn := path.Join(slashPath(res.Path), name)
+		if _, ok := changed[fn]; !ok {
+			missing = append(missing, fn)
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.New("release commit must update the changelog of every released module:\n" + strings.Join(missing, "\n"))
+	}
+
+	return nil
+}
+
+// checkTagCollisions returns an error listing every planned tag that is
+// planned more than once, or that collides with an existing ref, along with
+// the commit the ref points to. If Config.ExistingTags is ExistingTagsSkip,
+// then tags that already exist at the commit hash are not collisions, and are
+// returned instead.
+func (g *Gotagger) checkTagCollisions(tags []string, hash string) (map[string]bool, error) {
+	var conflicts []string
+
+	seen := map[string]struct{}{}
+	tagRefs := map[string]string{}
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			conflicts = append(conflicts, "tag "+tag+" would be created more than once")
+		}
+		seen[tag] = struct{}{}
+		tagRefs[g.repo.TagRef(tag)] = tag
 	}
 
-	versions, err := g.versions(modules, commitModules)
+	refs, err := g.repo.FindRefs(tags)
 	if err != nil {
 		return nil, err
 	}
 
-	// determine if we should create and push a tag or not
-	if (g.Config.Force || c.Type == mapper.TypeRelease) && g.Config.CreateTag {
-		// create tag
-		tags := make([]string, 0, len(versions))
-		for _, ver := range versions {
-			if err := g.repo.CreateTag(c.Hash, ver, "", false); err != nil {
-				// clean up tags we already created
-				if terr := g.repo.DeleteTags(tags); terr != nil {
-					err = fmt.Errorf("%w\n%s", err, terr)
-				}
-				return nil, err
-			}
-			tags = append(tags, ver)
+	existing := map[string]bool{}
+	for _, ref := range refs {
+		commit, err := g.repo.CommitHash(ref)
+		if err != nil {
+			conflicts = append(conflicts, ref+" already exists")
+			continue
 		}
 
-		// push tags
-		if g.Config.PushTag {
-			if err := g.repo.PushTags(tags, g.Config.RemoteName); err != nil {
-				// currently pushes are not atomic so some of the tags may be
-				// pushed while others fail. we delete all of the local tags to
-				// be safe
-				if terr := g.repo.DeleteTags(tags); terr != nil {
-					err = fmt.Errorf("%w\n%s", err, terr)
-				}
-				return nil, err
-			}
+		if tag, ok := tagRefs[ref]; ok && commit == hash && g.Config.ExistingTags == ExistingTagsSkip {
+			existing[tag] = true
+			continue
 		}
+
+		conflicts = append(conflicts, fmt.Sprintf("%s already exists at commit %s", ref, shortHash(commit)))
+	}
+
+	if len(conflicts) > 0 {
+		return nil, errors.New("tag collisions found:\n" + strings.Join(conflicts, "\n"))
 	}
 
-	return versions, nil
+	return existing, nil
+}
+
+// checkUpstream returns an error if HEAD is not the same commit as its
+// upstream branch.
+func (g *Gotagger) checkUpstream() error {
+	upstream, ahead, behind, err := g.repo.Upstream()
+	if err != nil {
+		return fmt.Errorf("could not compare HEAD with its upstream branch: %w", err)
+	}
+
+	g.logger.Info("compared HEAD with upstream", "upstream", upstream, "ahead", ahead, "behind", behind)
+	switch {
+	case ahead > 0 && behind > 0:
+		return fmt.Errorf("refusing to tag: HEAD has diverged from %s by %d and %d commits", upstream, ahead, behind)
+	case behind > 0:
+		return fmt.Errorf("refusing to tag: HEAD is behind %s by %d commits", upstream, behind)
+	case ahead > 0:
+		return fmt.Errorf("refusing to tag: HEAD is ahead of %s by %d commits", upstream, ahead)
+	}
+
+	return nil
 }
 
 // Version returns the current version for the repository.
@@ -171,21 +1365,52 @@ func (g *Gotagger) Version() (string, error) {
 		modules = m
 	}
 
-	versions, err := g.versions(modules, nil)
+	releases, err := g.releases(modules, nil, releaseOptions{})
 	if err != nil {
 		return "", err
 	}
 
 	// only return the first version
-	return versions[0], nil
+	return firstRelease(releases, modules).Version, nil
 }
 
 func (g *Gotagger) findAllModules(include []string) (modules []module, err error) {
-	g.logger.Info("finding modules")
+	return g.findModulesAt(head, include)
+}
+
+// findModulesAt returns the modules in the tree of rev. Modules are read from
+// the worktree if rev is HEAD, unless Config.CommittedModules is set.
+func (g *Gotagger) findModulesAt(rev string, include []string) ([]module, error) {
+	modules, err := g.walkModules(rev, include, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.subdirModules(modules, include), nil
+}
+
+// subdirModules returns the modules that gotagger was created for. When
+// created for a subdirectory, only the modules under it and the module that
+// contains it are versioned, unless modules are explicitly included.
+func (g *Gotagger) subdirModules(modules []module, include []string) []module {
+	if len(include) > 0 {
+		return modules
+	}
+
+	return filterSubdirModules(modules, g.subdirOrRoot())
+}
+
+// walkModules finds the modules in the tree of rev, like findModulesAt, but
+// does not filter them by the directory gotagger was created for. If
+// skipped is not nil, then it is called with the slash-separated directory of
+// every go.mod that is skipped because it does not declare a module path.
+func (g *Gotagger) walkModules(rev string, include []string, skipped func(dir string)) (modules []module, err error) {
+	g.logger.Info("finding modules", "rev", rev)
 
 	// either return all modules, or only explicitly included modules
 	modinclude := map[string]struct{}{}
 	for _, name := range include {
+		name = g.moduleName(name)
 		g.logger.Info("explicitly including module", "module", name)
 		modinclude[name] = struct{}{}
 	}
@@ -199,8 +1424,42 @@ func (g *Gotagger) findAllModules(include []string) (modules []module, err error
 		pathexclude[i] = normalizePath(name)
 	}
 
+	fsys := g.Config.FS
+	resolve := func(name string) (string, error) { return resolvePath(fsys, name) }
+	if fsys == nil {
+		if g.Config.CommittedModules || rev != head {
+			// the worktree of an empty repository may have modules,
+			// but there is no tree to read them from
+			if rev == head {
+				if err := g.checkCommits(); err != nil {
+					return nil, err
+				}
+			}
+			if fsys, err = g.repo.TreeFS(rev); err != nil {
+				return nil, err
+			}
+			defer g.repo.BeginBatch()()
+		} else {
+			fsys = os.DirFS(g.repo.Path)
+			resolve = func(name string) (string, error) {
+				return filepath.EvalSymlinks(filepath.Join(g.repo.Path, filepath.FromSlash(name)))
+			}
+		}
+	}
+
+	// paths in the ignore file never contain modules
+	patterns, err := readIgnoreFile(fsys)
+	if err != nil {
+		return nil, err
+	}
+	ignored := newFileMatcher(patterns)
+
+	// resolved paths of the directories already walked when following symlinks
+	visited := map[string]bool{}
+
 	// walk root and find all modules
-	err = filepath.Walk(g.repo.Path, func(pth string, info os.FileInfo, err error) error {
+	var walk fs.WalkDirFunc
+	walk = func(pth string, d fs.DirEntry, err error) error {
 		// bail on errors
 		if err != nil {
 			return err
@@ -209,33 +1468,67 @@ func (g *Gotagger) findAllModules(include []string) (modules []module, err error
 		logger := g.logger.WithValues("path", pth)
 
 		// ignore directories
-		if info.IsDir() {
+		if d.IsDir() {
 			// don't recurse into directories that start with '.', '_', or are named 'testdata'
-			dirname := info.Name()
+			dirname := d.Name()
 			if dirname != "." && (strings.HasPrefix(dirname, ".") || strings.HasPrefix(dirname, "_") || dirname == "testdata") {
 				logger.Info("not recursing into directory: ignored by default")
 				return filepath.SkipDir
 			}
 
+			if ignored.matchPath(pth, true) {
+				logger.Info("not recursing into directory: ignored by " + ignoreFile)
+				return filepath.SkipDir
+			}
+
+			if g.Config.FollowSymlinks {
+				// don't walk the same directory twice,
+				// which would loop forever on a symlink cycle
+				resolved, err := resolve(pth)
+				if err != nil {
+					return err
+				}
+				if visited[resolved] {
+					logger.Info("not recursing into directory: already visited")
+					return filepath.SkipDir
+				}
+				visited[resolved] = true
+			}
+
 			return nil
 		}
 
-		// add the directory leading up to any valid go.mod
-		relPath, err := filepath.Rel(g.repo.Path, pth)
-		if err != nil {
-			return err
+		// symlinked directories are only walked if FollowSymlinks is set
+		if d.Type()&fs.ModeSymlink != 0 && g.Config.FollowSymlinks {
+			info, err := fs.Stat(fsys, pth)
+			if err != nil {
+				// ignore broken symlinks
+				logger.Info("ignoring broken symlink", "error", err)
+				return nil
+			}
+
+			if info.IsDir() {
+				logger.Info("following symlink")
+				return fs.WalkDir(fsys, pth, walk)
+			}
 		}
 
-		if strings.HasSuffix(relPath, filepathSep+goMod) || relPath == goMod {
+		// add the directory leading up to any valid go.mod
+		if path.Base(pth) == goMod {
+			if ignored.match(pth) {
+				logger.Info("ignoring go module: ignored by " + ignoreFile)
+				return nil
+			}
+
 			logger.Info("found go module")
-			data, err := os.ReadFile(pth)
+			data, err := fs.ReadFile(fsys, pth)
 			if err != nil {
 				return err
 			}
 
 			// ignore go.mods that don't parse a module path
 			if modName := modfile.ModulePath(data); modName != "" {
-				modPath := filepath.Dir(relPath)
+				modPath := filepath.FromSlash(path.Dir(pth))
 				logger := logger.WithValues("module", modName, "modulePath", modPath)
 
 				// ignore module if it is not an included one
@@ -251,38 +1544,23 @@ func (g *Gotagger) findAllModules(include []string) (modules []module, err error
 					return nil
 				}
 
-				// normalize module path to ease comparisons
-				normPath := normalizePath(modPath)
-				for _, exclude := range pathexclude {
-					// see if an exclude is a prefix of normPath
-					if strings.HasPrefix(normPath, exclude) {
-						logger.Info("ignoring excluded module path")
-						return nil
-					}
-				}
-
-				// derive modPrefix from modPath
-				modPrefix := filepath.ToSlash(modPath)
-				if modPrefix == rootModulePath {
-					modPrefix = ""
-				} else {
-					// determine the major version prefix for this module
-					major := strings.TrimPrefix(versionRegex.FindString(modName), goModSep)
-
-					// strip trailing major version directory from prefix
-					modPrefix = strings.TrimSuffix(modPrefix, major)
-					if modPrefix != "" && !strings.HasSuffix(modPrefix, goModSep) {
-						modPrefix += goModSep
-					}
+				if excludedPath(modPath, pathexclude) {
+					logger.Info("ignoring excluded module path")
+					return nil
 				}
 
+				modPrefix := modulePrefix(modPath, modName)
 				logger.Info("adding moddule", "modulePrefix", modPrefix)
 				modules = append(modules, module{modPath, modName, modPrefix})
+			} else if skipped != nil && !excludedPath(path.Dir(pth), pathexclude) {
+				logger.Info("skipping go.mod without a module path")
+				skipped(path.Dir(pth))
 			}
 		}
 
 		return nil
-	})
+	}
+	err = fs.WalkDir(fsys, ".", walk)
 
 	if len(modules) > 0 && len(g.Config.Paths) > 0 {
 		err = errors.New("cannot use path filtering with go modules")
@@ -292,11 +1570,128 @@ func (g *Gotagger) findAllModules(include []string) (modules []module, err error
 	return
 }
 
+// subdirOrRoot returns the directory gotagger was created for, relative to the
+// root of the repository.
+func (g *Gotagger) subdirOrRoot() string {
+	if g.subdir == "" {
+		return rootModulePath
+	}
+	return g.subdir
+}
+
+// paths returns Config.Paths relative to the root of the repository,
+// or the directory gotagger was created for if Config.Paths is empty.
+func (g *Gotagger) paths() []string {
+	if len(g.Config.Paths) == 0 {
+		return []string{g.subdirOrRoot()}
+	}
+
+	paths := make([]string, len(g.Config.Paths))
+	for i, p := range g.Config.Paths {
+		paths[i] = filepath.Join(g.subdirOrRoot(), p)
+	}
+
+	return paths
+}
+
+// filterSubdirModules returns the modules whose directory is dir or is under
+// dir, and the innermost module whose directory contains dir.
+func filterSubdirModules(modules []module, dir string) []module {
+	if dir == rootModulePath {
+		return modules
+	}
+
+	var (
+		filtered  []module
+		enclosing *module
+	)
+	for i, mod := range modules {
+		switch {
+		case inDir(mod.path, dir):
+			filtered = append(filtered, mod)
+		case inDir(dir, mod.path):
+			if enclosing == nil || len(mod.path) > len(enclosing.path) || enclosing.path == rootModulePath {
+				enclosing = &modules[i]
+			}
+		}
+	}
+
+	// the enclosing module is only needed if no module is rooted at dir
+	if enclosing != nil && (len(filtered) == 0 || slashPath(filtered[0].path) != slashPath(dir)) {
+		filtered = append([]module{*enclosing}, filtered...)
+	}
+
+	return filtered
+}
+
+// incrementVersion returns the next version after v based on commits,
+// including the commit count and dirty worktree suffix if configured.
 func (g *Gotagger) incrementVersion(v *semver.Version, commits []git.Commit) (string, error) {
+	return g.incrementVersionAt(v, commits, g.Config.CommitTypeTable, releaseOptions{})
+}
+
+// incrementVersionAt is incrementVersion for the commit type table and the
+// revisions and options in opts. A promoted 0.x.y version is 1.0.0 before
+// any identifiers or suffix are added.
+func (g *Gotagger) incrementVersionAt(v *semver.Version, commits []git.Commit, table mapper.Table, opts releaseOptions) (string, error) {
+	// the worktree differs between checkouts of the same commit
+	worktree := opts.worktree() && !g.Config.Reproducible
+	version, err := g.nextVersion(v, commits, table, opts.minimum, worktree)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.promote {
+		if promoted, ok := promoteVersion(v); ok {
+			g.logger.Info("promoting version", "latest", v.String())
+			version = promoted
+		}
+	}
+
+	if g.Config.Nightly && !opts.tagsOnly && len(commits) > 0 {
+		g.logger.Info("adding nightly identifiers")
+		now, err := g.Date(opts.target())
+		if err != nil {
+			return "", err
+		}
+		version = nightlyVersion(version, now)
+	}
+
+	if g.Config.CommitsSince && !opts.tagsOnly && len(commits) > 0 {
+		g.logger.Info("adding commits since latest version", "commits", len(commits))
+		sep := "-"
+		if strings.Contains(version, "-") {
+			// already a pre-release version, so add another identifier
+			sep = "."
+		}
+		version += sep + "r" + strconv.Itoa(len(commits))
+	}
+
+	if g.Config.DirtyWorktreeSuffix == "" || !worktree {
+		return version, nil
+	}
+
+	isDirty, err := g.repo.IsDirty()
+	if err != nil {
+		return "", err
+	}
+
+	if isDirty {
+		g.logger.Info("adding suffix due to dirty worktree", "suffix", g.Config.DirtyWorktreeSuffix)
+		version += g.Config.DirtyWorktreeSuffix
+	}
+
+	return version, nil
+}
 
+func (g *Gotagger) nextVersion(v *semver.Version, commits []git.Commit, table mapper.Table, minimum mapper.Increment, worktree bool) (string, error) {
 	// If this is the latest tagged commit, then return
 	if len(commits) > 0 {
-		change := g.parseCommits(commits, v)
+		change := g.parseCommits(commits, v, table)
+		if change < minimum {
+			g.logger.Info("raising increment to the minimum of the release commit", "increment", change, "minimum", minimum)
+			change = minimum
+		}
 		switch change {
 		case mapper.IncrementMajor:
 			g.logger.Info("incrementing major version")
@@ -311,7 +1706,7 @@ func (g *Gotagger) incrementVersion(v *semver.Version, commits []git.Commit) (st
 			g.logger.Info("not incrementing version")
 			return v.String(), nil
 		}
-	} else {
+	} else if worktree {
 		isDirty, err := g.repo.IsDirty()
 		if err != nil {
 			return "", err
@@ -328,42 +1723,66 @@ func (g *Gotagger) incrementVersion(v *semver.Version, commits []git.Commit) (st
 			return v.String(), nil
 		}
 	}
+
+	return v.String(), nil
 }
 
-func (g *Gotagger) latest(tags []string, prefix string) (latest *semver.Version, hash string, err error) {
+// latest returns the latest version of the tags with prefix, the hash of the
+// commit tagged with that version, and warnings about the tags.
+func (g *Gotagger) latest(tags []string, prefix string) (*semver.Version, string, []Warning, error) {
 	logger := g.logger.WithValues("prefix", prefix)
 	logger.Info("finding latest tag")
 
-	latest = &semver.Version{}
+	var candidates []versionTag
+	var warnings []Warning
 	for _, tag := range tags {
 		tagName := strings.TrimPrefix(tag, prefix)
-		if tver, err := semver.NewVersion(tagName); err == nil && latest.LessThan(tver) {
-			g.logger.Info("found newer tag", "tag", tver)
-			hash, err = g.repo.RevParse(tag + "^{commit}")
-			if err != nil {
-				return nil, "", err
-			}
-			latest = tver
+		if tver, err := semver.NewVersion(tagName); err == nil {
+			candidates = append(candidates, versionTag{name: tag, version: tver})
+		} else if looksLikeVersion(tagName) {
+			warnings = append(warnings, invalidTagWarning(tag))
 		}
 	}
 
-	return
+	latest, err := g.selectLatest(candidates)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	warnings = append(warnings, g.unverifiedTags(candidates, latest)...)
+
+	if latest == nil {
+		return &semver.Version{}, "", warnings, nil
+	}
+
+	hash, err := g.repo.CommitHash(g.repo.TagRef(latest.name))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid version tag %s: %w", latest.name, err)
+	}
+
+	logger.Info("found latest tag", "tag", latest.name, "commit", hash)
+	others, err := g.otherTags(latest, candidates, hash)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	warnings = append(warnings, ignoredTagWarnings(latest, others)...)
+
+	return latest.version, hash, warnings, nil
 }
 
-// latestModule returns the latest version of m and the hash of the commit
-// tagged with that version.
-func (g *Gotagger) latestModule(tags []string, m module) (*semver.Version, string, error) {
+// latestModule returns the latest version of m, the hash of the commit
+// tagged with that version, and warnings about the tags of m.
+func (g *Gotagger) latestModule(tags []string, m module) (*semver.Version, string, []Warning, error) {
 	logger := g.logger.WithValues("module", m.name, "module_prefix", m.prefix, "module_path", m.path)
 	logger.Info("finding latest tag for module")
 
-	majorVersion := strings.TrimPrefix(versionRegex.FindString(m.name), goModSep)
+	majorVersion := majorVersion(m.name)
 	if majorVersion == "" {
 		majorVersion = "v0"
 	}
 
 	moduleVersion, err := semver.NewVersion(majorVersion + ".0.0")
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
 	_maximumVersion := moduleVersion.IncMajor()
@@ -373,77 +1792,325 @@ func (g *Gotagger) latestModule(tags []string, m module) (*semver.Version, strin
 	maximumVersion := &_maximumVersion
 	logger.Info("ignoring modules greater than " + g.Config.VersionPrefix + maximumVersion.String())
 
-	var latestVersion *semver.Version
-	var latestTag string
+	var candidates []versionTag
+	var warnings []Warning
 	for _, tag := range tags {
 		// strip the module prefix from the tag so we can parse it as a semver
 		tagName := strings.TrimPrefix(tag, m.prefix)
 		// we want the highest version that is less than the next major version
 		tver, err := semver.NewVersion(tagName)
 		if err != nil {
+			if looksLikeVersion(strings.TrimPrefix(tagName, g.Config.VersionPrefix)) {
+				warnings = append(warnings, invalidTagWarning(tag))
+			}
 			continue
 		}
 		if tver.Compare(maximumVersion) < 0 && tver.Compare(moduleVersion) >= 0 {
-			if latestVersion == nil || latestVersion.LessThan(tver) {
-				logger.Info("found newer tag", "tag", tag)
-				latestVersion = tver
-				latestTag = tag
-			}
+			candidates = append(candidates, versionTag{name: tag, version: tver})
 		}
 	}
 
+	latest, err := g.selectLatest(candidates)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	warnings = append(warnings, g.unverifiedTags(candidates, latest)...)
+
 	// if there were no tags, then return the base module version
-	if latestVersion == nil {
-		return moduleVersion, "", nil
+	if latest == nil {
+		return moduleVersion, "", warnings, nil
+	}
+
+	hash, err := g.repo.CommitHash(g.repo.TagRef(latest.name))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid version tag %s: %w", latest.name, err)
+	}
+
+	logger.Info("found latest tag", "tag", latest.version, "commit", hash)
+	others, err := g.otherTags(latest, candidates, hash)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	warnings = append(warnings, ignoredTagWarnings(latest, others)...)
+
+	return latest.version, hash, warnings, nil
+}
+
+// looksLikeVersion returns true if s, a tag name without its prefix, starts
+// with a digit, so it was probably meant to be a version.
+func looksLikeVersion(s string) bool {
+	return s != "" && unicode.IsDigit(rune(s[0]))
+}
+
+// invalidTagWarning returns the warning for a tag that looks like a version
+// but does not parse as one.
+func invalidTagWarning(tag string) Warning {
+	return Warning{
+		Code:    WarningInvalidTag,
+		Message: fmt.Sprintf("tag %s is not a valid semantic version and is ignored", tag),
+	}
+}
+
+// ignoredTagWarnings returns warnings for the other tags on the commit of
+// latest, as returned by otherTags.
+func ignoredTagWarnings(latest *versionTag, others []string) (warnings []Warning) {
+	for _, tag := range others {
+		warnings = append(warnings, Warning{
+			Code:    WarningIgnoredTag,
+			Message: fmt.Sprintf("tag %s is on the same commit as %s and is ignored", tag, latest.name),
+		})
 	}
 
-	hash, err := g.repo.RevParse(latestTag + "^{commit}")
+	return warnings
+}
+
+// otherTags returns the candidates, other than latest, that tag the same
+// commit as latest, from highest to lowest version. The highest version on a
+// commit always takes precedence, so these tags are ignored.
+func (g *Gotagger) otherTags(latest *versionTag, candidates []versionTag, hash string) ([]string, error) {
+	tagged, err := g.repo.TagsAt(hash)
 	if err != nil {
-		return nil, "", err
+		return nil, err
+	}
+
+	onCommit := make(map[string]struct{}, len(tagged))
+	for _, tag := range tagged {
+		onCommit[tag] = struct{}{}
+	}
+
+	var others []string
+	for _, candidate := range candidates {
+		if _, ok := onCommit[candidate.name]; ok && candidate.name != latest.name {
+			others = append(others, candidate.name)
+		}
+	}
+
+	if len(others) > 0 {
+		g.logger.Info("ignoring other version tags on the same commit as the latest tag",
+			"tag", latest.name, "commit", hash, "ignored", strings.Join(others, ", "))
+	}
+
+	return others, nil
+}
+
+// versionTag is a tag whose name parses as a semantic version.
+type versionTag struct {
+	name    string
+	version *semver.Version
+}
+
+// selectLatest returns the candidate with the highest version, or nil if
+// there are no candidates. Candidates are sorted from highest to lowest
+// version, and tags with equal versions, such as v1.0.0 and v1.0.0+build, are
+// sorted by name, so the result does not depend on the order of candidates.
+//
+// If tag verification is enabled, then candidates whose signature cannot be
+// verified are skipped or cause an error depending on the VerifyTags policy.
+func (g *Gotagger) selectLatest(candidates []versionTag) (*versionTag, error) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if c := candidates[i].version.Compare(candidates[j].version); c != 0 {
+			return c > 0
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	for i := range candidates {
+		candidate := &candidates[i]
+		if g.Config.VerifyTags == TagVerificationNone {
+			return candidate, nil
+		}
+
+		logger := g.logger.WithValues("tag", candidate.name)
+		logger.Info("verifying tag signature")
+		err := g.repo.VerifyTag(candidate.name)
+		if err == nil {
+			return candidate, nil
+		}
+
+		if g.Config.VerifyTags == TagVerificationFail {
+			return nil, fmt.Errorf("tag %s failed signature verification: %w", candidate.name, err)
+		}
+
+		logger.Info("skipping tag that failed signature verification", "error", err.Error())
+	}
+
+	return nil, nil
+}
+
+// unverifiedTags returns warnings for the candidates that selectLatest
+// skipped because they failed signature verification. These are the
+// candidates sorted before latest, or all of them if latest is nil.
+func (g *Gotagger) unverifiedTags(candidates []versionTag, latest *versionTag) (warnings []Warning) {
+	if g.Config.VerifyTags != TagVerificationSkip {
+		return nil
+	}
+
+	for i := range candidates {
+		if &candidates[i] == latest {
+			break
+		}
+
+		warnings = append(warnings, Warning{
+			Code:    WarningUnverifiedTag,
+			Message: fmt.Sprintf("tag %s failed signature verification and is skipped", candidates[i].name),
+		})
+	}
+
+	return warnings
+}
+
+// unknownType returns true if Config.StrictCommitTypes is set and the type of
+// c is not known by table.
+func (g *Gotagger) unknownType(c git.Commit, table mapper.Table) bool {
+	return g.Config.StrictCommitTypes && c.Type != "" && !table.Known(c.Type)
+}
+
+// commitTypeTable returns the commit type table for the module name, from
+// Config.ModuleCommitTypeTables if it has one by name or alias, or else
+// Config.CommitTypeTable.
+func (g *Gotagger) commitTypeTable(name string) mapper.Table {
+	if table, ok := g.Config.ModuleCommitTypeTables[name]; ok {
+		return table
+	}
+	if alias := g.moduleAlias(name); alias != "" {
+		if table, ok := g.Config.ModuleCommitTypeTables[alias]; ok {
+			return table
+		}
+	}
+
+	return g.Config.CommitTypeTable
+}
+
+// shortHash returns the abbreviated form of a commit hash.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+
+	return hash
+}
+
+// commitWarnings returns warnings about commits, such as types that are
+// unknown by table.
+func (g *Gotagger) commitWarnings(commits []git.Commit, table mapper.Table) (warnings []Warning) {
+	for _, c := range commits {
+		if g.unknownType(c, table) {
+			warnings = append(warnings, Warning{
+				Code:    WarningUnknownCommitType,
+				Message: fmt.Sprintf("commit %s has unknown type '%s' and does not increment the version", shortHash(c.Hash), c.Type),
+			})
+		}
 	}
 
-	logger.Info("found latest tag", "tag", latestVersion, "commit", hash)
-	return latestVersion, hash, nil
+	return warnings
 }
 
-func (g *Gotagger) parseCommits(cs []git.Commit, v *semver.Version) (vinc mapper.Increment) {
+func (g *Gotagger) parseCommits(cs []git.Commit, v *semver.Version, table mapper.Table) (vinc mapper.Increment) {
 	g.logger.Info("determining version increment from commits")
 
+	preMajor := g.Config.PreMajor && v.Major() == 0
 	for _, c := range cs {
 		logger := g.logger.WithValues("commit", c.Hash)
-		inc := g.Config.CommitTypeTable.Get(c.Type)
-		if c.Breaking {
-			// ignore breaking if this is a 0.x.y version and PreMajor is set
-			logger.Info("breaking change found")
-			if !(g.Config.PreMajor && v.Major() == 0) {
-				return mapper.IncrementMajor
+		inc := g.commitIncrement(c, preMajor, table)
+
+		switch inc {
+		case mapper.IncrementMajor:
+			return mapper.IncrementMajor
+		case mapper.IncrementMinor:
+			logger.Info("minor increment")
+			if vinc < mapper.IncrementMajor {
+				vinc = inc
+			}
+		case mapper.IncrementPatch:
+			logger.Info("patch increment")
+			if vinc < mapper.IncrementMinor {
+				vinc = inc
+			}
+		case mapper.IncrementNone:
+			logger.Info("no increment")
+			if vinc < mapper.IncrementPatch {
+				vinc = inc
 			}
-			logger.Info("ignoring due to pre-release version")
 		}
+	}
+
+	return vinc
+}
+
+// commitIncrement returns the increment of the commit c according to table.
+// If preMajor is true, then breaking changes do not increment the major
+// version.
+func (g *Gotagger) commitIncrement(c git.Commit, preMajor bool, table mapper.Table) mapper.Increment {
+	logger := g.logger.WithValues("commit", c.Hash)
+	inc := table.Get(c.Type)
+	if c.IsMerge() && c.Type == "" {
+		logger.Info("using merge increment")
+		inc = g.Config.MergeIncrement
+	}
+	if g.unknownType(c, table) {
+		logger.Info("ignoring unknown commit type", "type", c.Type)
+		inc = mapper.IncrementNone
+	}
+	if g.Config.DependencyUpdates.matches(c) {
+		logger.Info("using dependency update increment")
+		inc = g.Config.DependencyUpdates.Increment
+	}
+
+	// pre-release versions may map features to a different increment
+	if preMajor && inc == mapper.IncrementMinor && g.Config.PreMajorFeatureIncrement != mapper.IncrementNone {
+		logger.Info("using pre-release feature increment")
+		inc = g.Config.PreMajorFeatureIncrement
+	}
+
+	if c.Breaking {
+		// ignore breaking if this is a 0.x.y version and PreMajor is set
+		logger.Info("breaking change found")
+		if !preMajor {
+			return mapper.IncrementMajor
+		}
+		logger.Info("ignoring due to pre-release version")
+
+		// breaking changes increment at least as much as the configured pre-release increment
+		if g.Config.PreMajorBreakingIncrement > inc {
+			logger.Info("using pre-release breaking increment")
+			inc = g.Config.PreMajorBreakingIncrement
+		}
+	}
+
+	return inc
+}
+
+// releaseModules returns the modules released by the commit c, which are the
+// modules listed in its Modules footer, or the root module, and the modules
+// nested in them. A release train, or "Modules: all", considers every module,
+// so there is nothing to validate and none are returned. The nested modules
+// are marked as optional in opts. The modules that c changes are only
+// compared with the modules it releases if committed is true.
+func (g *Gotagger) releaseModules(c git.Commit, modules []module, opts *releaseOptions, committed bool) ([]module, error) {
+	if len(modules) == 0 || opts.changedOnly() {
+		return nil, nil
+	}
+
+	if g.Config.RequireExplicitModules && c.Type == mapper.TypeRelease && !hasModulesFooter(c) {
+		return nil, errors.New("release commit must list the modules to release in a Modules footer")
+	}
 
-		switch inc {
-		case mapper.IncrementMinor:
-			logger.Info("minor increment")
-			if vinc < mapper.IncrementMajor {
-				vinc = inc
-			}
-		case mapper.IncrementPatch:
-			logger.Info("patch increment")
-			if vinc < mapper.IncrementMinor {
-				vinc = inc
-			}
-		case mapper.IncrementNone:
-			logger.Info("no increment")
-			if vinc < mapper.IncrementPatch {
-				vinc = inc
-			}
+	commitModules, err := g.extractCommitModules(c, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := opts.nestedModules(modules, commitModules)
+	if committed {
+		if err := g.validateCommit(c, modules, commitModules, nested); err != nil {
+			return nil, err
 		}
 	}
 
-	return vinc
+	return append(commitModules, nested...), nil
 }
 
-func (g *Gotagger) validateCommit(c git.Commit, modules []module, commitModules []module) error {
+func (g *Gotagger) validateCommit(c git.Commit, modules, commitModules, nested []module) error {
 	logger := g.logger.WithValues("commit", c.Hash)
 
 	// if no modules were found, then skip validation
@@ -455,18 +2122,27 @@ func (g *Gotagger) validateCommit(c git.Commit, modules []module, commitModules
 	modulesByPath := mapModulesByPath(modules)
 
 	if c.Type == mapper.TypeRelease {
+		excluded, err := g.excludedFiles()
+		if err != nil {
+			return err
+		}
+
 		// generate a list of modules changed by this commit
 		var changedModules []module
 		for _, change := range c.Changes {
-			if mod, ok := isModuleFile(change.SourceName, modulesByPath); ok {
+			if mod, ok := isModuleFile(change.SourceName, modulesByPath); ok && !excluded.match(change.SourceName) {
 				logger.Info("module affected by commit", "module", mod.name, "path", change.SourceName)
 				changedModules = append(changedModules, mod)
-			} else if mod, ok := isModuleFile(change.DestName, modulesByPath); ok {
+			} else if mod, ok := isModuleFile(change.DestName, modulesByPath); ok && !excluded.match(change.DestName) {
 				logger.Info("module affected by commit", "module", mod.name, "path", change.DestName)
 				changedModules = append(changedModules, mod)
 			}
 		}
 
+		// nested modules included by the release may be changed by it,
+		// but do not have to be
+		changedModules = withoutModules(changedModules, nested)
+
 		if err := validateCommitModules(commitModules, changedModules); err != nil {
 			return err
 		}
@@ -475,20 +2151,105 @@ func (g *Gotagger) validateCommit(c git.Commit, modules []module, commitModules
 	return nil
 }
 
-func (g *Gotagger) versions(modules, commitModules []module) (versions []string, err error) {
+func (g *Gotagger) versions(modules, commitModules []module, opts releaseOptions) ([]string, error) {
+	results, err := g.results(modules, commitModules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultVersions(results), nil
+}
+
+// resultVersions returns the version of each result.
+func resultVersions(results []Result) []string {
+	versions := make([]string, len(results))
+	for i, res := range results {
+		versions[i] = res.Version
+	}
+
+	return versions
+}
+
+func (g *Gotagger) results(modules, commitModules []module, opts releaseOptions) ([]Result, error) {
+	releases, err := g.releases(modules, commitModules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return releaseResults(releases), nil
+}
+
+func releaseResults(releases []release) []Result {
+	results := make([]Result, len(releases))
+	for i, rel := range releases {
+		results[i] = rel.Result
+	}
+
+	return results
+}
+
+func (g *Gotagger) releases(modules, commitModules []module, opts releaseOptions) (releases []release, err error) {
+	if err := g.checkCommits(); err != nil {
+		return nil, err
+	}
+
+	// reuse git processes for the many lookups needed to version modules
+	defer g.repo.BeginBatch()()
+
+	if g.Config.CommitCache {
+		if err := g.repo.LoadCommitCache(); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if serr := g.repo.SaveCommitCache(); serr != nil && err == nil {
+				err = serr
+			}
+		}()
+	}
+
 	if len(modules) != 0 {
 		g.logger.Info("enforcing module versioning")
-		versions, err = g.versionsModules(modules, commitModules)
+		releases, err = g.versionsModules(modules, commitModules, opts)
+		g.sortReleases(releases)
 	} else {
-		versions, err = g.versionsSimple()
+		releases, err = g.versionsSimple(opts)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return
+	commit, err := g.repo.CommitHash(opts.target())
+	if err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		releases[i].Commit = commit
+		if releases[i].Trace != nil {
+			releases[i].Trace.Commit = commit
+		}
+	}
+
+	// tags and commits may be missing from a shallow clone
+	shallow, err := g.repo.IsShallow()
+	if err != nil {
+		return nil, err
+	}
+	if shallow {
+		g.logger.Info("repository is a shallow clone")
+		for i := range releases {
+			releases[i].Warnings = append(releases[i].Warnings, Warning{
+				Code:    WarningShallowClone,
+				Message: "repository is a shallow clone, so versions may be calculated from incomplete history",
+			})
+		}
+	}
+
+	return releases, nil
 }
 
 var versionRegex = regexp.MustCompile(`/v\d+$`)
 
-func (g *Gotagger) versionsModules(modules []module, commitModules []module) ([]string, error) {
+func (g *Gotagger) versionsModules(modules []module, commitModules []module, opts releaseOptions) ([]release, error) {
 	g.logger.Info("versioning modules")
 
 	// if no commit modules, then get versions for all modules
@@ -496,8 +2257,26 @@ func (g *Gotagger) versionsModules(modules []module, commitModules []module) ([]
 		commitModules = modules
 	}
 
-	versions := make([]string, len(commitModules))
-	for i, mod := range commitModules {
+	excluded, err := g.excludedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	owners, err := g.codeOwners()
+	if err != nil {
+		return nil, err
+	}
+	modulesByPath := mapModulesByPath(modules)
+
+	var outside *outsideModules
+	if g.Config.OutsideChanges != OutsideChangesIgnore {
+		if outside, err = g.newOutsideModules(opts.target(), excluded); err != nil {
+			return nil, err
+		}
+	}
+
+	releases := make([]release, 0, len(commitModules))
+	for _, mod := range commitModules {
 		logger := g.logger.WithValues("module", mod.name)
 
 		// we determine the tag prefix by concatenating the module prefix, the
@@ -509,70 +2288,188 @@ func (g *Gotagger) versionsModules(modules []module, commitModules []module) ([]
 			prefix = mod.prefix + prefix
 		}
 
-		// get tags that match the prefixes
-		tags, err := g.repo.Tags(head, prefix)
+		// get tags that match the prefixes. when the number of tags is
+		// limited, only ask for tags of this module's major versions, so
+		// that tags of other major versions do not use up the limit.
+		prefixes := []string{prefix}
+		if g.Config.TagLimit > 0 {
+			if major := majorVersion(mod.name); major != "" {
+				prefixes = []string{prefix + strings.TrimPrefix(major, "v") + "."}
+			} else {
+				prefixes = []string{prefix + "0.", prefix + "1."}
+			}
+		}
+		tags, err := g.latestTags(opts, prefix, prefixes...)
 		if err != nil {
 			return nil, err
 		}
 		logger.Info("found tags", "tags", tags)
 
 		// get latest commit for this module
-		latest, hash, err := g.latestModule(tags, mod)
+		latest, hash, warnings, err := g.latestModule(tags, mod)
 		if err != nil {
 			return nil, err
 		}
 
+		// a module without tags may have a current version to start from
+		var seeded bool
+		if hash == "" {
+			current, currentHash, err := g.currentVersion(mod.path, mod.name, opts)
+			if err != nil {
+				return nil, err
+			}
+			if current != nil {
+				logger.Info("using current version", "version", current.String(), "commit", currentHash)
+				latest, hash, seeded = current, currentHash, true
+			}
+		}
+
+		assumed, isAssumed := opts.assumedLatest(moduleKey{mod.name, mod.path}, latest)
+		if isAssumed {
+			logger.Info("using assumed latest tag", "tag", assumed.name, "commit", assumed.hash)
+			latest, hash = assumed.version, assumed.hash
+		}
+
 		// Find the commits between HEAD and latest
 		// that touched any path under the module.
 		// This list will need further filtering to deal with modules
 		// that are sub-directories of this module.
-		commits, err := g.repo.RevList(head, hash, mod.path)
+		commits, err := g.repo.RevList(opts.target(), hash, mod.path)
 		if err != nil {
-			return nil, fmt.Errorf("could not fetch commits HEAD..%s: %w", hash, err)
+			return nil, fmt.Errorf("could not fetch commits %s..%s: %w", opts.target(), hash, err)
 		}
+		commits = g.excludeCommits(commits)
 
 		// group the commits by the modules they affected
-		commitsByModule := g.groupCommitsByModule(commits, modules)
+		commitsByModule := g.groupCommitsByModule(commits, modules, excluded)
+		modCommits, incCommits := commitsByModule[mod], commitsByModule[mod]
+
+		// the umbrella version of the root module includes the changes to
+		// every module
+		if g.Config.UmbrellaVersion && mod.path == rootModulePath {
+			logger.Info("calculating umbrella version")
+			modCommits, incCommits = umbrellaCommits(commits, commitsByModule, mod)
+		}
+
+		// a release train, or "Modules: all", skips modules that have not
+		// changed since they were last released
+		if (opts.changedOnly() || opts.optional[mod]) && hash != "" && len(modCommits) == 0 {
+			logger.Info("skipping unchanged module", "train", opts.train)
+			continue
+		}
+
+		// changes outside of modules may warrant a release of their own
+		if outside != nil {
+			outsideWarnings, err := outside.check(mod, hash)
+			if err != nil {
+				return nil, err
+			}
+			warnings = append(warnings, outsideWarnings...)
+		}
 
-		version, err := g.incrementVersion(latest, commitsByModule[mod])
+		table := g.commitTypeTable(mod.name)
+		version, err := g.incrementVersionAt(latest, incCommits, table, opts)
 		if err != nil {
 			return nil, fmt.Errorf("could not increment version: %w", err)
 		}
 
-		versions[i] = prefix + version
+		if opts.promote {
+			// modules that are already stable are released as usual
+			if _, ok := promoteVersion(latest); !ok {
+				logger.Info("not promoting stable module", "latest", latest.String())
+				warnings = append(warnings, stableWarnings(mod.name, prefix, latest, []string{mod.name})...)
+			}
+		} else {
+			warnings = append(warnings, unpromotedWarnings(mod.name, prefix, latest, version, []string{mod.name})...)
+		}
+
+		// the umbrella version is owned by the owners of every module
+		umbrella := g.Config.UmbrellaVersion && mod.path == rootModulePath
+		owned := func(name string) bool {
+			m, ok := isModuleFile(name, modulesByPath)
+			return ok && (umbrella || m == mod) && !excluded.match(name)
+		}
+
+		res := Result{
+			Module:   mod.name,
+			Alias:    g.moduleAlias(mod.name),
+			Path:     mod.path,
+			Prefix:   prefix,
+			Version:  prefix + version,
+			Owners:   owners.changedOwners(modCommits, owned),
+			Warnings: append(g.commitWarnings(modCommits, table), warnings...),
+		}
+		switch {
+		case isAssumed:
+			res.LatestTag, res.LatestHash = assumed.name, assumed.hash
+		case seeded:
+			// the current version has no tag
+		case hash != "":
+			if err := g.setLatest(&res, mod.prefix+latest.Original(), hash); err != nil {
+				return nil, err
+			}
+		}
+		g.traceResult(&res, tags, incCommits, latest, table)
+
+		releases = append(releases, release{Result: res, commits: modCommits, target: opts.target(), base: hash})
 	}
 
-	return versions, nil
+	return releases, nil
 }
 
-func (g *Gotagger) versionsSimple() ([]string, error) {
+func (g *Gotagger) versionsSimple(opts releaseOptions) ([]release, error) {
 	// simple version calculation where we consider all tags that match the
 	// configured prefix
 
-	// need to ensure we default to the root path, "."
-	if len(g.Config.Paths) == 0 {
-		g.Config.Paths = []string{"."}
+	var releases []release
+	for _, pth := range g.paths() {
+		rel, err := g.versionPath(pth, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, rel)
 	}
 
-	var versions []string
-	for _, pth := range g.Config.Paths {
-		version, err := g.versionPath(pth)
+	return releases, nil
+}
+
+// latestTags returns the tags of the base revision of opts that match
+// prefixes, highest version first, without ignored and floating tags. Only
+// the names that are versions after prefix count towards Config.TagLimit,
+// so more tags are listed until the limit is reached or none are left.
+func (g *Gotagger) latestTags(opts releaseOptions, prefix string, prefixes ...string) ([]string, error) {
+	limit := g.Config.TagLimit
+	for n := limit; ; n *= 2 {
+		listed, err := g.repo.LatestTags(opts.base(), n, prefixes...)
 		if err != nil {
 			return nil, err
 		}
 
-		versions = append(versions, version)
+		tags := g.filterFloatingTags(opts.filterTags(listed), prefix)
+		if n == 0 || len(listed) < n || countVersions(tags, prefix) >= limit {
+			return tags, nil
+		}
+	}
+}
+
+// countVersions returns the number of tags that are a version after prefix.
+func countVersions(tags []string, prefix string) (n int) {
+	for _, tag := range tags {
+		if _, err := semver.NewVersion(strings.TrimPrefix(tag, prefix)); err == nil {
+			n++
+		}
 	}
 
-	return versions, nil
+	return
 }
 
-func (g *Gotagger) versionPath(p string) (string, error) {
+func (g *Gotagger) versionPath(p string, opts releaseOptions) (release, error) {
 	prefix := g.Config.VersionPrefix
 
-	tags, err := g.repo.Tags(head, prefix)
+	tags, err := g.latestTags(opts, prefix, prefix)
 	if err != nil {
-		return "", err
+		return release{}, err
 	}
 
 	// if the tag prefix is an empty string, then we need to filter out
@@ -580,7 +2477,7 @@ func (g *Gotagger) versionPath(p string) (string, error) {
 	if prefix == "" {
 		filtered := make([]string, 0, len(tags))
 		for _, tag := range tags {
-			if unicode.IsDigit(rune(tag[0])) {
+			if looksLikeVersion(tag) {
 				filtered = append(filtered, tag)
 			}
 		}
@@ -588,30 +2485,303 @@ func (g *Gotagger) versionPath(p string) (string, error) {
 	}
 
 	// find the latest tag and its hash
-	latest, hash, err := g.latest(tags, prefix)
+	latest, hash, warnings, err := g.latest(tags, prefix)
 	if err != nil {
-		return "", err
+		return release{}, err
+	}
+
+	// a path without tags may have a current version to start from
+	var seeded bool
+	if hash == "" {
+		current, currentHash, err := g.currentVersion(p, "", opts)
+		if err != nil {
+			return release{}, err
+		}
+		if current != nil {
+			g.logger.Info("using current version", "path", p, "version", current.String(), "commit", currentHash)
+			latest, hash, seeded = current, currentHash, true
+		}
+	}
+
+	assumed, isAssumed := opts.assumedLatest(moduleKey{path: p}, latest)
+	if isAssumed {
+		g.logger.Info("using assumed latest tag", "tag", assumed.name, "commit", assumed.hash)
+		latest, hash = assumed.version, assumed.hash
 	}
 
 	// find all commits between HEAD and the latest tag that touch files under
 	// directory p
-	commits, err := g.repo.RevList(head, hash, p)
+	commits, err := g.repo.RevList(opts.target(), hash, p)
+	if err != nil {
+		return release{}, fmt.Errorf("could not fetch commits %s..%s: %w", opts.target(), hash, err)
+	}
+	commits = g.excludeCommits(commits)
+
+	excluded, err := g.excludedFiles()
+	if err != nil {
+		return release{}, err
+	}
+
+	owners, err := g.codeOwners()
 	if err != nil {
-		return "", fmt.Errorf("could not fetch commits HEAD..%s: %w", hash, err)
+		return release{}, err
 	}
 
 	// group the commits by the configured paths
 	// this eliminates commits that only touched files that are
 	// beneath subpaths of p
-	commitsByPath := g.groupCommitsByPath(commits)
+	commitsByPath := g.groupCommitsByPath(commits, excluded)
 
 	// increment the version
-	version, err := g.incrementVersion(latest, commitsByPath[p])
+	version, err := g.incrementVersionAt(latest, commitsByPath[p], g.Config.CommitTypeTable, opts)
+	if err != nil {
+		return release{}, fmt.Errorf("could not increment version: %w", err)
+	}
+
+	if opts.promote {
+		if _, ok := promoteVersion(latest); !ok {
+			warnings = append(warnings, stableWarnings(fmt.Sprintf("path %q", p), prefix, latest, nil)...)
+		}
+	} else {
+		warnings = append(warnings, unpromotedWarnings(fmt.Sprintf("path %q", p), prefix, latest, version, nil)...)
+	}
+
+	pathsMap := map[string]string{}
+	for _, pth := range g.paths() {
+		pathsMap[pth] = pth
+	}
+	owned := func(name string) bool {
+		selected, ok := isPathFile(name, pathsMap)
+		return ok && selected == p && !excluded.match(name)
+	}
+
+	res := Result{
+		Path:     p,
+		Prefix:   prefix,
+		Version:  prefix + version,
+		Owners:   owners.changedOwners(commitsByPath[p], owned),
+		Warnings: append(g.commitWarnings(commitsByPath[p], g.Config.CommitTypeTable), warnings...),
+	}
+	switch {
+	case isAssumed:
+		res.LatestTag, res.LatestHash = assumed.name, assumed.hash
+	case seeded:
+		// the current version has no tag
+	case hash != "":
+		if err := g.setLatest(&res, prefix+latest.Original(), hash); err != nil {
+			return release{}, err
+		}
+	}
+	g.traceResult(&res, tags, commitsByPath[p], latest, g.Config.CommitTypeTable)
+
+	return release{Result: res, commits: commitsByPath[p], target: opts.target(), base: hash}, nil
+}
+
+// release is the Result for a module or path,
+// along with the commits that determined its version.
+type release struct {
+	Result
+
+	commits []git.Commit
+
+	// the revision the commits were listed from,
+	// and the commit of the base version they were listed to, if any
+	target, base string
+}
+
+// setLatest records the latest version tag and the commit it points to in res.
+func (g *Gotagger) setLatest(res *Result, tag, hash string) error {
+	date, err := g.repo.TagDate(tag)
 	if err != nil {
-		return "", fmt.Errorf("could not increment version: %w", err)
+		return err
+	}
+
+	res.LatestTag = tag
+	res.LatestHash = hash
+	res.LatestDate = date
+
+	return nil
+}
+
+// releaseOptions are options that apply to a single run of TagRepo,
+// usually derived from the footers of the HEAD commit.
+type releaseOptions struct {
+	// promote a 0.x.y version to 1.0.0
+	promote bool
+
+	// the lowest increment of the released versions, as with
+	// "Minimum-Increment: minor"
+	minimum mapper.Increment
+
+	// name of the release train,
+	// which releases every module that changed
+	train string
+
+	// release every module that changed, as with "Modules: all"
+	all bool
+
+	// release the changed modules nested under the modules listed in
+	// Modules footers, as with "Include-Nested: true"
+	includeNested bool
+
+	// modules that are only released if they changed since their latest
+	// version, such as nested modules included by includeNested
+	optional map[module]bool
+
+	// revision whose latest version is the base version,
+	// and the revision being versioned.
+	// both default to HEAD
+	from, to string
+
+	// only calculate what is tagged,
+	// without the commit count or dirty worktree suffix
+	tagsOnly bool
+
+	// tags that are not considered when finding the latest version
+	ignoreTags map[string]bool
+
+	// version tags that do not exist yet, such as tags that Backfill would
+	// create for earlier release commits, by the module or path they are
+	// for. they are the latest version if they are higher than the tags.
+	assumed map[moduleKey]assumedTag
+}
+
+// moduleKey identifies a go module, or a path if Module is empty.
+type moduleKey struct {
+	module, path string
+}
+
+// assumedTag is a version tag that does not exist yet.
+type assumedTag struct {
+	// name is the name of the tag, including its prefix
+	name string
+
+	// version is the version of the tag, without its prefix
+	version *semver.Version
+
+	// hash is the hash of the commit the tag is for
+	hash string
+}
+
+// assumedLatest returns the assumed tag of key, if there is one and it is
+// a higher version than latest.
+func (o releaseOptions) assumedLatest(key moduleKey, latest *semver.Version) (assumedTag, bool) {
+	tag, ok := o.assumed[key]
+	if !ok || !tag.version.GreaterThan(latest) {
+		return assumedTag{}, false
+	}
+
+	return tag, true
+}
+
+// base returns the revision whose latest version is the base version.
+func (o releaseOptions) base() string {
+	if o.from == "" {
+		return o.target()
+	}
+	return o.from
+}
+
+// filterTags returns the tags that are not ignored.
+func (o releaseOptions) filterTags(tags []string) []string {
+	if len(o.ignoreTags) == 0 {
+		return tags
+	}
+
+	filtered := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !o.ignoreTags[tag] {
+			filtered = append(filtered, tag)
+		}
+	}
+
+	return filtered
+}
+
+// worktree returns true if the worktree is considered when versioning.
+func (o releaseOptions) worktree() bool {
+	return o.target() == head && !o.tagsOnly
+}
+
+// target returns the revision being versioned.
+func (o releaseOptions) target() string {
+	if o.to == "" {
+		return head
+	}
+	return o.to
+}
+
+// changedOnly returns true if every module that changed is released, for a
+// release train or "Modules: all", instead of the modules listed in Modules
+// footers.
+func (o releaseOptions) changedOnly() bool {
+	return o.train != "" || o.all
+}
+
+// extractReleaseOptions returns the releaseOptions for commit c.
+//
+// Footers are only considered if c is a release commit.
+func (g *Gotagger) extractReleaseOptions(c git.Commit) (opts releaseOptions, err error) {
+	opts.promote = g.Config.Promote
+
+	if c.Type != mapper.TypeRelease {
+		return
+	}
+
+	var moduleNames []string
+	for _, footer := range c.Footers {
+		switch footer.Title {
+		case promoteFooter:
+			if value := strings.TrimSpace(footer.Text); value != promoteStable {
+				return opts, fmt.Errorf("invalid %s footer: %q, must be %q", promoteFooter, value, promoteStable)
+			}
+			g.logger.Info("promoting to stable version", "commit", c.Hash)
+			opts.promote = true
+		case minimumFooter:
+			value := strings.TrimSpace(footer.Text)
+			if opts.minimum, err = mapper.Convert(value); err != nil || opts.minimum == mapper.IncrementMajor || opts.minimum == mapper.IncrementNone {
+				return opts, fmt.Errorf("invalid %s footer: %q, must be patch or minor", minimumFooter, value)
+			}
+			g.logger.Info("raising increment to minimum", "commit", c.Hash, "minimum", opts.minimum)
+		case includeFooter:
+			value := strings.TrimSpace(footer.Text)
+			if opts.includeNested, err = strconv.ParseBool(value); err != nil {
+				return opts, fmt.Errorf("invalid %s footer: %q, must be true or false", includeFooter, value)
+			}
+			g.logger.Info("including nested modules", "commit", c.Hash, "include", opts.includeNested)
+		case trainFooter:
+			if opts.train = strings.TrimSpace(footer.Text); opts.train == "" {
+				return opts, fmt.Errorf("invalid %s footer: must not be empty", trainFooter)
+			}
+			g.logger.Info("releasing train", "commit", c.Hash, "train", opts.train)
+		}
+		if isModulesFooter(footer.Title) {
+			moduleNames = append(moduleNames, splitModulesFooter(footer.Text)...)
+		}
+	}
+
+	if opts.train != "" && len(moduleNames) > 0 {
+		return opts, fmt.Errorf("the %s and Modules footers cannot be used together", trainFooter)
 	}
 
-	return prefix + version, nil
+	if opts.all, err = isAllModules(moduleNames); err != nil {
+		return opts, err
+	}
+	if opts.all {
+		g.logger.Info("releasing all changed modules", "commit", c.Hash)
+	}
+
+	return
+}
+
+// promoteVersion returns the 1.0.0 version for a pre-release version v, or
+// false if v has already been promoted.
+func promoteVersion(v *semver.Version) (string, bool) {
+	if v.Major() != 0 {
+		return "", false
+	}
+
+	return "1.0.0", true
 }
 
 type module struct {
@@ -626,32 +2796,72 @@ func (s sortByPath) Len() int      { return len(s) }
 func (s sortByPath) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 func (s sortByPath) Sort()         { sort.Sort(s) }
 func (s sortByPath) Less(i, j int) bool {
-	si, sj := s[i], s[j]
-	if len(si.path) < len(sj.path) {
-		return true
+	return comparePaths(s[i].path, s[j].path) < 0
+}
+
+// sortReleases sorts the releases of go modules in the order of
+// Config.ModuleOrder. Modules with the same name are ordered by path.
+func (g *Gotagger) sortReleases(releases []release) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		ri, rj := releases[i], releases[j]
+		if g.Config.ModuleOrder == ModuleOrderName && ri.Module != rj.Module {
+			return ri.Module < rj.Module
+		}
+
+		return comparePaths(ri.Path, rj.Path) < 0
+	})
+}
+
+// firstRelease returns the release of the first of modules, which is the root
+// module or the module that contains the directory gotagger was created for,
+// whatever the order of releases.
+func firstRelease(releases []release, modules []module) release {
+	if len(modules) > 0 {
+		for _, rel := range releases {
+			if rel.Path == modules[0].path {
+				return rel
+			}
+		}
+	}
+
+	return releases[0]
+}
+
+// hasModulesFooter returns true if commit c has a Modules footer.
+func hasModulesFooter(c git.Commit) bool {
+	for _, footer := range c.Footers {
+		if isModulesFooter(footer.Title) {
+			return true
+		}
 	}
-	return si.path < sj.path
+
+	return false
 }
 
 // extractCommitModules returns the modules referenced in the commit Footer(s).
 // If there are no modules referenced, then this returns the root module.
-func extractCommitModules(c git.Commit, modules []module) ([]module, error) {
-	// map module name to module for faster lookup
+func (g *Gotagger) extractCommitModules(c git.Commit, modules []module) ([]module, error) {
+	// map module name to module for faster lookup,
+	// ignoring the case of the host
 	moduleNameMap := map[string]module{}
 	for _, m := range modules {
-		moduleNameMap[m.name] = m
+		moduleNameMap[foldModuleName(m.name)] = m
 	}
 
-	// extract modules from Modules footers
+	// extract modules from Modules footers,
+	// listing modules named by several footers once
 	var commitModules []module
+	seen := map[module]bool{}
 	for _, footer := range c.Footers {
-		if footer.Title == "Modules" {
-			for _, moduleName := range strings.Split(footer.Text, ",") {
-				moduleName = strings.TrimSpace(moduleName)
-				if m, ok := moduleNameMap[moduleName]; ok {
+		if isModulesFooter(footer.Title) {
+			for _, moduleName := range splitModulesFooter(footer.Text) {
+				m, ok := moduleNameMap[foldModuleName(g.moduleName(moduleName))]
+				if !ok {
+					return nil, g.unknownModuleError(moduleName, modules)
+				}
+				if !seen[m] {
+					seen[m] = true
 					commitModules = append(commitModules, m)
-				} else {
-					return nil, fmt.Errorf("no module %s found", moduleName)
 				}
 			}
 		}
@@ -673,7 +2883,7 @@ func extractCommitModules(c git.Commit, modules []module) ([]module, error) {
 	return commitModules, nil
 }
 
-func (g *Gotagger) groupCommitsByModule(commits []git.Commit, modules []module) map[module][]git.Commit {
+func (g *Gotagger) groupCommitsByModule(commits []git.Commit, modules []module, excluded fileMatcher) map[module][]git.Commit {
 	g.logger.Info("group commits by module")
 
 	// map modules by path for faster lookup
@@ -684,7 +2894,7 @@ func (g *Gotagger) groupCommitsByModule(commits []git.Commit, modules []module)
 		logger := g.logger.WithValues("commit", commit.Hash)
 		mappedModules := map[module]struct{}{}
 		for _, change := range commit.Changes {
-			if m, ok := isModuleFile(change.SourceName, modulesByPath); ok {
+			if m, ok := isModuleFile(change.SourceName, modulesByPath); ok && !excluded.match(change.SourceName) {
 				logger.Info("module affected by commit", "module", m.name, "path", change.SourceName)
 				if _, mapped := mappedModules[m]; !mapped {
 					grouped[m] = append(grouped[m], commit)
@@ -694,7 +2904,7 @@ func (g *Gotagger) groupCommitsByModule(commits []git.Commit, modules []module)
 			}
 			// check if the dest name touched this module
 			if change.DestName != "" {
-				if m, ok := isModuleFile(change.DestName, modulesByPath); ok {
+				if m, ok := isModuleFile(change.DestName, modulesByPath); ok && !excluded.match(change.DestName) {
 					logger.Info("module affected by commit", "module", m.name, "path", change.DestName)
 					if _, mapped := mappedModules[m]; !mapped {
 						grouped[m] = append(grouped[m], commit)
@@ -709,27 +2919,34 @@ func (g *Gotagger) groupCommitsByModule(commits []git.Commit, modules []module)
 	return grouped
 }
 
-func (g *Gotagger) groupCommitsByPath(commits []git.Commit) map[string][]git.Commit {
+func (g *Gotagger) groupCommitsByPath(commits []git.Commit, excluded fileMatcher) map[string][]git.Commit {
 	g.logger.Info("group commits by path")
 
 	// make a map of paths for faster lookup
 	pathsMap := map[string]string{}
-	for _, p := range g.Config.Paths {
+	for _, p := range g.paths() {
 		pathsMap[p] = p
 	}
 
 	grouped := map[string][]git.Commit{}
 	for _, commit := range commits {
 		logger := g.logger.WithValues("commit", commit.Hash)
+		mappedPaths := map[string]struct{}{}
 		for _, change := range commit.Changes {
-			if p, ok := isPathFile(change.SourceName, pathsMap); ok {
+			if p, ok := isPathFile(change.SourceName, pathsMap); ok && !excluded.match(change.SourceName) {
 				logger.Info("path affected by commit", "path", change.SourceName, "selectedPath", p)
-				grouped[p] = append(grouped[p], commit)
+				if _, mapped := mappedPaths[p]; !mapped {
+					grouped[p] = append(grouped[p], commit)
+					mappedPaths[p] = struct{}{}
+				}
 			}
 
-			if p, ok := isPathFile(change.DestName, pathsMap); ok {
+			if p, ok := isPathFile(change.DestName, pathsMap); ok && !excluded.match(change.DestName) {
 				logger.Info("path affected by commit", "path", change.DestName, "selectedPath", p)
-				grouped[p] = append(grouped[p], commit)
+				if _, mapped := mappedPaths[p]; !mapped {
+					grouped[p] = append(grouped[p], commit)
+					mappedPaths[p] = struct{}{}
+				}
 			}
 		}
 	}
@@ -771,21 +2988,18 @@ func mapModulesByPath(modules []module) map[string]module {
 	return moduleMap
 }
 
-func normalizePath(p string) string {
-	// normalize to /
-	p = filepath.ToSlash(p)
-
-	// ensure leading "./"
-	if !strings.HasPrefix(p, "./") && p != "." {
-		p = "./" + p
-	}
-
-	// ensure trailing /
-	if !strings.HasSuffix(p, "/") {
-		p += "/"
+// excludedPath returns true if any of the normalized paths in excludes is a
+// prefix of the directory dir.
+func excludedPath(dir string, excludes []string) bool {
+	// normalize module path to ease comparisons
+	normPath := normalizePath(dir)
+	for _, exclude := range excludes {
+		if strings.HasPrefix(normPath, exclude) {
+			return true
+		}
 	}
 
-	return p
+	return false
 }
 
 func validateCommitModules(commitModules, changedModules []module) (err error) {
@@ -2,6 +2,7 @@ package gotagger
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-logr/logr"
@@ -32,3 +33,81 @@ func TestWindowsPaths(t *testing.T) {
 		assert.Equal(t, []string{"v1.1.0"}, versions)
 	}
 }
+
+func TestWindowsPaths_results(t *testing.T) {
+	g, repo, path := newGotagger(t)
+
+	simpleGoRepo(t, repo, path)
+
+	// paths are native, but tag prefixes always use /
+	if results, err := g.Results(); assert.NoError(t, err) && assert.Len(t, results, 2) {
+		assert.Equal(t, ".", results[0].Path)
+		assert.Equal(t, `sub\module`, results[1].Path)
+		assert.Equal(t, "sub/module/v0.1.1", results[1].Version)
+	}
+}
+
+func TestWindowsPaths_subdir(t *testing.T) {
+	_, repo, path := newGotagger(t)
+
+	simpleGoRepo(t, repo, path)
+
+	// a subdirectory given with either separator selects the same module
+	for _, dir := range []string{filepath.Join(path, "sub", "module"), filepath.ToSlash(filepath.Join(path, "sub", "module"))} {
+		g, err := New(dir)
+		require.NoError(t, err)
+
+		if versions, err := g.ModuleVersions(); assert.NoError(t, err, dir) {
+			assert.Equal(t, []string{"sub/module/v0.1.1"}, versions, dir)
+		}
+	}
+}
+
+func TestWindowsPaths_major_version(t *testing.T) {
+	g, repo, path := newGotagger(t)
+
+	simpleGoRepo(t, repo, path)
+	testutils.CommitFile(t, repo, path, "sub/module/v2/go.mod", "feat: add v2", []byte("module foo/sub/module/v2\n"))
+	testutils.CreateTag(t, repo, "sub/module/v2.0.0")
+	testutils.CommitFile(t, repo, path, "sub/module/v2/file", "fix: fix v2", []byte("v2 data"))
+
+	if versions, err := g.ModuleVersions("foo/sub/module/v2"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"sub/module/v2.0.1"}, versions)
+	}
+}
+
+func TestWindowsPaths_long(t *testing.T) {
+	g, repo, path := newGotagger(t)
+
+	simpleGoRepo(t, repo, path)
+
+	// a module whose files are beyond MAX_PATH
+	dir := strings.Repeat("deeply-nested-directory/", 12) + "module"
+	require.Greater(t, len(filepath.Join(path, dir, "go.mod")), 260)
+
+	testutils.CommitFile(t, repo, path, dir+"/go.mod", "feat: add a deep module", []byte("module foo/"+dir+"\n"))
+	testutils.CreateTag(t, repo, dir+"/v1.0.0")
+	testutils.CommitFile(t, repo, path, dir+"/file", "fix: fix deep module", []byte("deep data"))
+
+	if versions, err := g.ModuleVersions("foo/" + dir); assert.NoError(t, err) {
+		assert.Equal(t, []string{dir + "/v1.0.1"}, versions)
+	}
+}
+
+func TestWindowsPaths_checkChangelogs(t *testing.T) {
+	c := git.Commit{Changes: []git.Change{
+		{SourceName: "CHANGELOG.md", DestName: "CHANGELOG.md"},
+		{SourceName: "sub/module/CHANGELOG.md", DestName: "sub/module/CHANGELOG.md"},
+	}}
+	results := []Result{{Path: "."}, {Path: `sub\module`}}
+
+	assert.NoError(t, checkChangelogs(c, results, "CHANGELOG.md"))
+}
+
+func TestWindowsPaths_inDir(t *testing.T) {
+	assert.True(t, inDir(`sub\module`, "sub/module"))
+	assert.True(t, inDir(`sub\module\pkg`, "sub/module"))
+	assert.True(t, inDir("sub/module/pkg", `sub\module`))
+	assert.False(t, inDir(`sub\modules`, `sub\module`))
+	assert.Equal(t, "sub/module/", modulePrefix(`sub\module\v2`, "foo/sub/module/v2"))
+}
@@ -0,0 +1,222 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gotaggertest builds synthetic git repositories and commit histories
+// for testing release tooling that embeds gotagger.
+//
+// Repositories are created in a temporary directory of the test, and are
+// removed when it finishes. Any failure to build a repository fails the test.
+package gotaggertest
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sassoftware/gotagger"
+	"github.com/sassoftware/gotagger/internal/testutils"
+)
+
+// HistoryFile is the file that Commits changes.
+const HistoryFile = "HISTORY"
+
+// T is the part of testing.TB that the helpers use.
+// It is implemented by *testing.T and *testing.B.
+type T interface {
+	Errorf(string, ...interface{})
+	FailNow()
+	Fatal(...interface{})
+	Fatalf(string, ...interface{})
+	Helper()
+	Log(args ...interface{})
+	TempDir() string
+}
+
+// File is a file to commit, with its path relative to the root of the
+// repository, using forward slashes.
+type File struct {
+	Path     string
+	Contents string
+}
+
+// Repo is a git repository for testing.
+type Repo struct {
+	// Path is the root of the worktree of the repository.
+	Path string
+
+	t    T
+	repo *git.Repository
+}
+
+// NewRepo returns a new repository, with no commits, in a temporary
+// directory of t.
+func NewRepo(t T) *Repo {
+	t.Helper()
+
+	repo, path := testutils.NewGitRepo(t)
+	return &Repo{Path: path, t: t, repo: repo}
+}
+
+// Gotagger returns a Gotagger for the repository, with the default
+// configuration.
+func (r *Repo) Gotagger() *gotagger.Gotagger {
+	r.t.Helper()
+
+	g, err := gotagger.New(r.Path)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return g
+}
+
+// Commit commits files with the commit message, and returns the hash of the
+// commit.
+func (r *Repo) Commit(message string, files ...File) string {
+	r.t.Helper()
+
+	return testutils.CommitFiles(r.t, r.repo, r.Path, message, r.fileCommits(files)).String()
+}
+
+// CommitAs is like Commit, but the commit is authored by the user name with
+// the email address email.
+func (r *Repo) CommitAs(name, email, message string, file File) string {
+	r.t.Helper()
+
+	return testutils.CommitFileAs(r.t, r.repo, r.Path, filepath.FromSlash(file.Path), message, []byte(file.Contents), name, email).String()
+}
+
+// Commits makes a commit with each of messages, in order, and returns their
+// hashes. Each commit appends its message to HistoryFile, so that a history
+// can be written as a list of commit messages:
+//
+//	repo.Commits("feat: add foo", "fix: fix foo", "release: v1.1.0")
+func (r *Repo) Commits(messages ...string) []string {
+	r.t.Helper()
+
+	// a missing file is an empty history
+	data, _ := os.ReadFile(filepath.Join(r.Path, HistoryFile))
+	history := string(data)
+
+	hashes := make([]string, len(messages))
+	for i, message := range messages {
+		history += message + "\n"
+		hashes[i] = r.Commit(message, File{Path: HistoryFile, Contents: history})
+	}
+
+	return hashes
+}
+
+// Merge commits files with the commit message as a merge of HEAD and the
+// commit or branch parent, and returns the hash of the merge commit. The
+// trees of the parents are not merged, so pass the changes that the merge
+// brings in as files.
+func (r *Repo) Merge(message, parent string, files ...File) string {
+	r.t.Helper()
+
+	return testutils.MergeFiles(r.t, r.repo, r.Path, message, r.resolve(parent), r.fileCommits(files)).String()
+}
+
+// AddModule commits a go.mod that declares the module name in the directory
+// dir, with a "feat" commit message, and returns the hash of the commit. Use
+// "." for the root module.
+func (r *Repo) AddModule(dir, name string) string {
+	r.t.Helper()
+
+	return r.Commit("feat: add "+name, File{Path: path.Join(dir, "go.mod"), Contents: "module " + name + "\n"})
+}
+
+// Tag creates an annotated tag name at HEAD.
+func (r *Repo) Tag(name string) {
+	r.t.Helper()
+
+	testutils.CreateTag(r.t, r.repo, name)
+}
+
+// Branch creates a branch name at HEAD, or moves it to HEAD if it exists,
+// and checks it out.
+func (r *Repo) Branch(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	branch := plumbing.NewBranchReferenceName(name)
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(branch, head.Hash())); err != nil {
+		r.t.Fatal(err)
+	}
+
+	r.Checkout(name)
+}
+
+// Checkout checks out the branch name.
+func (r *Repo) Checkout(name string) {
+	r.t.Helper()
+
+	w, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	if err := w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(name)}); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// NewRemote creates a bare repository in a temporary directory of the test,
+// adds it as the origin remote, pushes every branch to it, and sets the
+// upstream of each branch. It returns the path of the remote repository.
+func (r *Repo) NewRemote() string {
+	r.t.Helper()
+
+	_, remotePath := testutils.NewRemote(r.t, r.repo)
+	return remotePath
+}
+
+// Tags returns the sorted names of the tags of the repository in refs/tags/.
+func (r *Repo) Tags() []string {
+	r.t.Helper()
+
+	iter, err := r.repo.Tags()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	var tags []string
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	}); err != nil {
+		r.t.Fatal(err)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// resolve returns the hash of the commit rev.
+func (r *Repo) resolve(rev string) plumbing.Hash {
+	r.t.Helper()
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return *hash
+}
+
+// fileCommits converts files to the files of testutils.
+func (r *Repo) fileCommits(files []File) []testutils.FileCommit {
+	commits := make([]testutils.FileCommit, len(files))
+	for i, f := range files {
+		commits[i] = testutils.FileCommit{Path: filepath.FromSlash(f.Path), Contents: []byte(f.Contents)}
+	}
+
+	return commits
+}
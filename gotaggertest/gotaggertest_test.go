@@ -0,0 +1,74 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotaggertest
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepo(t *testing.T) {
+	repo := NewRepo(t)
+
+	repo.Commits("feat: add foo", "fix: fix foo")
+	repo.Tag("v1.0.0")
+	repo.Commits("feat: add bar")
+
+	g := repo.Gotagger()
+	if version, err := g.Version(); assert.NoError(t, err) {
+		assert.Equal(t, "v1.1.0", version)
+	}
+
+	// a release is tagged and pushed
+	remote := repo.NewRemote()
+	repo.Commits("release: v1.1.0")
+	g.Config.CreateTag = true
+	g.Config.PushTag = true
+	if versions, err := g.TagRepo(); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.1.0"}, versions)
+	}
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, repo.Tags())
+
+	out, err := exec.Command("git", "-C", remote, "tag").CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "v1.1.0", strings.TrimSpace(string(out)))
+}
+
+func TestRepo_modules(t *testing.T) {
+	repo := NewRepo(t)
+
+	repo.AddModule(".", "example.com/foo")
+	repo.AddModule("bar", "example.com/foo/bar")
+	repo.Tag("v1.0.0")
+	repo.Tag("bar/v0.1.0")
+	repo.Commit("fix(bar): fix bar", File{Path: "bar/bar.go", Contents: "package bar\n"})
+
+	if versions, err := repo.Gotagger().ModuleVersions(); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.0.0", "bar/v0.1.1"}, versions)
+	}
+}
+
+func TestRepo_branches(t *testing.T) {
+	repo := NewRepo(t)
+
+	repo.Commits("feat: add foo")
+	repo.Tag("v1.0.0")
+	repo.Branch("feature")
+	repo.Commits("feat: add bar")
+	repo.Checkout("master")
+	repo.Merge("Merge branch 'feature'", "feature", File{Path: HistoryFile, Contents: "feat: add foo\nfeat: add bar\n"})
+
+	author := repo.CommitAs("Some One", "some.one@example.com", "fix: fix foo", File{Path: "foo", Contents: "foo"})
+	out, err := exec.Command("git", "-C", repo.Path, "log", "-1", "--format=%an <%ae>", author).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "Some One <some.one@example.com>", strings.TrimSpace(string(out)))
+
+	if version, err := repo.Gotagger().Version(); assert.NoError(t, err) {
+		assert.Equal(t, "v1.1.0", version)
+	}
+}
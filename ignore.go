@@ -0,0 +1,142 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sassoftware/gotagger/internal/gitignore"
+)
+
+// ignoreFile is the name of the file at the root of the repository that lists
+// the paths that never affect versions, using gitignore syntax.
+const ignoreFile = ".gotaggerignore"
+
+// fileMatcher matches the files whose changes never affect versions.
+type fileMatcher struct {
+	m *gitignore.Matcher
+}
+
+// excludedFiles returns a fileMatcher for Config.ExcludeFiles and the
+// patterns in the ignore file of the repository.
+func (g *Gotagger) excludedFiles() (fileMatcher, error) {
+	patterns, err := g.ignoredPatterns()
+	if err != nil {
+		return fileMatcher{}, err
+	}
+
+	for _, p := range g.Config.ExcludeFiles {
+		patterns = append(patterns, gitignore.ParsePattern(p))
+	}
+
+	return newFileMatcher(patterns), nil
+}
+
+// ignoredPatterns returns the patterns in the ignore file of the repository.
+func (g *Gotagger) ignoredPatterns() ([]gitignore.Pattern, error) {
+	lines, err := g.ignoredLines()
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePatterns(lines), nil
+}
+
+// ignoredLines returns the lines of the ignore file of the repository that
+// are patterns. The ignore file is read from the same place as go modules
+// are discovered: Config.FS if set, the tree of HEAD if
+// Config.CommittedModules is set, or else the worktree.
+func (g *Gotagger) ignoredLines() ([]string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case g.Config.FS != nil:
+		data, err = fs.ReadFile(g.Config.FS, ignoreFile)
+	case g.Config.CommittedModules:
+		data, err = g.repo.ReadFileAt(head, ignoreFile)
+	default:
+		data, err = os.ReadFile(filepath.Join(g.repo.Path, ignoreFile))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return patternLines(data), nil
+}
+
+// readIgnoreFile returns the patterns in the ignore file at the root of fsys,
+// if there is one.
+func readIgnoreFile(fsys fs.FS) ([]gitignore.Pattern, error) {
+	data, err := fs.ReadFile(fsys, ignoreFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return parsePatterns(patternLines(data)), nil
+}
+
+// patternLines returns the lines of the contents of an ignore file, skipping
+// blank lines and comments.
+func patternLines(data []byte) []string {
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// parsePatterns parses the lines of an ignore file.
+func parsePatterns(lines []string) []gitignore.Pattern {
+	patterns := make([]gitignore.Pattern, len(lines))
+	for i, line := range lines {
+		patterns[i] = gitignore.ParsePattern(line)
+	}
+
+	return patterns
+}
+
+// newFileMatcher returns a fileMatcher for patterns.
+func newFileMatcher(patterns []gitignore.Pattern) fileMatcher {
+	if len(patterns) == 0 {
+		return fileMatcher{}
+	}
+
+	m := gitignore.NewMatcher(patterns)
+	return fileMatcher{m: &m}
+}
+
+// match returns true if the file name, relative to the root of the
+// repository, is excluded.
+func (f fileMatcher) match(name string) bool {
+	return f.matchPath(name, false)
+}
+
+// matchPath returns true if the file or directory name, relative to the root
+// of the repository, is excluded.
+func (f fileMatcher) matchPath(name string, isDir bool) bool {
+	if f.m == nil || name == "" || name == "." {
+		return false
+	}
+
+	return f.m.Match(strings.Split(filepath.ToSlash(name), "/"), isDir)
+}
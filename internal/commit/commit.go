@@ -5,14 +5,27 @@ package commit
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 var (
 	typeRe   = regexp.MustCompile(`^(?P<type>\w+)(?:\((?P<scope>[-\w$.*/ ]+)\))?(?P<breaking>!)?: (?P<subject>.+)$`)
-	mergeRe  = regexp.MustCompile(`^Merge "(.*)"$`)
+	mergeRe  = regexp.MustCompile(`^Merge "(.*)"(?: into [^\s"]+)?$`)
 	revertRe = regexp.MustCompile(`^Revert\s"([\s\S]+)"\s*This reverts commit (\w+)\.`)
 	footerRe = regexp.MustCompile(`^(?P<title>[-\w ]+): (?P<text>.*)`)
+
+	// Gerrit merges the changes of a topic with a subject such as
+	// Merge changes from topic "foo" into main, and identifies each change
+	// with a Change-Id trailer.
+	topicRe    = regexp.MustCompile(`^Merge changes from topic (?:"([^"]+)"|'([^']+)')(?: into [^\s"]+)?$`)
+	changeIDRe = regexp.MustCompile(`(?m)^Change-Id: (I[0-9a-f]{40})\s*$`)
+
+	// Azure Repos prefixes the subjects of pull request merges with
+	// "Merged PR 1234: ", and Bitbucket suffixes them with
+	// " (pull request #12)".
+	azurePRRe     = regexp.MustCompile(`^Merged PR (\d+): (.*)$`)
+	bitbucketPRRe = regexp.MustCompile(`^(.*?)\s*\(pull request #(\d+)\)$`)
 )
 
 // Commit represents the parsed data from a conventional commit message.
@@ -62,7 +75,55 @@ type Revert struct {
 	Hash   string
 }
 
-// Parse parses a commit message and returns a conventional commit.
+// ChangeID returns the Gerrit Change-Id trailer of the commit message s,
+// such as "I8473b95934b5732ac55d26311a706c9c2bde9940", or the empty string if
+// it has none. If there are several, then the last one is returned, as Gerrit
+// does.
+func ChangeID(s string) string {
+	m := changeIDRe.FindAllStringSubmatch(s, -1)
+	if len(m) == 0 {
+		return ""
+	}
+
+	return m[len(m)-1][1]
+}
+
+// Topic returns the Gerrit topic of a merge commit with the subject header,
+// such as "foo" for `Merge changes from topic "foo"`, or the empty string if
+// header is not the subject of a topic merge.
+func Topic(header string) string {
+	m := topicRe.FindStringSubmatch(header)
+	if len(m) == 0 {
+		return ""
+	}
+
+	return m[1] + m[2]
+}
+
+// PullRequest splits the subject header of a pull request merge made by
+// Azure Repos, such as "Merged PR 1234: feat: add foo", or by Bitbucket, such
+// as "feat: add foo (pull request #12)", into the subject without the
+// decoration and the number of the pull request. If header has neither
+// decoration, then it is returned as is, with a number of 0.
+func PullRequest(header string) (string, int) {
+	subject, number := header, ""
+	if m := azurePRRe.FindStringSubmatch(header); len(m) > 0 {
+		subject, number = m[2], m[1]
+	} else if m := bitbucketPRRe.FindStringSubmatch(header); len(m) > 0 {
+		subject, number = m[1], m[2]
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return header, 0
+	}
+
+	return subject, n
+}
+
+// Parse parses a commit message and returns a conventional commit. The pull
+// request decorations of Azure Repos and Bitbucket are stripped from the
+// header first.
 //
 // If the message does not follow the format, then nil is returned.
 func Parse(s string) (c Commit) {
@@ -72,6 +133,7 @@ func Parse(s string) (c Commit) {
 
 	lines := strings.Split(s, "\n")
 	header, lines := lines[0], lines[1:]
+	header, _ = PullRequest(header)
 
 	// Is this a merge commit
 	var merge bool
@@ -135,6 +135,93 @@ func TestParse_merge(t *testing.T) {
 	})
 }
 
+func TestParse_gerritMerge(t *testing.T) {
+	c := Parse("Merge \"feat: add foo\" into main\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940")
+	assert.True(t, c.Merge)
+	assert.Equal(t, "feat", c.Type)
+	assert.Equal(t, "add foo", c.Subject)
+	assert.Equal(t, "feat: add foo", c.Header)
+}
+
+func TestChangeID(t *testing.T) {
+	tests := []struct {
+		title   string
+		message string
+		want    string
+	}{
+		{"none", "feat: add foo", ""},
+		{"trailer", "feat: add foo\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940", "I8473b95934b5732ac55d26311a706c9c2bde9940"},
+		{"with other trailers", "fix: fix foo\n\nbody\n\nChange-Id: I0123456789abcdef0123456789abcdef01234567\nSigned-off-by: Jane Doe <jane@example.com>", "I0123456789abcdef0123456789abcdef01234567"},
+		{"last wins", "feat: foo\n\nChange-Id: I0000000000000000000000000000000000000000\nChange-Id: I1111111111111111111111111111111111111111", "I1111111111111111111111111111111111111111"},
+		{"not conventional", "Add foo\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940", "I8473b95934b5732ac55d26311a706c9c2bde9940"},
+		{"too short", "feat: add foo\n\nChange-Id: I8473b959", ""},
+		{"in body", "feat: add foo\n\nsee Change-Id: I8473b95934b5732ac55d26311a706c9c2bde9940", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.want, ChangeID(tt.message))
+		})
+	}
+}
+
+func TestPullRequest(t *testing.T) {
+	tests := []struct {
+		header      string
+		wantSubject string
+		wantNumber  int
+	}{
+		{"Merged PR 1234: feat: add foo", "feat: add foo", 1234},
+		{"feat: add foo (pull request #12)", "feat: add foo", 12},
+		{"Merged PR 7: Update README", "Update README", 7},
+		{"feat: add foo (#12)", "feat: add foo (#12)", 0},
+		{"feat: add foo", "feat: add foo", 0},
+		{"Merged PR: feat: add foo", "Merged PR: feat: add foo", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			subject, number := PullRequest(tt.header)
+			assert.Equal(t, tt.wantSubject, subject)
+			assert.Equal(t, tt.wantNumber, number)
+		})
+	}
+}
+
+func TestParse_pullRequest(t *testing.T) {
+	c := Parse("Merged PR 1234: feat(api)!: add foo\n\nRelated work items: #56")
+	assert.Equal(t, "feat", c.Type)
+	assert.Equal(t, "api", c.Scope)
+	assert.Equal(t, "add foo", c.Subject)
+	assert.True(t, c.Breaking)
+	assert.Equal(t, "feat(api)!: add foo", c.Header)
+
+	c = Parse("fix: fix foo (pull request #12)")
+	assert.Equal(t, "fix", c.Type)
+	assert.Equal(t, "fix foo", c.Subject)
+	assert.Equal(t, "fix: fix foo", c.Header)
+}
+
+func TestTopic(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{`Merge changes from topic "foo"`, "foo"},
+		{`Merge changes from topic 'foo-bar'`, "foo-bar"},
+		{`Merge changes from topic "foo" into main`, "foo"},
+		{`Merge "feat: add foo"`, ""},
+		{`Merge changes I1234,I5678`, ""},
+		{`feat: add foo`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, Topic(tt.header))
+		})
+	}
+}
+
 func TestParse_revert(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		ctype := rapid.StringMatching(`^\w+$`).Draw(t, "type")
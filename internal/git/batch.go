@@ -0,0 +1,191 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// batch is a long-lived git cat-file process that answers object queries
+// without spawning a new git process for each one. Spawning processes is
+// particularly slow on Windows.
+type batch struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// batchSession holds the cat-file processes of a Repository while a batch
+// session is active.
+type batchSession struct {
+	mu    sync.Mutex
+	depth int
+	check *batch // git cat-file --batch-check
+	blobs *batch // git cat-file --batch
+}
+
+// BeginBatch starts a batch session, during which object lookups, such as
+// RevParse and reading files from a TreeFS, are answered by long-lived
+// git cat-file processes instead of a new git process per lookup.
+// Sessions may be nested. Call the returned function to end the session;
+// the processes are stopped when the outermost session ends.
+//
+// If the processes cannot be started, then lookups fall back to running
+// a git process for each one.
+func (r *Repository) BeginBatch() (end func()) {
+	r.batch.mu.Lock()
+	defer r.batch.mu.Unlock()
+
+	r.batch.depth++
+	if r.batch.depth == 1 {
+		r.logger.V(1).Info("starting batch session")
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(r.endBatch)
+	}
+}
+
+func (r *Repository) endBatch() {
+	r.batch.mu.Lock()
+	defer r.batch.mu.Unlock()
+
+	r.batch.depth--
+	if r.batch.depth > 0 {
+		return
+	}
+
+	r.logger.V(1).Info("ending batch session")
+	for _, b := range []*batch{r.batch.check, r.batch.blobs} {
+		if b != nil {
+			if err := b.close(); err != nil {
+				r.logger.V(1).Info("error stopping git cat-file", "error", err.Error())
+			}
+		}
+	}
+	r.batch.check, r.batch.blobs = nil, nil
+}
+
+// objectInfo returns the hash and type of the object named by rev, using
+// git cat-file --batch-check. It returns ok false if there is no batch
+// session.
+func (r *Repository) objectInfo(rev string) (hash, typ string, ok bool, err error) {
+	r.batch.mu.Lock()
+	defer r.batch.mu.Unlock()
+
+	b := r.startBatch(&r.batch.check, "--batch-check")
+	if b == nil {
+		return "", "", false, nil
+	}
+
+	hash, typ, _, err = b.header(rev)
+	return hash, typ, true, err
+}
+
+// objectContents returns the contents of the object named by rev, using
+// git cat-file --batch. It returns ok false if there is no batch session.
+func (r *Repository) objectContents(rev string) (data []byte, ok bool, err error) {
+	r.batch.mu.Lock()
+	defer r.batch.mu.Unlock()
+
+	b := r.startBatch(&r.batch.blobs, "--batch")
+	if b == nil {
+		return nil, false, nil
+	}
+
+	_, _, size, err := b.header(rev)
+	if err != nil {
+		return nil, true, err
+	}
+
+	// contents are followed by a newline
+	data = make([]byte, size+1)
+	if _, err := io.ReadFull(b.stdout, data); err != nil {
+		return nil, true, err
+	}
+
+	return data[:size], true, nil
+}
+
+// startBatch returns the process in *b, starting it if needed. It returns nil
+// if there is no batch session or the process cannot be started.
+// The caller must hold r.batch.mu.
+func (r *Repository) startBatch(b **batch, mode string) *batch {
+	if r.batch.depth == 0 {
+		return nil
+	}
+
+	if *b == nil {
+		cmd := exec.Command("git", gitArgs(append(r.offlineArgs(), "cat-file", mode)...)...)
+		cmd.Dir = r.Path
+		cmd.Env = append(os.Environ(), r.offlineEnv()...)
+
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil
+		}
+		if err := cmd.Start(); err != nil {
+			r.logger.V(1).Info("could not start git cat-file", "error", err.Error())
+			return nil
+		}
+
+		*b = &batch{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}
+	}
+
+	return *b
+}
+
+// header requests rev and returns the object header that git cat-file
+// replies with.
+func (b *batch) header(rev string) (hash, typ string, size int, err error) {
+	if strings.Contains(rev, "\n") {
+		return "", "", 0, fmt.Errorf("invalid revision %q", rev)
+	}
+
+	if _, err := io.WriteString(b.stdin, rev+"\n"); err != nil {
+		return "", "", 0, err
+	}
+
+	line, err := b.stdout.ReadString('\n')
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	// <oid> SP <type> SP <size> LF, or <object> SP missing LF
+	if strings.HasSuffix(line, " missing\n") || strings.HasSuffix(line, " ambiguous\n") {
+		return "", "", 0, fmt.Errorf("unknown revision %s", rev)
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return "", "", 0, fmt.Errorf("invalid git cat-file output: %q", line)
+	}
+
+	size, err = strconv.Atoi(fields[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid git cat-file output: %q", line)
+	}
+
+	return fields[0], fields[1], size, nil
+}
+
+func (b *batch) close() error {
+	if err := b.stdin.Close(); err != nil {
+		return err
+	}
+
+	return b.cmd.Wait()
+}
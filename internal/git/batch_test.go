@@ -0,0 +1,64 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/sassoftware/gotagger/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_BeginBatch(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	want, err := r.RevParse("v1.0.0^{commit}")
+	require.NoError(t, err)
+
+	fsys, err := r.TreeFS("master")
+	require.NoError(t, err)
+
+	end := r.BeginBatch()
+	inner := r.BeginBatch()
+
+	if got, err := r.RevParse("v1.0.0^{commit}"); assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+
+	_, err = r.RevParse("v9.9.9")
+	assert.EqualError(t, err, "unknown revision v9.9.9")
+
+	if data, err := fs.ReadFile(fsys, "bar"); assert.NoError(t, err) {
+		assert.Equal(t, "some bars too", string(data))
+	}
+	assert.NotNil(t, r.batch.check)
+	assert.NotNil(t, r.batch.blobs)
+
+	// ending a nested session keeps the processes running,
+	// and ending a session twice does nothing
+	inner()
+	inner()
+	assert.NotNil(t, r.batch.check)
+
+	if data, err := fs.ReadFile(fsys, "foo"); assert.NoError(t, err) {
+		assert.Equal(t, "foo more", string(data))
+	}
+
+	end()
+	assert.Nil(t, r.batch.check)
+	assert.Nil(t, r.batch.blobs)
+
+	// lookups still work without a session
+	if got, err := r.RevParse("v1.0.0^{commit}"); assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+	assert.Nil(t, r.batch.check)
+}
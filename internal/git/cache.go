@@ -0,0 +1,147 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sassoftware/gotagger/internal/commit"
+)
+
+const (
+	cacheDir  = "gotagger"
+	cacheFile = "commits.json"
+
+	// cacheVersion must be incremented whenever commit.Parse changes how
+	// messages are parsed, so that stale results are not reused.
+	cacheVersion = 3
+)
+
+// commitCache holds parsed commit messages by commit hash.
+// A commit's message cannot change without changing its hash,
+// so entries never need to be invalidated.
+type commitCache struct {
+	mu      sync.Mutex
+	commits map[string]commit.Commit
+	loaded  bool
+	dirty   bool
+}
+
+// cacheData is the on-disk format of the commit cache.
+type cacheData struct {
+	Version int                      `json:"version"`
+	Commits map[string]commit.Commit `json:"commits"`
+}
+
+// parseMessage returns the parsed message of the commit hash,
+// parsing and caching it if it has not been parsed before.
+func (r *Repository) parseMessage(hash, message string) commit.Commit {
+	if r.ParseMessage != nil {
+		if c, ok := r.ParseMessage(message); ok {
+			return c
+		}
+	}
+
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	if c, ok := r.cache.commits[hash]; ok && hash != "" {
+		return c
+	}
+
+	c := commit.Parse(message)
+	if hash != "" {
+		if r.cache.commits == nil {
+			r.cache.commits = make(map[string]commit.Commit)
+		}
+		r.cache.commits[hash] = c
+		r.cache.dirty = true
+	}
+
+	return c
+}
+
+// cachePath returns the path to the on-disk commit cache.
+func (r *Repository) cachePath() string {
+	return filepath.Join(r.GitDir, cacheDir, cacheFile)
+}
+
+// LoadCommitCache reads the parsed commits saved by SaveCommitCache,
+// so that they are not parsed again. It does nothing if the cache was
+// already loaded. A missing, unreadable, or outdated cache is ignored.
+func (r *Repository) LoadCommitCache() error {
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	if r.cache.loaded {
+		return nil
+	}
+	r.cache.loaded = true
+
+	logger := r.logger.V(1).WithValues("path", r.cachePath())
+	raw, err := os.ReadFile(r.cachePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var data cacheData
+	if err := json.Unmarshal(raw, &data); err != nil || data.Version != cacheVersion {
+		logger.Info("ignoring invalid or outdated commit cache")
+		return nil
+	}
+
+	if r.cache.commits == nil {
+		r.cache.commits = make(map[string]commit.Commit, len(data.Commits))
+	}
+	for hash, c := range data.Commits {
+		if _, ok := r.cache.commits[hash]; !ok {
+			r.cache.commits[hash] = c
+		}
+	}
+
+	logger.Info("loaded commit cache", "commits", len(data.Commits))
+	return nil
+}
+
+// SaveCommitCache writes the parsed commits to the git directory, so that
+// later runs can load them with LoadCommitCache. It does nothing if no
+// commits were parsed since the cache was loaded or saved.
+func (r *Repository) SaveCommitCache() error {
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	if !r.cache.dirty {
+		return nil
+	}
+
+	raw, err := json.Marshal(cacheData{Version: cacheVersion, Commits: r.cache.commits})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.cachePath()), 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that the cache is never truncated
+	tmp := r.cachePath() + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.cachePath()); err != nil {
+		return err
+	}
+
+	r.cache.dirty = false
+	r.logger.V(1).Info("saved commit cache", "path", r.cachePath(), "commits", len(r.cache.commits))
+
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/sassoftware/gotagger/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitCache(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	// nothing is written until commits are parsed
+	require.NoError(t, r.LoadCommitCache())
+	require.NoError(t, r.SaveCommitCache())
+	assert.NoFileExists(t, r.cachePath())
+
+	commits, err := r.RevList("master", "")
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	require.NoError(t, r.SaveCommitCache())
+
+	raw, err := os.ReadFile(r.cachePath())
+	require.NoError(t, err)
+
+	var data cacheData
+	require.NoError(t, json.Unmarshal(raw, &data))
+	assert.Equal(t, cacheVersion, data.Version)
+	assert.Len(t, data.Commits, 3)
+
+	// a new repository uses the cached commits instead of parsing them
+	hash := commits[0].Hash
+	cached := data.Commits[hash]
+	cached.Type = "cached"
+	data.Commits[hash] = cached
+	writeCache(t, r.cachePath(), data)
+
+	r, err = New(path)
+	require.NoError(t, err)
+	require.NoError(t, r.LoadCommitCache())
+
+	if commits, err := r.RevList("master", ""); assert.NoError(t, err) {
+		assert.Equal(t, "cached", commits[0].Type)
+		assert.Equal(t, "feat", commits[1].Type)
+	}
+
+	// outdated caches are ignored
+	data.Version = cacheVersion + 1
+	writeCache(t, r.cachePath(), data)
+
+	r, err = New(path)
+	require.NoError(t, err)
+	require.NoError(t, r.LoadCommitCache())
+
+	if commits, err := r.RevList("master", ""); assert.NoError(t, err) {
+		assert.Equal(t, "feat", commits[0].Type)
+	}
+
+	// invalid caches are ignored
+	require.NoError(t, os.WriteFile(r.cachePath(), []byte("not json"), 0o600))
+
+	r, err = New(path)
+	require.NoError(t, err)
+	assert.NoError(t, r.LoadCommitCache())
+}
+
+func writeCache(t *testing.T, path string, data cacheData) {
+	t.Helper()
+
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+}
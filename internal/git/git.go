@@ -5,17 +5,28 @@
 package git
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/sassoftware/gotagger/internal/commit"
 )
 
+const (
+	head = "HEAD"
+
+	// defaultTagNamespace is where git stores tags.
+	defaultTagNamespace = "refs/tags/"
+)
+
 var (
 	errEmptyStart = errors.New("Must specify a start")
 )
@@ -24,7 +35,40 @@ var (
 type Commit struct {
 	commit.Commit
 	Hash    string
+	Parents []string
 	Changes []Change
+
+	// Author and AuthorEmail are the name and email address of the author.
+	Author      string
+	AuthorEmail string
+
+	// Committer and CommitterEmail are the name and email address of the
+	// committer.
+	Committer      string
+	CommitterEmail string
+
+	// Title is the first line of the commit message,
+	// even if it is not a conventional commit.
+	Title string
+
+	// ChangeID is the Gerrit Change-Id trailer of the commit message, if
+	// any, even if it is not a conventional commit.
+	ChangeID string
+
+	// Topic is the Gerrit topic that the commit merges, if it is a merge
+	// with a subject such as `Merge changes from topic "foo"`. Use
+	// Repository.Topics to find the topics of the commits that were merged.
+	Topic string
+
+	// PullRequest is the number of the pull request that the commit merged,
+	// if its subject is decorated with it the way Azure Repos or Bitbucket
+	// do, such as "Merged PR 1234: feat: add foo".
+	PullRequest int
+}
+
+// IsMerge returns true if c has more than one parent.
+func (c Commit) IsMerge() bool {
+	return len(c.Parents) > 1
 }
 
 type Change struct {
@@ -35,15 +79,75 @@ type Change struct {
 	DestMode   string
 	SourceSHA  string
 	DestSHA    string
+
+	// Insertions and Deletions are the number of lines the change adds and
+	// removes, as listed by git log --numstat. They are only set if
+	// Repository.ChangeStats returns true, and are -1 for binary files.
+	Insertions int
+	Deletions  int
+}
+
+// LineStats returns the number of lines that the changes of c add and
+// remove, not counting binary files. Both are zero unless
+// Repository.ChangeStats returns true.
+func (c Commit) LineStats() (insertions, deletions int) {
+	for _, change := range c.Changes {
+		if change.Insertions > 0 {
+			insertions += change.Insertions
+		}
+		if change.Deletions > 0 {
+			deletions += change.Deletions
+		}
+	}
+
+	return insertions, deletions
 }
 
 // Repository represents a git repository.
 type Repository struct {
 	GitDir string
-	Path   string
 
-	runner func([]string, string) (string, error)
+	// Path is the top-level directory of the worktree.
+	Path string
+
+	// TagNamespace returns the ref namespace that tags are listed from,
+	// created in, and pushed to, such as refs/releases/. If it is nil or
+	// returns the empty string, then tags are stored in refs/tags/.
+	TagNamespace func() string
+
+	// MergeChanges returns true if RevList lists the changes that merge
+	// commits make to their first parent. If it is nil or returns false,
+	// then merge commits have no changes, as in git log.
+	MergeChanges func() bool
+
+	// ChangeStats returns true if RevList counts the lines added and removed
+	// by each change, which is slower for large commits.
+	ChangeStats func() bool
+
+	// TypeAlias returns the commit type that typ is an alias of, such as
+	// "fix" for "bugfix". If it is nil or returns the empty string, then the
+	// type of each commit is used as it is.
+	TypeAlias func(typ string) string
+
+	// Offline returns true if git must not use the network. Every transport
+	// is then disabled, so that pushes fail, and the missing objects of
+	// partial clones are not fetched.
+	Offline func() bool
+
+	// TaggerDate returns the date that a tag of the commit hash records as
+	// the date it was created, in a format that git accepts, or the empty
+	// string for the current time.
+	TaggerDate func(hash string) (string, error)
+
+	// ParseMessage parses commit messages instead of the conventional commit
+	// parser. If it is nil or returns false, then messages are parsed as
+	// conventional commits. Messages that it parses are not cached.
+	ParseMessage func(message string) (commit.Commit, bool)
+
+	runner func([]string, string, []string) (string, error)
 	logger logr.Logger
+	cache  commitCache
+	batch  batchSession
 }
 
 // New returns a new git Repo. If path is not a git repo, then an error will be returned.
@@ -62,6 +166,18 @@ func New(path string) (*Repository, error) {
 		}
 	}
 
+	// run git from the top-level directory, so that paths are relative to
+	// the root of the repository, even if path is a subdirectory
+	if top, err := getTopLevel(path); err == nil && top != "" {
+		path = top
+	}
+
+	// go only supports paths longer than MAX_PATH on Windows if they are
+	// absolute
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
 	repo := &Repository{
 		GitDir: gitDir,
 		Path:   path,
@@ -72,6 +188,24 @@ func New(path string) (*Repository, error) {
 	return repo, nil
 }
 
+// TagRef returns the full ref name of the tag name, such as refs/tags/v1.0.0.
+func (r *Repository) TagRef(name string) string {
+	return r.tagNamespace() + name
+}
+
+// tagNamespace returns the ref namespace of tags, ending in a slash.
+func (r *Repository) tagNamespace() string {
+	if r.TagNamespace == nil {
+		return defaultTagNamespace
+	}
+
+	if ns := r.TagNamespace(); ns != "" {
+		return strings.TrimSuffix(ns, "/") + "/"
+	}
+
+	return defaultTagNamespace
+}
+
 // CreateTag tags a commit in a git repo.
 //
 // If prefix is a non-empty string, then the version will be prefixed with that string.
@@ -82,15 +216,119 @@ func (r *Repository) CreateTag(hash, name, message string, signed bool) error {
 		message = "Release " + name
 	}
 
+	if r.tagNamespace() != defaultTagNamespace {
+		if signed {
+			return fmt.Errorf("cannot sign tag %s: signed tags can only be created in %s", name, defaultTagNamespace)
+		}
+
+		// an empty old value means the tag must not already exist
+		return r.writeTag(hash, name, message, "")
+	}
+
 	args := []string{"tag"}
 	if signed {
 		r.logger.V(1).Info("signing tag")
 		args = append(args, "-s")
 	}
 
-	args = append(args, "-m", message, name, hash)
+	// keep lines starting with '#', such as markdown headings in release notes
+	args = append(args, "--cleanup=whitespace", "-m", message, name, hash)
+
+	env, err := r.tagEnv(hash)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.run(args)
+	_, err = r.runEnv(args, env)
+	return err
+}
+
+// tagEnv returns the environment that a tag of the commit hash is created
+// with, which sets its date if TaggerDate returns one.
+func (r *Repository) tagEnv(hash string) ([]string, error) {
+	if r.TaggerDate == nil {
+		return nil, nil
+	}
+
+	date, err := r.TaggerDate(hash)
+	if err != nil || date == "" {
+		return nil, err
+	}
+
+	return []string{"GIT_COMMITTER_DATE=" + date}, nil
+}
+
+// MoveTag points the annotated tag name at hash, creating it if it does not
+// exist, and returns the object the tag pointed to before, or the empty
+// string if it did not exist. Pass that object to RestoreTag to undo the move.
+func (r *Repository) MoveTag(hash, name, message string) (previous string, err error) {
+	r.logger.V(1).Info("moving tag", "tag", name)
+
+	out, err := r.run([]string{"for-each-ref", "--format=%(objectname)", r.TagRef(name)})
+	if err != nil {
+		return "", err
+	}
+	previous = strings.TrimSpace(out)
+
+	return previous, r.writeTag(hash, name, message, previous)
+}
+
+// RestoreTag points the tag name back at previous, as returned by MoveTag,
+// or deletes it if previous is empty.
+func (r *Repository) RestoreTag(name, previous string) error {
+	r.logger.V(1).Info("restoring tag", "tag", name)
+
+	if previous == "" {
+		_, err := r.run([]string{"update-ref", "-d", r.TagRef(name)})
+		return err
+	}
+
+	_, err := r.run([]string{"update-ref", "-m", "gotagger: restore tag " + name, r.TagRef(name), previous})
+	return err
+}
+
+// writeTag writes an annotated tag object for hash and points the tag name at
+// it, if the tag currently points at old. This works outside of refs/tags/,
+// unlike git tag.
+func (r *Repository) writeTag(hash, name, message, old string) error {
+	commit, err := r.CommitHash(hash)
+	if err != nil {
+		return err
+	}
+
+	env, err := r.tagEnv(commit)
+	if err != nil {
+		return err
+	}
+
+	tagger, err := r.runEnv([]string{"var", "GIT_COMMITTER_IDENT"}, env)
+	if err != nil {
+		return err
+	}
+
+	object := fmt.Sprintf("object %s\ntype commit\ntag %s\ntagger %s\n\n%s\n",
+		commit, name, strings.TrimSpace(tagger), strings.TrimSpace(message))
+
+	f, err := os.CreateTemp("", "gotagger-tag-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(object); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	tag, err := r.run([]string{"hash-object", "-t", "tag", "-w", f.Name()})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.run([]string{"update-ref", "-m", "gotagger: tag " + name, r.TagRef(name), strings.TrimSpace(tag), old})
 	return err
 }
 
@@ -98,7 +336,13 @@ func (r *Repository) DeleteTags(tags []string) error {
 	var errorMsg string
 	for _, tag := range tags {
 		r.logger.V(1).Info("deleting tag", "tag", tag)
-		if _, terr := r.run([]string{"tag", "-d", tag}); terr != nil {
+
+		args := []string{"tag", "-d", tag}
+		if r.tagNamespace() != defaultTagNamespace {
+			args = []string{"update-ref", "-d", r.TagRef(tag)}
+		}
+
+		if _, terr := r.run(args); terr != nil {
 			if errorMsg == "" {
 				errorMsg = "could not delete tags:"
 			}
@@ -113,17 +357,146 @@ func (r *Repository) DeleteTags(tags []string) error {
 	return nil
 }
 
+// CurrentBranch returns the short name of the branch HEAD points to. If HEAD
+// is detached, then the empty string is returned.
+func (r *Repository) CurrentBranch() (string, error) {
+	out, err := r.run([]string{"rev-parse", "--abbrev-ref", "HEAD"})
+	if err != nil {
+		return "", err
+	}
+
+	branch := strings.TrimSpace(out)
+	if branch == head {
+		return "", nil
+	}
+
+	return branch, nil
+}
+
+// DefaultBranch returns the name of the branch that the HEAD of remote points
+// to, such as "main" for refs/remotes/origin/HEAD. It is empty if the HEAD of
+// remote is not known, such as when the remote was added instead of cloned
+// and git remote set-head was not run.
+func (r *Repository) DefaultBranch(remote string) (string, error) {
+	ref := "refs/remotes/" + remote + "/HEAD"
+	if _, err := r.run([]string{"show-ref", "--verify", "--quiet", ref}); err != nil {
+		return "", nil
+	}
+
+	out, err := r.run([]string{"symbolic-ref", "--short", ref})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(strings.TrimSpace(out), remote+"/"), nil
+}
+
+// FindRefs returns the full names of existing refs that git could resolve
+// from any of the short names in names, for example refs/heads/v1.0.0 or
+// refs/tags/v1.0.0 for the name v1.0.0. Refs in the tag namespace are also
+// returned.
+func (r *Repository) FindRefs(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	namespaces := []string{"refs/", "refs/tags/", "refs/heads/", "refs/remotes/"}
+	if ns := r.tagNamespace(); ns != defaultTagNamespace {
+		namespaces = append(namespaces, ns)
+	}
+
+	want := map[string]struct{}{}
+	args := []string{"for-each-ref", "--format=%(refname)"}
+	for _, name := range names {
+		for _, namespace := range namespaces {
+			want[namespace+name] = struct{}{}
+			args = append(args, namespace+name)
+		}
+	}
+
+	out, err := r.run(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// for-each-ref patterns also match refs beneath the pattern,
+	// so only keep exact matches
+	var refs []string
+	for _, ref := range splitLines(out) {
+		if _, ok := want[ref]; ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs, nil
+}
+
 // Head returns the commit at HEAD
 func (r *Repository) Head() (c Commit, err error) {
-	r.logger.V(1).Info("getting HEAD commit")
-	out, err := r.run([]string{"show", "--format=raw", "--raw", "--no-abbrev", "HEAD"})
+	return r.CommitAt("HEAD")
+}
+
+// CommitAt returns the commit at rev, which may be any revision that names a
+// commit, such as a branch, tag, or hash.
+func (r *Repository) CommitAt(rev string) (c Commit, err error) {
+	r.logger.V(1).Info("getting commit", "rev", rev)
+	hash, err := r.CommitHash(rev)
+	if err != nil {
+		return Commit{}, err
+	}
+
+	out, err := r.run([]string{"show", "--format=raw", "--raw", "--no-abbrev", hash, "--"})
 	if err != nil {
 		return Commit{}, err
 	}
 
 	out = strings.TrimSpace(out)
 
-	return parseCommit(out), nil
+	return r.parseCommit(out), nil
+}
+
+// IsUnborn returns true if HEAD is a branch that has no commits yet, as in a
+// repository that was just initialized.
+func (r *Repository) IsUnborn() bool {
+	branch, err := r.run([]string{"symbolic-ref", "--quiet", "HEAD"})
+	if err != nil {
+		// a detached HEAD always points to a commit
+		return false
+	}
+
+	_, err = r.run([]string{"show-ref", "--verify", "--quiet", strings.TrimSpace(branch)})
+	return err != nil
+}
+
+// Upstream returns the name of the upstream branch of HEAD, and how many
+// commits HEAD is ahead of and behind it.
+func (r *Repository) Upstream() (upstream string, ahead, behind int, err error) {
+	r.logger.V(1).Info("comparing HEAD with upstream")
+	out, err := r.run([]string{"rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"})
+	if err != nil {
+		return "", 0, 0, err
+	}
+	upstream = strings.TrimSpace(out)
+
+	out, err = r.run([]string{"rev-list", "--count", "--left-right", "@{upstream}...HEAD"})
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	counts := strings.Fields(out)
+	if len(counts) != 2 {
+		return "", 0, 0, fmt.Errorf("unexpected rev-list output: %s", out)
+	}
+
+	if behind, err = strconv.Atoi(counts[0]); err != nil {
+		return "", 0, 0, err
+	}
+
+	if ahead, err = strconv.Atoi(counts[1]); err != nil {
+		return "", 0, 0, err
+	}
+
+	return upstream, ahead, behind, nil
 }
 
 // IsDirty returns a boolean indicating whether there are uncommited changes.
@@ -132,32 +505,204 @@ func (r *Repository) IsDirty() (bool, error) {
 	return out != "", err
 }
 
+// IsShallow returns true if the repository is a shallow clone.
+func (r *Repository) IsShallow() (bool, error) {
+	out, err := r.run([]string{"rev-parse", "--is-shallow-repository"})
+	return strings.TrimSpace(out) == "true", err
+}
+
+// RemoteURL returns the URL of the remote name, with any url.<base>.insteadOf
+// rewrites applied. It returns the empty string if there is no such remote.
+func (r *Repository) RemoteURL(name string) (string, error) {
+	out, err := r.run([]string{"remote"})
+	if err != nil {
+		return "", err
+	}
+
+	for _, remote := range strings.Fields(out) {
+		if remote == name {
+			out, err := r.run([]string{"remote", "get-url", name})
+			return strings.TrimSpace(out), err
+		}
+	}
+
+	return "", nil
+}
+
+// FetchTags fetches the tags in the tag namespace from remote. Tags that
+// already exist locally are not replaced. A shallow clone is unshallowed, so
+// that versions are calculated from the complete history.
+func (r *Repository) FetchTags(remote string) error {
+	shallow, err := r.IsShallow()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"fetch", "--no-tags"}
+	if shallow {
+		r.logger.V(1).Info("unshallowing repository")
+		args = append(args, "--unshallow")
+	}
+
+	refspec := r.TagRef("*")
+	args = append(args, remote, refspec+":"+refspec)
+
+	r.logger.V(1).Info("fetching tags", "remote", remote)
+	_, err = r.run(args)
+	return err
+}
+
 // PushTag pushes tag to remote.
 func (r *Repository) PushTag(tag string, remote string) error {
 	return r.PushTags([]string{tag}, remote)
 }
 
+// PushOptions control how tags are pushed.
+type PushOptions struct {
+	// FollowTags pushes with --follow-tags, which also pushes every annotated
+	// tag reachable from the pushed tags that the remote is missing. The push
+	// is refused if that would push any tags that are not being pushed
+	// explicitly.
+	FollowTags bool
+
+	// Options are passed to the remote as push options.
+	Options []string
+
+	// ForceTags are tags that replace the tags of the same name on the
+	// remote, such as floating alias tags.
+	ForceTags []string
+
+	// Token is used to authenticate HTTPS pushes, along with Username.
+	// It is sent in an Authorization header.
+	Token string
+
+	// Username is the user name to authenticate with when Token is set.
+	Username string
+}
+
 // PushTags pushes tags to the remote repository remote.
 func (r *Repository) PushTags(tags []string, remote string) error {
+	return r.PushTagsWithOptions(tags, remote, PushOptions{})
+}
+
+// PushTagsWithOptions pushes tags to the remote repository remote using opts.
+//
+// Tags are always pushed explicitly, never HEAD, so that pushing works from a
+// detached HEAD and does not push the current branch.
+func (r *Repository) PushTagsWithOptions(tags []string, remote string, opts PushOptions) error {
 	r.logger.V(1).Info("pushing tags", "tags", tags)
-	refSpecs := make([]string, len(tags))
-	for i, tag := range tags {
-		refname := "refs/tags/" + tag
-		refSpecs[i] = refname + ":" + refname
+
+	var refspecs []string
+	for _, tag := range tags {
+		refname := r.TagRef(tag)
+		refspecs = append(refspecs, refname+":"+refname)
+	}
+	for _, tag := range opts.ForceTags {
+		refname := r.TagRef(tag)
+		refspecs = append(refspecs, "+"+refname+":"+refname)
+	}
+
+	var env []string
+	if opts.Token != "" {
+		r.logger.V(1).Info("authenticating push with token", "username", opts.Username)
+		credentials := base64.StdEncoding.EncodeToString([]byte(opts.Username + ":" + opts.Token))
+		env = configEnv("http.extraHeader", "Authorization: Basic "+credentials)
 	}
 
-	args := append([]string{"push", remote}, refSpecs...)
-	_, err := r.run(args)
+	args := []string{"push"}
+	for _, o := range opts.Options {
+		args = append(args, "--push-option="+o)
+	}
+
+	if opts.FollowTags {
+		r.logger.V(1).Info("following tags")
+		if err := r.checkFollowedTags(remote, refspecs, env); err != nil {
+			return err
+		}
+		args = append(args, "--follow-tags")
+	}
+	args = append(append(args, remote), refspecs...)
+
+	_, err := r.runEnv(args, env)
 	return err
 }
 
+// checkFollowedTags returns an error if pushing refspecs to remote with
+// --follow-tags would also push refs that are not in refspecs, such as
+// unrelated annotated tags in the history of the tagged commits, since
+// those could not be taken back if the release fails.
+func (r *Repository) checkFollowedTags(remote string, refspecs []string, env []string) error {
+	args := append([]string{"push", "--dry-run", "--porcelain", "--follow-tags", remote}, refspecs...)
+	out, err := r.runEnv(args, env)
+	if err != nil {
+		return err
+	}
+
+	pushed := make(map[string]bool, len(refspecs))
+	for _, spec := range refspecs {
+		_, dst, _ := strings.Cut(spec, ":")
+		pushed[dst] = true
+	}
+
+	// new refs are listed as "*" TAB <from>:<to> TAB <summary>
+	var extra []string
+	for _, line := range splitLines(out) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] != "*" {
+			continue
+		}
+		if _, dst, _ := strings.Cut(fields[1], ":"); !pushed[dst] {
+			extra = append(extra, dst)
+		}
+	}
+
+	if len(extra) > 0 {
+		return fmt.Errorf("refusing to push with --follow-tags, which would also push %s", strings.Join(extra, ", "))
+	}
+
+	return nil
+}
+
+// RevListOptions bound the commits listed by RevListWithOptions, so that
+// callers can page through enormous histories.
+type RevListOptions struct {
+	// MaxCount is the most commits to list. Zero lists every commit.
+	MaxCount int
+
+	// Skip is the number of commits to skip before listing, such as the
+	// number already listed by earlier pages.
+	Skip int
+}
+
 // RevList returns a slice of commits from start to end.
 func (r *Repository) RevList(start, end string, paths ...string) ([]Commit, error) {
+	return r.RevListWithOptions(start, end, RevListOptions{}, paths...)
+}
+
+// RevListWithOptions returns a slice of commits from start to end, bounded by
+// opts. Commits are listed newest first, so the next page of commits is
+// listed by adding the MaxCount of a page to Skip.
+func (r *Repository) RevListWithOptions(start, end string, opts RevListOptions, paths ...string) ([]Commit, error) {
 	if start == "" {
 		return nil, errEmptyStart
 	}
+	if opts.MaxCount < 0 || opts.Skip < 0 {
+		return nil, fmt.Errorf("invalid commit limits: max count %d, skip %d", opts.MaxCount, opts.Skip)
+	}
 
 	args := []string{"log", "--format=raw", "--raw", "--no-abbrev", start}
+	if opts.MaxCount > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(opts.MaxCount))
+	}
+	if opts.Skip > 0 {
+		args = append(args, "--skip="+strconv.Itoa(opts.Skip))
+	}
+	if r.MergeChanges != nil && r.MergeChanges() {
+		args = append(args, "--diff-merges=first-parent")
+	}
+	if r.ChangeStats != nil && r.ChangeStats() {
+		args = append(args, "--numstat")
+	}
 
 	// add start and end refs
 	logger := r.logger.V(1).WithValues("start", start)
@@ -184,10 +729,125 @@ func (r *Repository) RevList(start, end string, paths ...string) ([]Commit, erro
 		return []Commit{}, nil
 	}
 
-	return parseCommits(string(out)), nil
+	return r.parseCommits(string(out)), nil
+}
+
+// Topics returns the Gerrit topics of the commits from start to end, by
+// hash. A commit is in a topic if a merge with a subject such as
+// `Merge changes from topic "foo"` brought it in, or if it is that merge. If a
+// commit was brought in by several topic merges, then the newest wins. If end
+// is empty, then every commit reachable from start is considered.
+func (r *Repository) Topics(start, end string) (map[string]string, error) {
+	if start == "" {
+		return nil, errEmptyStart
+	}
+
+	args := []string{"log", "--merges", "--format=%H %P%x00%s", start}
+	if end != "" {
+		args = append(args, "^"+end)
+	}
+
+	out, err := r.run(args)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make(map[string]string)
+	for _, line := range splitLines(out) {
+		revs, subject, _ := strings.Cut(line, "\x00")
+		topic := commit.Topic(subject)
+		hashes := strings.Fields(revs)
+		if topic == "" || len(hashes) < 3 {
+			continue
+		}
+
+		// the merge itself, and the commits of its second parent that
+		// the first parent does not have
+		merged := []string{hashes[0]}
+		listArgs := []string{"rev-list", hashes[2], "^" + hashes[1]}
+		if end != "" {
+			listArgs = append(listArgs, "^"+end)
+		}
+		out, err := r.run(listArgs)
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, splitLines(out)...)
+
+		for _, hash := range merged {
+			if _, ok := topics[hash]; !ok {
+				topics[hash] = topic
+			}
+		}
+	}
+
+	return topics, nil
+}
+
+// IsAncestor returns true if the commit ancestor is an ancestor of, or the
+// same commit as, rev.
+func (r *Repository) IsAncestor(ancestor, rev string) (bool, error) {
+	hash, err := r.CommitHash(ancestor)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := r.run([]string{"merge-base", "--all", hash, rev})
+	if err != nil {
+		// there is no merge base if the histories are unrelated
+		return false, nil
+	}
+
+	for _, base := range strings.Fields(out) {
+		if base == hash {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CommitHash returns the hash of the commit that rev points to. Annotated tags
+// are followed to the object they tag, including tags of other tags. An error
+// is returned if rev ultimately points to an object that is not a commit, such
+// as a tree or blob.
+func (r *Repository) CommitHash(rev string) (string, error) {
+	hash, typ, err := r.peel(rev)
+	if err != nil {
+		return "", err
+	}
+
+	if typ != "commit" {
+		return "", fmt.Errorf("%s points to a %s, not a commit", rev, typ)
+	}
+
+	return hash, nil
+}
+
+// peel returns the hash and type of the object that rev points to, following
+// annotated tags until an object that is not a tag is found.
+func (r *Repository) peel(rev string) (hash, typ string, err error) {
+	if hash, typ, ok, err := r.objectInfo(rev + "^{}"); ok {
+		return hash, typ, err
+	}
+
+	if hash, err = r.RevParse(rev + "^{}"); err != nil {
+		return "", "", err
+	}
+
+	out, err := r.run([]string{"cat-file", "-t", hash})
+	if err != nil {
+		return "", "", err
+	}
+
+	return hash, strings.TrimSpace(out), nil
 }
 
 func (r *Repository) RevParse(rev string) (string, error) {
+	if hash, _, ok, err := r.objectInfo(rev); ok {
+		return hash, err
+	}
+
 	out, err := r.run([]string{"rev-parse", rev})
 	if err != nil {
 		return "", err
@@ -196,27 +856,117 @@ func (r *Repository) RevParse(rev string) (string, error) {
 	return strings.TrimSpace(out), nil
 }
 
+// TagDate returns the date tag was created. For lightweight tags this is the
+// committer date of the tagged commit.
+func (r *Repository) TagDate(tag string) (time.Time, error) {
+	out, err := r.run([]string{"for-each-ref", "--format=%(creatordate:iso-strict)", r.TagRef(tag)})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return time.Time{}, fmt.Errorf("tag %s not found", tag)
+	}
+
+	return time.Parse(time.RFC3339, out)
+}
+
+// AuthorDate returns the date that the commit rev was authored.
+func (r *Repository) AuthorDate(rev string) (time.Time, error) {
+	return r.commitDate(rev, "%aI")
+}
+
+// CommitDate returns the date that the commit rev was committed.
+func (r *Repository) CommitDate(rev string) (time.Time, error) {
+	return r.commitDate(rev, "%cI")
+}
+
+// commitDate returns the date of the commit rev printed by the log format
+// placeholder format.
+func (r *Repository) commitDate(rev, format string) (time.Time, error) {
+	out, err := r.run([]string{"log", "-1", "--format=" + format, rev, "--"})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, strings.TrimSpace(out))
+}
+
+// TagMessage returns the message of the annotated tag, without its signature.
+// An error is returned if tag does not exist or is a lightweight tag.
+func (r *Repository) TagMessage(tag string) (string, error) {
+	out, err := r.run([]string{"for-each-ref", "--format=%(objecttype)%00%(contents)%00%(contents:signature)", r.TagRef(tag)})
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.SplitN(out, "\x00", 3)
+	if len(fields) != 3 {
+		return "", fmt.Errorf("tag %s not found", tag)
+	}
+
+	if fields[0] != "tag" {
+		return "", fmt.Errorf("tag %s is not an annotated tag", tag)
+	}
+
+	// for-each-ref ends each ref with a newline
+	signature := strings.TrimSuffix(fields[2], "\n")
+
+	return strings.TrimSpace(strings.TrimSuffix(fields[1], signature)), nil
+}
+
+// VerifyTag verifies the signature of tag. An error is returned if the tag is
+// not signed, or the signature is not valid or not trusted.
+func (r *Repository) VerifyTag(tag string) error {
+	r.logger.V(1).Info("verifying tag", "tag", tag)
+	_, err := r.run([]string{"verify-tag", r.TagRef(tag)})
+	return err
+}
+
 // SetLogger updates the Repository's internal logger.
 func (r *Repository) SetLogger(l logr.Logger) {
 	r.logger = l
 }
 
-// Tags returns all tags that point to ancestors of rev.
+// Tags returns all tags that point to ancestors of rev, sorted by version
+// from highest to lowest.
 //
 // rev can be either a revision or a hash.
 //
 // prefix is a string prefix to filter tags with.
 func (r *Repository) Tags(rev string, prefixes ...string) (tags []string, err error) {
-	// list all tags that point to ancestors of rev
-	args := []string{"tag", "--merged", rev}
+	return r.LatestTags(rev, 0, prefixes...)
+}
+
+// LatestTags returns at most n tags that point to ancestors of rev, sorted by
+// version from highest to lowest. If n is 0, then all tags are returned.
+// If rev is empty, then tags are returned whatever they point to.
+//
+// Sorting and limiting is done by git for-each-ref using its version sort,
+// so repositories with many tags do not need to read every tag.
+//
+// prefix is a string prefix to filter tags with.
+func (r *Repository) LatestTags(rev string, n int, prefixes ...string) (tags []string, err error) {
+	// list tags that point to ancestors of rev, highest version first
+	// versionsort.suffix sorts pre-releases, such as v1.0.0-rc.1, below
+	// their release instead of above it
+	ns := r.tagNamespace()
+	args := []string{"-c", "versionsort.suffix=-", "for-each-ref", "--sort=-v:refname", r.tagNameFormat()}
+	if rev != "" {
+		args = append(args, "--merged", rev)
+	}
+	if n > 0 {
+		args = append(args, "--count="+strconv.Itoa(n))
+	}
 	if len(prefixes) > 0 {
-		args = append(args, "--list")
 		for _, p := range prefixes {
-			args = append(args, p+"*")
+			args = append(args, ns+p+"*")
 		}
-		r.logger.V(1).Info("getting tags matching prefixes", "from", rev, "prefixes", strings.Join(prefixes, ", "))
+		r.logger.V(1).Info("getting tags matching prefixes", "from", rev, "prefixes", strings.Join(prefixes, ", "), "limit", n)
 	} else {
-		r.logger.V(1).Info("getting tags", "from", rev)
+		args = append(args, ns)
+		r.logger.V(1).Info("getting tags", "from", rev, "limit", n)
 	}
 
 	out, err := r.run(args)
@@ -224,29 +974,146 @@ func (r *Repository) Tags(rev string, prefixes ...string) (tags []string, err er
 		return
 	}
 
-	out = strings.TrimSpace(out)
-	if out != "" {
-		// no tags found
-		tags = strings.Split(string(out), "\n")
+	return splitLines(out), nil
+}
+
+// TagsAt returns the tags that point to the commit rev, sorted by name.
+// Annotated tags are followed to the commit they tag, including tags of other
+// tags, which git for-each-ref --points-at does not follow.
+func (r *Repository) TagsAt(rev string) (tags []string, err error) {
+	r.logger.V(1).Info("getting tags that point at", "rev", rev)
+
+	hash, err := r.CommitHash(rev)
+	if err != nil {
+		return nil, err
 	}
 
-	return
+	// for annotated tags, %(*objectname) is the object the tag points to,
+	// which is only another tag for nested tags
+	ns := r.tagNamespace()
+	format := "--format=%(objectname) %(*objectname) %(*objecttype) %(refname)"
+	out, err := r.run([]string{"for-each-ref", format, ns})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range splitLines(out) {
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		object, peeled, typ, ref := fields[0], fields[1], fields[2], fields[3]
+
+		switch {
+		case typ == "tag":
+			if object, err = r.CommitHash(ref); err != nil {
+				// nested tags of trees and blobs do not point to commits
+				continue
+			}
+		case peeled != "":
+			object = peeled
+		}
+
+		if object == hash {
+			tags = append(tags, strings.TrimPrefix(ref, ns))
+		}
+	}
+	sort.Strings(tags)
+
+	return tags, nil
+}
+
+// tagNameFormat returns the for-each-ref format that prints the names of
+// tags without their namespace.
+func (r *Repository) tagNameFormat() string {
+	return "--format=%(refname:lstrip=" + strconv.Itoa(strings.Count(r.tagNamespace(), "/")) + ")"
+}
+
+// splitLines returns the lines of the output of a git command, without empty
+// lines, so that empty output is an empty list instead of a list of one empty
+// string.
+func splitLines(out string) []string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
 }
 
 func (r *Repository) run(args []string) (string, error) {
-	args = append([]string{"--git-dir", r.GitDir}, args...)
+	return r.runEnv(args, nil)
+}
+
+// runEnv runs a git command with extra environment variables. Values in env
+// are not logged.
+func (r *Repository) runEnv(args []string, env []string) (string, error) {
+	args = append(append(r.offlineArgs(), "--git-dir", r.GitDir), args...)
 	r.logger.V(1).Info("running git command", "args", strings.Join(args, " "))
-	return r.runner(args, r.Path)
+	return r.runner(args, r.Path, append(r.offlineEnv(), env...))
+}
+
+// offlineArgs returns the git options that disable every transport if
+// r.Offline returns true.
+func (r *Repository) offlineArgs() []string {
+	if r.Offline == nil || !r.Offline() {
+		return nil
+	}
+
+	return []string{"-c", "protocol.allow=never"}
+}
+
+// offlineEnv returns the environment variables that stop git from fetching
+// the missing objects of partial clones if r.Offline returns true.
+func (r *Repository) offlineEnv() []string {
+	if r.Offline == nil || !r.Offline() {
+		return nil
+	}
+
+	return []string{"GIT_NO_LAZY_FETCH=1"}
+}
+
+// configEnv returns environment variables that set git configuration values
+// without exposing them on the command line.
+func configEnv(keyValues ...string) []string {
+	// append to any configuration already set in the environment
+	count, err := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	if err != nil {
+		count = 0
+	}
+
+	var env []string
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, keyValues[i]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, keyValues[i+1]),
+		)
+		count++
+	}
+
+	return append(env, "GIT_CONFIG_COUNT="+strconv.Itoa(count))
 }
 
 func getGitDirectory(path string) (string, error) {
-	out, err := runGitCommand([]string{"rev-parse", "--git-dir"}, path)
+	out, err := runGitCommand([]string{"rev-parse", "--git-dir"}, path, nil)
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(out)), nil
 }
 
+// getTopLevel returns the top-level directory of the worktree containing path.
+// It returns an error for bare repositories.
+func getTopLevel(path string) (string, error) {
+	out, err := runGitCommand([]string{"rev-parse", "--show-toplevel"}, path, nil)
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(out)), nil
+}
+
 // hasPrefix returns true if t has a prefix that matches any prefixes.
 // The empty string matches if t has no prefix.
 func hasPrefix(t string, prefixes []string) bool {
@@ -265,6 +1132,15 @@ func hasPrefix(t string, prefixes []string) bool {
 }
 
 func parseChanges(lines []string) []Change {
+	// --numstat lines follow the --raw lines, in the same order
+	var stats []string
+	for i, line := range lines {
+		if !strings.HasPrefix(line, ":") {
+			lines, stats = lines[:i], lines[i:]
+			break
+		}
+	}
+
 	changes := make([]Change, len(lines))
 	for i, line := range lines {
 		parts := strings.Split(line, "\t")
@@ -277,11 +1153,15 @@ func parseChanges(lines []string) []Change {
 			SourceSHA:  parts[2],
 			DestSHA:    parts[3],
 			Action:     parts[4],
-			SourceName: files[0],
+			SourceName: unquotePath(files[0]),
 		}
 
 		if len(files) > 1 {
-			c.DestName = files[1]
+			c.DestName = unquotePath(files[1])
+		}
+
+		if i < len(stats) {
+			c.Insertions, c.Deletions = parseNumStat(stats[i])
 		}
 
 		changes[i] = c
@@ -290,7 +1170,43 @@ func parseChanges(lines []string) []Change {
 	return changes
 }
 
-func parseCommit(data string) Commit {
+// unquotePath returns the path name as it is in the repository. Even with
+// core.quotepath off, git quotes paths that contain control characters,
+// double quotes, or backslashes, and escapes those characters as in C.
+func unquotePath(name string) string {
+	if len(name) < 2 || name[0] != '"' || name[len(name)-1] != '"' {
+		return name
+	}
+
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		return unquoted
+	}
+
+	return name
+}
+
+// parseNumStat returns the insertions and deletions of a --numstat line,
+// such as "3\t1\tfoo.go". Binary files, listed as "-\t-\tfoo.png",
+// have -1 of each.
+func parseNumStat(line string) (insertions, deletions int) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) < 2 {
+		return 0, 0
+	}
+
+	insertions, err := strconv.Atoi(fields[0])
+	if err != nil {
+		insertions = -1
+	}
+	deletions, err = strconv.Atoi(fields[1])
+	if err != nil {
+		deletions = -1
+	}
+
+	return insertions, deletions
+}
+
+func (r *Repository) parseCommit(data string) Commit {
 	// strip the leading 'commit '
 	data = strings.TrimPrefix(data, "commit ")
 
@@ -310,31 +1226,92 @@ func parseCommit(data string) Commit {
 	message = strings.TrimSpace(message)
 	message = strings.ReplaceAll(message, "\n    ", "\n")
 
-	// parse the commit message
+	// the first header line is the hash, followed by tree, parent,
+	// author, and committer lines
+	headerLines := strings.Split(headers, "\n")
+	hash := headerLines[0]
+	var parents []string
+	var author, email, committer, committerEmail string
+	for _, line := range headerLines[1:] {
+		if parent, ok := strings.CutPrefix(line, "parent "); ok {
+			parents = append(parents, parent)
+		} else if ident, ok := strings.CutPrefix(line, "author "); ok {
+			author, email = parseIdent(ident)
+		} else if ident, ok := strings.CutPrefix(line, "committer "); ok {
+			committer, committerEmail = parseIdent(ident)
+		}
+	}
+
+	title, _, _ := strings.Cut(message, "\n")
+	_, pullRequest := commit.PullRequest(title)
+
+	// parse the commit message. aliases are applied after caching, so that
+	// the cache does not depend on the configuration
+	parsed := r.parseMessage(hash, message)
+	if r.TypeAlias != nil {
+		if typ := r.TypeAlias(parsed.Type); typ != "" {
+			parsed.Type = typ
+		}
+	}
+
 	return Commit{
-		Commit:  commit.Parse(message),
-		Hash:    strings.Split(headers, "\n")[0],
-		Changes: changes,
+		Commit:         parsed,
+		Hash:           hash,
+		Parents:        parents,
+		Changes:        changes,
+		Author:         author,
+		AuthorEmail:    email,
+		Committer:      committer,
+		CommitterEmail: committerEmail,
+		Title:          title,
+		ChangeID:       commit.ChangeID(message),
+		Topic:          commit.Topic(title),
+		PullRequest:    pullRequest,
+	}
+}
+
+// parseIdent splits an author or committer line of a raw commit,
+// such as "Jane Doe <jane@example.com> 1700000000 +0000",
+// into the name and email address.
+func parseIdent(ident string) (name, email string) {
+	start := strings.LastIndex(ident, "<")
+	end := strings.LastIndex(ident, ">")
+	if start < 0 || end < start {
+		return strings.TrimSpace(ident), ""
 	}
+
+	return strings.TrimSpace(ident[:start]), ident[start+1 : end]
 }
 
-func parseCommits(data string) (commits []Commit) {
+func (r *Repository) parseCommits(data string) (commits []Commit) {
 	// split on \ncommit to separate the raw output into raw commits
 	rawCommits := strings.Split(data, "\ncommit ")
 	for _, rawCommit := range rawCommits {
-		commits = append(commits, parseCommit(rawCommit))
+		commits = append(commits, r.parseCommit(rawCommit))
 	}
 
 	return
 }
 
-func runGitCommand(args []string, path string) (string, error) {
-	c := exec.Command("git", args...)
+// gitArgs returns args preceded by the options that every git command is run
+// with.
+func gitArgs(args ...string) []string {
+	// print paths with non-ASCII characters as they are, instead of quoted
+	opts := append([]string{"-c", "core.quotepath=off"}, platformArgs...)
+	return append(opts, args...)
+}
+
+func runGitCommand(args []string, path string, env []string) (string, error) {
+	// keep the output the same whatever the locale of the user
+	c := exec.Command("git", gitArgs(args...)...)
 
 	if path != "" {
 		c.Dir = path
 	}
 
+	c.Env = append(os.Environ(), "LC_ALL=C")
+	c.Env = append(c.Env, env...)
+
 	out, err := c.Output()
 	if err != nil {
 		if err, ok := err.(*exec.ExitError); ok {
@@ -0,0 +1,9 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package git
+
+// platformArgs are the git options that are only needed on some platforms.
+var platformArgs []string
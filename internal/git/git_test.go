@@ -4,12 +4,17 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
+	ggit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-logr/logr"
 	"github.com/sassoftware/gotagger/internal/testutils"
 	"github.com/stretchr/testify/assert"
@@ -40,20 +45,20 @@ func TestCreateTag(t *testing.T) {
 		want    []string
 	}{
 		{
-			want: []string{"--git-dir", ".git", "tag", "-m", "Release v1.0.0", "v1.0.0", "hash"},
+			want: []string{"--git-dir", ".git", "tag", "--cleanup=whitespace", "-m", "Release v1.0.0", "v1.0.0", "hash"},
 		},
 		{
 			message: "message",
-			want:    []string{"--git-dir", ".git", "tag", "-m", "message", "v1.0.0", "hash"},
+			want:    []string{"--git-dir", ".git", "tag", "--cleanup=whitespace", "-m", "message", "v1.0.0", "hash"},
 		},
 		{
 			message: "message",
 			signed:  true,
-			want:    []string{"--git-dir", ".git", "tag", "-s", "-m", "message", "v1.0.0", "hash"},
+			want:    []string{"--git-dir", ".git", "tag", "-s", "--cleanup=whitespace", "-m", "message", "v1.0.0", "hash"},
 		},
 		{
 			signed: true,
-			want:   []string{"--git-dir", ".git", "tag", "-s", "-m", "Release v1.0.0", "v1.0.0", "hash"},
+			want:   []string{"--git-dir", ".git", "tag", "-s", "--cleanup=whitespace", "-m", "Release v1.0.0", "v1.0.0", "hash"},
 		},
 	}
 
@@ -68,6 +73,29 @@ func TestCreateTag(t *testing.T) {
 	}
 }
 
+func TestFindRefs(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	// create a branch that looks like a version
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("v1.1.0"), head.Hash())))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("v2.0.0/feature"), head.Hash())))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if got, err := r.FindRefs([]string{"v1.0.0", "v1.1.0", "v2.0.0"}); assert.NoError(t, err) {
+		assert.Equal(t, []string{"refs/heads/v1.1.0", "refs/tags/v1.0.0"}, got)
+	}
+
+	if got, err := r.FindRefs([]string{"v3.0.0"}); assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
 func TestHead(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
@@ -96,6 +124,22 @@ func TestHead_one_commit(t *testing.T) {
 	}
 }
 
+func TestCommitAt(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if c, err := r.CommitAt("v1.0.0"); assert.NoError(t, err) {
+		assert.Equal(t, "feat: more foo", c.Message())
+	}
+
+	_, err = r.CommitAt("missing")
+	assert.Error(t, err)
+}
+
 func TestIsDirty(t *testing.T) {
 	t.Parallel()
 
@@ -170,6 +214,125 @@ func TestIsDirty(t *testing.T) {
 	})
 }
 
+func TestIsShallow(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if got, err := r.IsShallow(); assert.NoError(t, err) {
+		assert.False(t, got)
+	}
+
+	clone := t.TempDir()
+	out, err := exec.Command("git", "clone", "--quiet", "--depth", "1", "file://"+path, clone).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	r, err = New(clone)
+	require.NoError(t, err)
+
+	if got, err := r.IsShallow(); assert.NoError(t, err) {
+		assert.True(t, got)
+	}
+}
+
+func TestFetchTags(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	clone := t.TempDir()
+	out, err := exec.Command("git", "clone", "--quiet", "--depth", "1", "--no-tags", "file://"+path, clone).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	r, err := New(clone)
+	require.NoError(t, err)
+
+	if assert.NoError(t, r.FetchTags("origin")) {
+		if tags, err := r.Tags(""); assert.NoError(t, err) {
+			assert.Contains(t, tags, "v1.0.0")
+		}
+		if got, err := r.IsShallow(); assert.NoError(t, err) {
+			assert.False(t, got)
+		}
+	}
+}
+
+func TestIsUnborn(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	assert.True(t, r.IsUnborn())
+
+	h := testutils.CommitFile(t, repo, path, "foo.txt", "feat: adding a foo", []byte("foo\n"))
+	assert.False(t, r.IsUnborn())
+
+	// a detached HEAD is never unborn
+	out, err := exec.Command("git", "-C", path, "checkout", "--quiet", "--detach", h.String()).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.False(t, r.IsUnborn())
+}
+
+func TestUpstream(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	// no upstream
+	_, _, _, err = r.Upstream()
+	assert.Error(t, err)
+
+	testutils.NewRemote(t, repo)
+
+	if upstream, ahead, behind, err := r.Upstream(); assert.NoError(t, err) {
+		assert.Equal(t, "origin/master", upstream)
+		assert.Equal(t, 0, ahead)
+		assert.Equal(t, 0, behind)
+	}
+
+	testutils.CommitFile(t, repo, path, "ahead", "feat: ahead", []byte("ahead\n"))
+	testutils.CommitFile(t, repo, path, "ahead", "feat: more ahead", []byte("more ahead\n"))
+
+	if _, ahead, behind, err := r.Upstream(); assert.NoError(t, err) {
+		assert.Equal(t, 2, ahead)
+		assert.Equal(t, 0, behind)
+	}
+}
+
+func TestDefaultBranch(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	// no remote HEAD
+	testutils.NewRemote(t, repo)
+	if branch, err := r.DefaultBranch("origin"); assert.NoError(t, err) {
+		assert.Equal(t, "", branch)
+	}
+
+	out, err := exec.Command("git", "-C", path, "remote", "set-head", "origin", "master").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	if branch, err := r.DefaultBranch("origin"); assert.NoError(t, err) {
+		assert.Equal(t, "master", branch)
+	}
+
+	// unknown remote
+	if branch, err := r.DefaultBranch("upstream"); assert.NoError(t, err) {
+		assert.Equal(t, "", branch)
+	}
+}
+
 func TestPushTags(t *testing.T) {
 	wantArgs := []string{"--git-dir", ".git", "push", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0"}
 	wantPath := "path"
@@ -177,6 +340,150 @@ func TestPushTags(t *testing.T) {
 	_ = r.PushTags([]string{"v1.0.0"}, "origin")
 }
 
+func TestPushTagsWithOptions(t *testing.T) {
+	tests := []struct {
+		opts   PushOptions
+		dryRun []string
+		want   []string
+	}{
+		{
+			want: []string{"--git-dir", ".git", "push", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0"},
+		},
+		{
+			opts: PushOptions{Options: []string{"ci.skip", "merge_request.create"}},
+			want: []string{"--git-dir", ".git", "push", "--push-option=ci.skip", "--push-option=merge_request.create", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0"},
+		},
+		{
+			opts:   PushOptions{FollowTags: true, Options: []string{"ci.skip"}},
+			dryRun: []string{"--git-dir", ".git", "push", "--dry-run", "--porcelain", "--follow-tags", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0"},
+			want:   []string{"--git-dir", ".git", "push", "--push-option=ci.skip", "--follow-tags", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0"},
+		},
+		{
+			opts: PushOptions{ForceTags: []string{"v1"}},
+			want: []string{"--git-dir", ".git", "push", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0", "+refs/tags/v1:refs/tags/v1"},
+		},
+		{
+			opts:   PushOptions{FollowTags: true, ForceTags: []string{"v1"}},
+			dryRun: []string{"--git-dir", ".git", "push", "--dry-run", "--porcelain", "--follow-tags", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0", "+refs/tags/v1:refs/tags/v1"},
+			want:   []string{"--git-dir", ".git", "push", "--follow-tags", "origin", "refs/tags/v1.0.0:refs/tags/v1.0.0", "+refs/tags/v1:refs/tags/v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.want), func(t *testing.T) {
+			want := [][]string{tt.want}
+			if tt.dryRun != nil {
+				want = [][]string{tt.dryRun, tt.want}
+			}
+			r := &Repository{GitDir: ".git", Path: "path", runner: mockRunGitCommands(t, want, "path"), logger: logr.Discard()}
+			_ = r.PushTagsWithOptions([]string{"v1.0.0"}, "origin", tt.opts)
+		})
+	}
+}
+
+func TestPushTagsWithOptions_token(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+
+	var gotEnv []string
+	r := &Repository{GitDir: ".git", Path: "path", logger: logr.Discard()}
+	r.runner = func(args []string, path string, env []string) (string, error) {
+		gotEnv = env
+		return "", nil
+	}
+
+	require.NoError(t, r.PushTagsWithOptions([]string{"v1.0.0"}, "origin", PushOptions{Token: "secret", Username: "user"}))
+	assert.Equal(t, []string{
+		"GIT_CONFIG_KEY_1=http.extraHeader",
+		"GIT_CONFIG_VALUE_1=Authorization: Basic dXNlcjpzZWNyZXQ=",
+		"GIT_CONFIG_COUNT=2",
+	}, gotEnv)
+}
+
+func TestPushTags_offline(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+	testutils.NewRemote(t, repo)
+
+	r, err := New(path)
+	require.NoError(t, err)
+	r.Offline = func() bool { return true }
+
+	head, err := r.Head()
+	require.NoError(t, err)
+	require.NoError(t, r.CreateTag(head.Hash, "v1.0.1", "", false))
+
+	// the local remote is a transport too
+	if err := r.PushTags([]string{"v1.0.1"}, "origin"); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "transport 'file' not allowed")
+	}
+
+	// commands that do not use the network still work
+	if tags, err := r.Tags(head.Hash); assert.NoError(t, err) {
+		assert.Contains(t, tags, "v1.0.1")
+	}
+}
+
+func TestPushTagsWithOptions_detached(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+	remote, _ := testutils.NewRemote(t, repo)
+	testutils.CommitFile(t, repo, path, "foo", "feat: not pushed", []byte("not pushed\n"))
+
+	out, err := exec.Command("git", "-C", path, "checkout", "--quiet", "--detach").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	r, err := New(path)
+	require.NoError(t, err)
+	require.NoError(t, r.PushTags([]string{"v0.1.0", "v1.0.0"}, "origin"))
+
+	head, err := r.Head()
+	require.NoError(t, err)
+	require.NoError(t, r.CreateTag(head.Hash, "v1.1.0", "Release v1.1.0", false))
+
+	require.NoError(t, r.PushTagsWithOptions([]string{"v1.1.0"}, "origin", PushOptions{FollowTags: true}))
+
+	// the tag is pushed, but the branch is not
+	_, err = remote.Tag("v1.1.0")
+	assert.NoError(t, err)
+	branch, err := remote.Reference(plumbing.NewBranchReferenceName("master"), true)
+	require.NoError(t, err)
+	assert.NotEqual(t, head.Hash, branch.Hash().String())
+}
+
+func TestPushTagsWithOptions_followTags_unrelated(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+	remote, _ := testutils.NewRemote(t, repo)
+
+	r, err := New(path)
+	require.NoError(t, err)
+	require.NoError(t, r.PushTags([]string{"v0.1.0", "v1.0.0"}, "origin"))
+
+	// an annotated tag that was never meant to be pushed
+	head, err := r.Head()
+	require.NoError(t, err)
+	require.NoError(t, r.CreateTag(head.Hash, "wip-experiment", "work in progress", false))
+	testutils.CommitFile(t, repo, path, "foo", "feat: release", []byte("release\n"))
+
+	head, err = r.Head()
+	require.NoError(t, err)
+	require.NoError(t, r.CreateTag(head.Hash, "v1.1.0", "Release v1.1.0", false))
+
+	err = r.PushTagsWithOptions([]string{"v1.1.0"}, "origin", PushOptions{FollowTags: true})
+	assert.EqualError(t, err, "refusing to push with --follow-tags, which would also push refs/tags/wip-experiment")
+
+	// nothing is pushed
+	for _, tag := range []string{"v1.1.0", "wip-experiment"} {
+		_, err = remote.Tag(tag)
+		assert.ErrorIs(t, err, ggit.ErrTagNotFound, tag)
+	}
+
+	// the release tags alone are pushed as usual
+	require.NoError(t, r.PushTagsWithOptions([]string{"v1.1.0"}, "origin", PushOptions{}))
+	_, err = remote.Tag("v1.1.0")
+	assert.NoError(t, err)
+}
+
 func TestPushTag_no_remote(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
@@ -273,119 +580,682 @@ func TestRevList(t *testing.T) {
 	}
 }
 
-func TestRevList_one_commit(t *testing.T) {
-	assert := assert.New(t)
-	require := require.New(t)
-
+func TestRevListWithOptions(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
-	testutils.CommitFile(t, repo, path, "foo", "add foo", []byte("contents"))
+	testutils.SimpleGitRepo(t, repo, path)
 
 	r, err := New(path)
-	require.NoError(err)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if commits, err := r.RevList("HEAD", ""); assert.NoError(err) {
-		assert.Equal(1, len(commits))
+	all, err := r.RevList("HEAD", "")
+	if !assert.NoError(t, err) || !assert.Len(t, all, 3) {
+		return
 	}
 
-	if _, err := r.RevList("HEAD", "HEAD~1"); assert.Error(err) {
-		assert.Contains(err.Error(), "bad revision '^HEAD~1")
+	// page through the commits two at a time
+	var paged []Commit
+	for opts := (RevListOptions{MaxCount: 2}); ; opts.Skip += opts.MaxCount {
+		page, err := r.RevListWithOptions("HEAD", "", opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if len(page) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(page), opts.MaxCount)
+		paged = append(paged, page...)
+	}
+	assert.Equal(t, all, paged)
+
+	// limits apply after filtering by path
+	if commits, err := r.RevListWithOptions("HEAD", "", RevListOptions{MaxCount: 1}, "foo"); assert.NoError(t, err) {
+		assert.Len(t, commits, 1)
 	}
+	if commits, err := r.RevListWithOptions("HEAD", "", RevListOptions{Skip: 1}, "foo"); assert.NoError(t, err) {
+		assert.Len(t, commits, 1)
+	}
+
+	_, err = r.RevListWithOptions("HEAD", "", RevListOptions{MaxCount: -1})
+	assert.EqualError(t, err, "invalid commit limits: max count -1, skip 0")
 }
 
-func TestRevList_empty_repo(t *testing.T) {
-	assert := assert.New(t)
-	require := require.New(t)
+func TestRevList_merge(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
 
-	_, path := testutils.NewGitRepo(t)
+	base := testutils.CommitFile(t, repo, path, "foo", "feat: foo", []byte("foo"))
+	side := testutils.CommitFile(t, repo, path, "bar", "feat: bar", []byte("bar"))
+
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, w.Reset(&ggit.ResetOptions{Commit: base, Mode: ggit.HardReset}))
+	head := testutils.CommitFile(t, repo, path, "foo", "fix: foo", []byte("more foo"))
+	testutils.MergeFiles(t, repo, path, "Merge branch 'bar'", side, []testutils.FileCommit{{Path: "bar", Contents: []byte("bar")}})
 
 	r, err := New(path)
-	require.NoError(err)
+	require.NoError(t, err)
 
-	if _, err := r.RevList("HEAD", ""); assert.Error(err) {
-		assert.Contains(err.Error(), "unknown revision")
+	commits, err := r.RevList("HEAD", "HEAD^")
+	require.NoError(t, err)
+	if assert.Len(t, commits, 2) {
+		assert.True(t, commits[0].IsMerge())
+		assert.Equal(t, []string{head.String(), side.String()}, commits[0].Parents)
+		assert.Empty(t, commits[0].Changes)
+		assert.False(t, commits[1].IsMerge())
 	}
 
-	if _, err := r.RevList("HEAD", "HEAD^"); assert.Error(err) {
-		assert.Contains(err.Error(), "unknown revision")
+	// merge commits list the changes they make to their first parent
+	r.MergeChanges = func() bool { return true }
+	commits, err = r.RevList("HEAD", "HEAD^")
+	require.NoError(t, err)
+	if assert.Len(t, commits, 2) && assert.Len(t, commits[0].Changes, 1) {
+		assert.Equal(t, "bar", commits[0].Changes[0].SourceName)
 	}
 }
 
-func TestRevList_empty_start(t *testing.T) {
+func TestRevList_gerrit(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
-	testutils.SimpleGitRepo(t, repo, path)
+	testutils.CommitFile(t, repo, path, "foo", "feat: foo\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940", []byte("foo"))
 
 	r, err := New(path)
-	if err != nil {
-		t.Fatal(err)
-	}
+	require.NoError(t, err)
 
-	_, err = r.RevList("", "")
-	if got, want := err, errEmptyStart; got != want {
-		t.Errorf("RevList(\"\", \"\") returned an error %v, want %v", got, want)
+	commits, err := r.RevList("HEAD", "")
+	require.NoError(t, err)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, "I8473b95934b5732ac55d26311a706c9c2bde9940", commits[0].ChangeID)
+		assert.Empty(t, commits[0].Topic)
 	}
 }
 
-func TestTags(t *testing.T) {
+func TestRevList_pull_request(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
-	testutils.SimpleGitRepo(t, repo, path)
+	testutils.CommitFile(t, repo, path, "foo", "Merged PR 1234: feat: foo", []byte("foo"))
+	testutils.CommitFile(t, repo, path, "bar", "fix: bar (pull request #12)", []byte("bar"))
 
 	r, err := New(path)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	tags, err := r.Tags("master")
-	if err != nil {
-		if eerr, ok := err.(*exec.ExitError); ok {
-			t.Fatal(string(eerr.Stderr))
-		}
-		t.Fatal(err)
-	}
+	require.NoError(t, err)
 
-	if got, want := len(tags), 1; got != want {
-		t.Errorf("Tags returned %d tags, want %d", got, want)
-	} else if got, want := tags[0], "v1.0.0"; got != want {
-		t.Errorf("Tags returned %s, want %s", got, want)
+	commits, err := r.RevList("HEAD", "")
+	require.NoError(t, err)
+	if assert.Len(t, commits, 2) {
+		assert.Equal(t, 12, commits[0].PullRequest)
+		assert.Equal(t, "fix", commits[0].Type)
+		assert.Equal(t, "bar", commits[0].Subject)
+		assert.Equal(t, "fix: bar (pull request #12)", commits[0].Title)
+		assert.Equal(t, 1234, commits[1].PullRequest)
+		assert.Equal(t, "feat", commits[1].Type)
 	}
 }
 
-func TestTags_no_tags(t *testing.T) {
+func TestTopics(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
-	testutils.CommitFile(t, repo, path, "foo.txt", "chore: adding a foo", []byte("foo\n"))
+	base := testutils.CommitFile(t, repo, path, "foo", "feat: foo", []byte("foo"))
+	first := testutils.CommitFile(t, repo, path, "bar", "feat: bar", []byte("bar"))
+	second := testutils.CommitFile(t, repo, path, "bar", "fix: bar", []byte("more bar"))
+
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, w.Reset(&ggit.ResetOptions{Commit: base, Mode: ggit.HardReset}))
+	head := testutils.CommitFile(t, repo, path, "foo", "fix: foo", []byte("more foo"))
+	merge := testutils.MergeFiles(t, repo, path, "Merge changes from topic \"bar\" into main", second, []testutils.FileCommit{{Path: "bar", Contents: []byte("more bar")}})
 
 	r, err := New(path)
 	require.NoError(t, err)
 
-	if got, err := r.Tags("HEAD"); assert.NoError(t, err) {
-		assert.Empty(t, got)
+	commits, err := r.RevList("HEAD", "HEAD^")
+	require.NoError(t, err)
+	if assert.NotEmpty(t, commits) {
+		assert.Equal(t, "bar", commits[0].Topic)
 	}
+
+	topics, err := r.Topics("HEAD", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		merge.String():  "bar",
+		first.String():  "bar",
+		second.String(): "bar",
+	}, topics)
+	assert.NotContains(t, topics, head.String())
+
+	// commits before end are not in the topic
+	topics, err = r.Topics("HEAD", first.String())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{merge.String(): "bar", second.String(): "bar"}, topics)
+
+	_, err = r.Topics("", "")
+	assert.ErrorIs(t, err, errEmptyStart)
 }
 
-func TestTags_prefixes(t *testing.T) {
+func TestRevList_stats(t *testing.T) {
 	repo, path := testutils.NewGitRepo(t)
 
-	testutils.SimpleGitRepo(t, repo, path)
-
-	// add a submodule tag
-	submodule := "sub/module"
-	testutils.CommitFile(t, repo, path, filepath.Join("sub", "module", "file"), "feat: add submodule", []byte("data"))
-	testutils.CreateTag(t, repo, submodule+"/v0.1.0")
+	testutils.CommitFile(t, repo, path, "foo", "feat: foo", []byte("one\ntwo\nthree\n"))
+	testutils.CommitFiles(t, repo, path, "feat: more", []testutils.FileCommit{
+		{Path: "foo", Contents: []byte("one\nthree\nfour\nfive\n")},
+		{Path: "image.png", Contents: []byte{0x89, 'P', 'N', 'G', 0, 1, 2}},
+	})
 
 	r, err := New(path)
-	if err != nil {
-		t.Fatal(err)
+	require.NoError(t, err)
+
+	// stats are not counted by default
+	if commits, err := r.RevList("HEAD", "HEAD~1"); assert.NoError(t, err) && assert.Len(t, commits, 1) {
+		insertions, deletions := commits[0].LineStats()
+		assert.Zero(t, insertions)
+		assert.Zero(t, deletions)
 	}
 
-	tags, err := r.Tags("master", submodule+"/")
-	if err != nil {
-		if eerr, ok := err.(*exec.ExitError); ok {
-			t.Fatal(string(eerr.Stderr))
-		}
-		t.Fatal(err)
+	r.ChangeStats = func() bool { return true }
+	commits, err := r.RevList("HEAD", "HEAD~1")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	if assert.Len(t, commits[0].Changes, 2) {
+		assert.Equal(t, "foo", commits[0].Changes[0].SourceName)
+		assert.Equal(t, 2, commits[0].Changes[0].Insertions)
+		assert.Equal(t, 1, commits[0].Changes[0].Deletions)
+		assert.Equal(t, "image.png", commits[0].Changes[1].SourceName)
+		assert.Equal(t, -1, commits[0].Changes[1].Insertions)
+		assert.Equal(t, -1, commits[0].Changes[1].Deletions)
+	}
+
+	insertions, deletions := commits[0].LineStats()
+	assert.Equal(t, 2, insertions)
+	assert.Equal(t, 1, deletions)
+}
+
+func TestRevList_type_alias(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.CommitFile(t, repo, path, "foo", "bugfix: fix foo", []byte("foo"))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if commits, err := r.RevList("HEAD", ""); assert.NoError(t, err) && assert.Len(t, commits, 1) {
+		assert.Equal(t, "bugfix", commits[0].Type)
+	}
+
+	// aliases apply to cached commits too
+	r.TypeAlias = func(typ string) string {
+		return map[string]string{"bugfix": "fix"}[typ]
+	}
+	if commits, err := r.RevList("HEAD", ""); assert.NoError(t, err) && assert.Len(t, commits, 1) {
+		assert.Equal(t, "fix", commits[0].Type)
+	}
+	if c, err := r.Head(); assert.NoError(t, err) {
+		assert.Equal(t, "fix", c.Type)
+	}
+}
+
+func TestRevList_quoted_paths(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	names := []string{
+		"caf\u00e9/na\u00efve.go",
+		"with space/file name.go",
+		"\u65e5\u672c/\u8a9e.txt",
+		`quote"d.txt`,
+		`back\slash.txt`,
+		"tab\tname.txt",
+	}
+
+	files := make([]testutils.FileCommit, len(names))
+	for i, name := range names {
+		files[i] = testutils.FileCommit{Path: name, Contents: []byte(name + "\n")}
+	}
+	testutils.CommitFiles(t, repo, path, "feat: add files", files)
+
+	// paths are not quoted, even if the user asks for it
+	out, err := exec.Command("git", "-C", path, "config", "core.quotepath", "true").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	commits, err := r.RevList("HEAD", "")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+
+	var got []string
+	for _, change := range commits[0].Changes {
+		got = append(got, change.SourceName)
+	}
+	assert.ElementsMatch(t, names, got)
+}
+
+func TestUnquotePath(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"foo.go", "foo.go"},
+		{"with space.go", "with space.go"},
+		{"caf\u00e9.go", "caf\u00e9.go"},
+		{`"caf\303\251.go"`, "caf\u00e9.go"},
+		{`"quote\"d.txt"`, `quote"d.txt`},
+		{`"back\\slash.txt"`, `back\slash.txt`},
+		{`"tab\tname.txt"`, "tab\tname.txt"},
+		{`"`, `"`},
+		{`"bad\escape"`, `"bad\escape"`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, unquotePath(tt.name), tt.name)
+	}
+}
+
+func TestRevList_one_commit(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "foo", "add foo", []byte("contents"))
+
+	r, err := New(path)
+	require.NoError(err)
+
+	if commits, err := r.RevList("HEAD", ""); assert.NoError(err) && assert.Equal(1, len(commits)) {
+		assert.Equal(testutils.GotaggerName, commits[0].Author)
+		assert.Equal(testutils.GotaggerEmail, commits[0].AuthorEmail)
+		assert.Equal(testutils.GotaggerName, commits[0].Committer)
+		assert.Equal(testutils.GotaggerEmail, commits[0].CommitterEmail)
+		assert.Equal("add foo", commits[0].Title)
+	}
+
+	if _, err := r.RevList("HEAD", "HEAD~1"); assert.Error(err) {
+		assert.Contains(err.Error(), "bad revision '^HEAD~1")
+	}
+}
+
+func TestParseIdent(t *testing.T) {
+	tests := []struct {
+		ident, name, email string
+	}{
+		{"Jane Doe <jane@example.com> 1700000000 +0000", "Jane Doe", "jane@example.com"},
+		{"dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com> 1700000000 +0000", "dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com"},
+		{"<jane@example.com> 1700000000 +0000", "", "jane@example.com"},
+		{"Jane Doe", "Jane Doe", ""},
+	}
+
+	for _, tt := range tests {
+		name, email := parseIdent(tt.ident)
+		assert.Equal(t, tt.name, name, tt.ident)
+		assert.Equal(t, tt.email, email, tt.ident)
+	}
+}
+
+func TestRevList_empty_repo(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, path := testutils.NewGitRepo(t)
+
+	r, err := New(path)
+	require.NoError(err)
+
+	if _, err := r.RevList("HEAD", ""); assert.Error(err) {
+		assert.Contains(err.Error(), "unknown revision")
+	}
+
+	if _, err := r.RevList("HEAD", "HEAD^"); assert.Error(err) {
+		assert.Contains(err.Error(), "unknown revision")
+	}
+}
+
+func TestRevList_empty_start(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.RevList("", "")
+	if got, want := err, errEmptyStart; got != want {
+		t.Errorf("RevList(\"\", \"\") returned an error %v, want %v", got, want)
+	}
+}
+
+func TestTags(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := r.Tags("master")
+	if err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			t.Fatal(string(eerr.Stderr))
+		}
+		t.Fatal(err)
+	}
+
+	if got, want := len(tags), 1; got != want {
+		t.Errorf("Tags returned %d tags, want %d", got, want)
+	} else if got, want := tags[0], "v1.0.0"; got != want {
+		t.Errorf("Tags returned %s, want %s", got, want)
+	}
+}
+
+func TestTags_no_tags(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "foo.txt", "chore: adding a foo", []byte("foo\n"))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if got, err := r.Tags("HEAD"); assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		out  string
+		want []string
+	}{
+		{"", nil},
+		{"\n", nil},
+		{"  \n\n", nil},
+		{"v1.0.0", []string{"v1.0.0"}},
+		{"v1.0.0\n", []string{"v1.0.0"}},
+		{"v1.0.0\r\n\nv0.1.0\n", []string{"v1.0.0", "v0.1.0"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, splitLines(tt.out), "%q", tt.out)
+	}
+}
+
+func TestLatestTags(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "foo.txt", "feat: adding a foo", []byte("foo\n"))
+	testutils.CreateTag(t, repo, "v1.9.0")
+	testutils.CreateTag(t, repo, "bar/v1.0.0")
+	testutils.CommitFile(t, repo, path, "foo.txt", "feat: more foo", []byte("foo more\n"))
+	testutils.CreateTag(t, repo, "v1.10.0")
+	testutils.CreateTag(t, repo, "v2.0.0")
+	testutils.CommitFile(t, repo, path, "foo.txt", "feat: even more foo", []byte("foo even more\n"))
+	testutils.CreateTag(t, repo, "v2.1.0")
+	testutils.CreateTag(t, repo, "v2.1.0-rc.1")
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	// pre-releases sort below their release
+	if got, err := r.LatestTags("HEAD", 0, "v"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v2.1.0", "v2.1.0-rc.1", "v2.0.0", "v1.10.0", "v1.9.0"}, got)
+	}
+
+	if got, err := r.LatestTags("HEAD~1", 2, "v"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v2.0.0", "v1.10.0"}, got)
+	}
+
+	if got, err := r.LatestTags("HEAD", 1, "v0.", "v1."); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.10.0"}, got)
+	}
+
+	if got, err := r.LatestTags("HEAD", 0, "bar/"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"bar/v1.0.0"}, got)
+	}
+}
+
+func TestTagsAt(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "foo.txt", "feat: adding a foo", []byte("foo\n"))
+	testutils.CreateTag(t, repo, "v1.0.1")
+	testutils.CreateTag(t, repo, "v1.0.0")
+	testutils.CommitFile(t, repo, path, "bar.txt", "feat: adding a bar", []byte("bar\n"))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if got, err := r.TagsAt("HEAD~1"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.0.0", "v1.0.1"}, got)
+	}
+
+	if got, err := r.TagsAt("HEAD"); assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
+func TestTagsAt_nested(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "foo.txt", "feat: adding a foo", []byte("foo\n"))
+	testutils.CreateTag(t, repo, "v1.0.0")
+	createTagOf(t, repo, "v1.0.1", tagObject(t, repo, "v1.0.0"))
+	createTagOf(t, repo, "v1.0.2", tagObject(t, repo, "v1.0.1"))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if got, err := r.TagsAt("HEAD"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.0.0", "v1.0.1", "v1.0.2"}, got)
+	}
+}
+
+func TestCommitHash(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	commit := testutils.CommitFile(t, repo, path, "foo.txt", "feat: adding a foo", []byte("foo\n"))
+	testutils.CreateTag(t, repo, "v1.0.0")
+	createTagOf(t, repo, "nested", tagObject(t, repo, "v1.0.0"))
+
+	c, err := repo.CommitObject(commit)
+	require.NoError(t, err)
+	createTagOf(t, repo, "tree", c.TreeHash)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	for _, rev := range []string{"HEAD", "v1.0.0", "nested"} {
+		if got, err := r.CommitHash(rev); assert.NoError(t, err, rev) {
+			assert.Equal(t, commit.String(), got, rev)
+		}
+	}
+
+	_, err = r.CommitHash("tree")
+	assert.EqualError(t, err, "tree points to a tree, not a commit")
+
+	_, err = r.CommitHash("missing")
+	assert.Error(t, err)
+
+	// the same answers from a batch session
+	defer r.BeginBatch()()
+	if got, err := r.CommitHash("nested"); assert.NoError(t, err) {
+		assert.Equal(t, commit.String(), got)
+	}
+	_, err = r.CommitHash("tree")
+	assert.EqualError(t, err, "tree points to a tree, not a commit")
+}
+
+// tagObject returns the hash of the annotated tag object of the tag name.
+func tagObject(t *testing.T, repo *ggit.Repository, name string) plumbing.Hash {
+	t.Helper()
+
+	ref, err := repo.Tag(name)
+	require.NoError(t, err)
+
+	return ref.Hash()
+}
+
+// createTagOf creates the annotated tag name of the object target, which may
+// be any kind of object.
+func createTagOf(t *testing.T, repo *ggit.Repository, name string, target plumbing.Hash) {
+	t.Helper()
+
+	_, err := repo.CreateTag(name, target, &ggit.CreateTagOptions{
+		Tagger: &object.Signature{
+			Email: testutils.GotaggerEmail,
+			Name:  testutils.GotaggerName,
+			When:  time.Now(),
+		},
+		Message: name,
+	})
+	require.NoError(t, err)
+}
+
+func TestMoveTag(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	first, err := r.RevParse("v1.0.0^{commit}")
+	require.NoError(t, err)
+	head, err := r.Head()
+	require.NoError(t, err)
+
+	// create v1
+	previous, err := r.MoveTag(first, "v1", "Release v1.0.0")
+	require.NoError(t, err)
+	assert.Empty(t, previous)
+
+	if got, err := r.RevParse("v1^{commit}"); assert.NoError(t, err) {
+		assert.Equal(t, first, got)
+	}
+
+	// move v1 to HEAD
+	previous, err = r.MoveTag(head.Hash, "v1", "Release v1.1.0")
+	require.NoError(t, err)
+	assert.NotEmpty(t, previous)
+
+	if got, err := r.RevParse("v1^{commit}"); assert.NoError(t, err) {
+		assert.Equal(t, head.Hash, got)
+	}
+	if got, err := r.TagMessage("v1"); assert.NoError(t, err) {
+		assert.Equal(t, "Release v1.1.0", got)
+	}
+
+	// and back again
+	require.NoError(t, r.RestoreTag("v1", previous))
+	if got, err := r.RevParse("v1^{commit}"); assert.NoError(t, err) {
+		assert.Equal(t, first, got)
+	}
+
+	require.NoError(t, r.RestoreTag("v1", ""))
+	if got, err := r.TagsAt(first); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.0.0"}, got)
+	}
+}
+
+func TestTagNamespace(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+	r.TagNamespace = func() string { return "refs/releases" }
+
+	assert.Equal(t, "refs/releases/v1.1.0", r.TagRef("v1.1.0"))
+
+	head, err := r.Head()
+	require.NoError(t, err)
+	require.NoError(t, r.CreateTag(head.Hash, "v1.1.0", "Release v1.1.0\n\n# Notes", false))
+
+	// the tag is not in refs/tags
+	if out, err := r.run([]string{"tag", "--list", "v1.1.0"}); assert.NoError(t, err) {
+		assert.Empty(t, out)
+	}
+
+	if got, err := r.LatestTags("HEAD", 0, "v"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.1.0"}, got)
+	}
+
+	if got, err := r.TagsAt("HEAD"); assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.1.0"}, got)
+	}
+
+	if got, err := r.TagMessage("v1.1.0"); assert.NoError(t, err) {
+		assert.Equal(t, "Release v1.1.0\n\n# Notes", got)
+	}
+
+	if got, err := r.TagDate("v1.1.0"); assert.NoError(t, err) {
+		assert.WithinDuration(t, time.Now(), got, time.Minute)
+	}
+
+	if got, err := r.FindRefs([]string{"v1.1.0"}); assert.NoError(t, err) {
+		assert.Equal(t, []string{"refs/releases/v1.1.0"}, got)
+	}
+
+	// tags cannot be created twice or signed
+	assert.Error(t, r.CreateTag(head.Hash, "v1.1.0", "", false))
+	assert.EqualError(t, r.CreateTag(head.Hash, "v1.2.0", "", true), "cannot sign tag v1.2.0: signed tags can only be created in refs/tags/")
+
+	require.NoError(t, r.DeleteTags([]string{"v1.1.0"}))
+	if got, err := r.LatestTags("HEAD", 0); assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
+func TestPushTagsWithOptions_namespace(t *testing.T) {
+	tests := []struct {
+		opts   PushOptions
+		dryRun []string
+		want   []string
+	}{
+		{
+			want: []string{"--git-dir", ".git", "push", "origin", "refs/releases/v1.0.0:refs/releases/v1.0.0"},
+		},
+		{
+			opts:   PushOptions{FollowTags: true},
+			dryRun: []string{"--git-dir", ".git", "push", "--dry-run", "--porcelain", "--follow-tags", "origin", "refs/releases/v1.0.0:refs/releases/v1.0.0"},
+			want:   []string{"--git-dir", ".git", "push", "--follow-tags", "origin", "refs/releases/v1.0.0:refs/releases/v1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.want), func(t *testing.T) {
+			want := [][]string{tt.want}
+			if tt.dryRun != nil {
+				want = [][]string{tt.dryRun, tt.want}
+			}
+			r := &Repository{GitDir: ".git", Path: "path", runner: mockRunGitCommands(t, want, "path"), logger: logr.Discard()}
+			r.TagNamespace = func() string { return "refs/releases/" }
+			_ = r.PushTagsWithOptions([]string{"v1.0.0"}, "origin", tt.opts)
+		})
+	}
+}
+
+func TestTags_prefixes(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	// add a submodule tag
+	submodule := "sub/module"
+	testutils.CommitFile(t, repo, path, filepath.Join("sub", "module", "file"), "feat: add submodule", []byte("data"))
+	testutils.CreateTag(t, repo, submodule+"/v0.1.0")
+
+	r, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := r.Tags("master", submodule+"/")
+	if err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			t.Fatal(string(eerr.Stderr))
+		}
+		t.Fatal(err)
 	}
 
 	if got, want := len(tags), 1; got != want {
@@ -395,6 +1265,163 @@ func TestTags_prefixes(t *testing.T) {
 	}
 }
 
+func TestTagDate(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	before := time.Now().Add(-time.Second)
+	testutils.SimpleGitRepo(t, repo, path)
+
+	// add a lightweight tag
+	head, err := repo.Head()
+	require.NoError(t, err)
+	_, err = repo.CreateTag("lightweight", head.Hash(), nil)
+	require.NoError(t, err)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	for _, tag := range []string{"v1.0.0", "lightweight"} {
+		if got, err := r.TagDate(tag); assert.NoError(t, err) {
+			assert.WithinRange(t, got, before, time.Now().Add(time.Second))
+		}
+	}
+
+	_, err = r.TagDate("missing")
+	assert.EqualError(t, err, "tag missing not found")
+}
+
+func TestCreateTag_tagger_date(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	head, err := r.CommitHash("HEAD")
+	require.NoError(t, err)
+
+	var dated string
+	r.TaggerDate = func(hash string) (string, error) {
+		dated = hash
+		return "2020-01-02T03:04:05Z", nil
+	}
+	want := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, r.CreateTag(head, "v2.0.0", "", false))
+	assert.Equal(t, head, dated)
+	if got, err := r.TagDate("v2.0.0"); assert.NoError(t, err) {
+		assert.True(t, want.Equal(got), got)
+	}
+
+	// tags written outside of refs/tags/ are dated too
+	_, err = r.MoveTag(head, "v2", "Release v2.0.0")
+	require.NoError(t, err)
+	if got, err := r.TagDate("v2"); assert.NoError(t, err) {
+		assert.True(t, want.Equal(got), got)
+	}
+
+	// errors are returned before anything is tagged
+	r.TaggerDate = func(string) (string, error) { return "", errors.New("no date") }
+	assert.EqualError(t, r.CreateTag("HEAD", "v3.0.0", "", false), "no date")
+	tags, err := r.Tags("HEAD")
+	require.NoError(t, err)
+	assert.NotContains(t, tags, "v3.0.0")
+}
+
+func TestAuthorDate(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+
+	cmd := exec.Command("git", "-C", path, "commit", "--allow-empty", "-m", "release: v1.1.0", "--date=2020-01-02T03:04:05Z")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if got, err := r.AuthorDate("HEAD"); assert.NoError(t, err) {
+		assert.True(t, time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC).Equal(got), got)
+	}
+	if got, err := r.CommitDate("HEAD"); assert.NoError(t, err) {
+		assert.WithinDuration(t, time.Now(), got, time.Minute)
+	}
+
+	_, err = r.AuthorDate("missing")
+	assert.Error(t, err)
+}
+
+func TestTagMessage(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+	testutils.SimpleGitRepo(t, repo, path)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	_, err = repo.CreateTag("lightweight", head.Hash(), nil)
+	require.NoError(t, err)
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	require.NoError(t, r.CreateTag(head.Hash().String(), "v1.1.0", "Release v1.1.0\n\n### Added\n\n- a feature", false))
+
+	if got, err := r.TagMessage("v1.1.0"); assert.NoError(t, err) {
+		assert.Equal(t, "Release v1.1.0\n\n### Added\n\n- a feature", got)
+	}
+
+	if got, err := r.TagMessage("v1.0.0"); assert.NoError(t, err) {
+		assert.Equal(t, "v1.0.0", got)
+	}
+
+	_, err = r.TagMessage("lightweight")
+	assert.EqualError(t, err, "tag lightweight is not an annotated tag")
+
+	_, err = r.TagMessage("missing")
+	assert.EqualError(t, err, "tag missing not found")
+}
+
+func TestVerifyTag(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is required to sign tags")
+	}
+
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.SimpleGitRepo(t, repo, path)
+
+	// generate an ssh key to sign tags with
+	keyDir := t.TempDir()
+	key := filepath.Join(keyDir, "key")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "gotagger", "-f", key).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	pub, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+
+	allowedSigners := filepath.Join(keyDir, "allowed_signers")
+	require.NoError(t, os.WriteFile(allowedSigners, []byte(testutils.GotaggerEmail+" "+string(pub)), 0600))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	for _, cfg := range [][]string{
+		{"gpg.format", "ssh"},
+		{"user.signingkey", key},
+		{"gpg.ssh.allowedSignersFile", allowedSigners},
+	} {
+		_, err := r.run(append([]string{"config"}, cfg...))
+		require.NoError(t, err)
+	}
+
+	head, err := r.Head()
+	require.NoError(t, err)
+	require.NoError(t, r.CreateTag(head.Hash, "v1.1.0", "", true))
+
+	assert.NoError(t, r.VerifyTag("v1.1.0"))
+
+	// v1.0.0 is not signed
+	assert.Error(t, r.VerifyTag("v1.0.0"))
+}
+
 func Test_hasPrefix(t *testing.T) {
 	tests := []struct {
 		title    string
@@ -451,8 +1478,20 @@ func Test_hasPrefix(t *testing.T) {
 }
 
 // tests that inject a mock runner function
-func mockRunGitCommand(t *testing.T, wantArgs []string, wantPath string) func([]string, string) (string, error) {
-	return func(args []string, path string) (string, error) {
+// mockRunGitCommands returns a runner that expects to be called with each of
+// wantArgs in order.
+func mockRunGitCommands(t *testing.T, wantArgs [][]string, wantPath string) func([]string, string, []string) (string, error) {
+	return func(args []string, path string, env []string) (string, error) {
+		require.NotEmpty(t, wantArgs, "unexpected git command: %v", args)
+		assert.Equal(t, wantArgs[0], args)
+		assert.Equal(t, wantPath, path)
+		wantArgs = wantArgs[1:]
+		return "", nil
+	}
+}
+
+func mockRunGitCommand(t *testing.T, wantArgs []string, wantPath string) func([]string, string, []string) (string, error) {
+	return func(args []string, path string, env []string) (string, error) {
 		assert.Equal(t, wantArgs, args)
 		assert.Equal(t, wantPath, path)
 		return "", nil
@@ -0,0 +1,8 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+// platformArgs lets git for Windows read and write files whose full path is
+// longer than MAX_PATH, as in deeply nested modules.
+var platformArgs = []string{"-c", "core.longpaths=true"}
@@ -0,0 +1,53 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParseRemoteURL returns the lower case host name of the server and the path
+// of the repository, without slashes at either end or the .git suffix, of a
+// remote URL such as https://github.com/org/repo.git or
+// git@github.com:org/repo.git. The host includes the port of http and https
+// URLs, but not of ssh URLs, whose port is not that of the web server. ok is
+// false for local paths and URLs with other schemes.
+func ParseRemoteURL(rawURL string) (host, path string, ok bool) {
+	if rawURL == "" {
+		return "", "", false
+	}
+
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", false
+		}
+
+		switch u.Scheme {
+		case "http", "https":
+			host = u.Host
+		case "ssh", "git":
+			host = u.Hostname()
+		default:
+			return "", "", false
+		}
+		path = u.Path
+	} else if before, after, found := strings.Cut(rawURL, ":"); found && !strings.ContainsAny(before, `/\`) && len(before) > 1 {
+		// scp-like syntax: [user@]host:path
+		if i := strings.LastIndex(before, "@"); i >= 0 {
+			before = before[i+1:]
+		}
+		host, path = before, after
+	} else {
+		return "", "", false
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", "", false
+	}
+
+	return strings.ToLower(host), path, true
+}
@@ -0,0 +1,251 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// treeFS is a read-only fs.FS of the files in a git tree.
+type treeFS struct {
+	repo  *Repository
+	files map[string]treeEntry
+	dirs  map[string][]fs.DirEntry
+}
+
+// treeEntry is a file listed by git ls-tree.
+type treeEntry struct {
+	name string
+	mode fs.FileMode
+	hash string
+}
+
+var (
+	_ fs.ReadDirFS  = (*treeFS)(nil)
+	_ fs.ReadFileFS = (*treeFS)(nil)
+	_ fs.StatFS     = (*treeFS)(nil)
+)
+
+// TreeFS returns a read-only fs.FS of the files committed in the tree of rev,
+// as listed by git ls-tree. Paths are relative to the root of the repository.
+// File contents are read from the object database when they are opened,
+// using the batch session if there is one.
+// Submodules are not included.
+func (r *Repository) TreeFS(rev string) (fs.FS, error) {
+	out, err := r.run([]string{"ls-tree", "-r", "-z", "--full-tree", rev})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &treeFS{
+		repo:  r,
+		files: map[string]treeEntry{},
+		dirs:  map[string][]fs.DirEntry{".": nil},
+	}
+
+	for _, line := range strings.Split(out, "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		info, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(info)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+
+		entry := treeEntry{name: path.Base(name), hash: fields[2]}
+		if fields[0] == "120000" {
+			entry.mode = fs.ModeSymlink
+		}
+		t.files[name] = entry
+		t.addEntry(name, dirEntry{entry})
+	}
+
+	for _, entries := range t.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+
+	return t, nil
+}
+
+// ReadFileAt returns the contents of the file name, relative to the root of
+// the repository, as committed in the tree of rev. The error wraps
+// fs.ErrNotExist if rev has no such file.
+func (r *Repository) ReadFileAt(rev, name string) ([]byte, error) {
+	out, err := r.run([]string{"ls-tree", "-z", "--full-tree", rev, "--", name})
+	if err != nil {
+		return nil, err
+	}
+
+	// <mode> SP <type> SP <object> TAB <file>
+	info, _, _ := strings.Cut(out, "\t")
+	fields := strings.Fields(info)
+	if len(fields) != 3 || fields[1] != "blob" {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if data, ok, err := r.objectContents(fields[2]); ok {
+		if err != nil {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		return data, nil
+	}
+
+	out, err = r.run([]string{"cat-file", "blob", fields[2]})
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	return []byte(out), nil
+}
+
+// addEntry adds entry to the directory containing name,
+// creating any parent directories.
+func (t *treeFS) addEntry(name string, entry fs.DirEntry) {
+	dir := path.Dir(name)
+	_, exists := t.dirs[dir]
+	t.dirs[dir] = append(t.dirs[dir], entry)
+
+	if !exists && dir != "." {
+		t.addEntry(dir, dirEntry{treeEntry{name: path.Base(dir), mode: fs.ModeDir}})
+	}
+}
+
+// Open opens the named file or directory.
+func (t *treeFS) Open(name string) (fs.File, error) {
+	info, err := t.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &treeDir{info: info, entries: t.dirs[name]}, nil
+	}
+
+	data, err := t.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &treeFile{info: info, Reader: strings.NewReader(string(data))}, nil
+}
+
+// ReadDir returns the entries of the named directory, sorted by name.
+func (t *treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, ok := t.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// ReadFile returns the contents of the named file.
+func (t *treeFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entry, ok := t.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if data, ok, err := t.repo.objectContents(entry.hash); ok {
+		if err != nil {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		return data, nil
+	}
+
+	out, err := t.repo.run([]string{"cat-file", "blob", entry.hash})
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	return []byte(out), nil
+}
+
+// Stat returns information about the named file or directory.
+func (t *treeFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entry, ok := t.files[name]; ok {
+		return entry, nil
+	}
+
+	if _, ok := t.dirs[name]; ok {
+		return treeEntry{name: path.Base(name), mode: fs.ModeDir}, nil
+	}
+
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// treeEntry implements fs.FileInfo. Sizes and modification times are not
+// tracked.
+func (e treeEntry) Name() string       { return e.name }
+func (e treeEntry) Size() int64        { return 0 }
+func (e treeEntry) Mode() fs.FileMode  { return e.mode }
+func (e treeEntry) ModTime() time.Time { return time.Time{} }
+func (e treeEntry) IsDir() bool        { return e.mode.IsDir() }
+func (e treeEntry) Sys() any           { return nil }
+
+// dirEntry implements fs.DirEntry.
+type dirEntry struct {
+	treeEntry
+}
+
+func (e dirEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e dirEntry) Info() (fs.FileInfo, error) { return e.treeEntry, nil }
+
+// treeFile is an open file in a treeFS.
+type treeFile struct {
+	*strings.Reader
+	info fs.FileInfo
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *treeFile) Close() error               { return nil }
+
+// treeDir is an open directory in a treeFS.
+type treeDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *treeDir) Close() error               { return nil }
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.entries[d.offset:]
+	if n > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		if n < len(entries) {
+			entries = entries[:n]
+		}
+	}
+	d.offset += len(entries)
+
+	return append([]fs.DirEntry(nil), entries...), nil
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sassoftware/gotagger/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeFS(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "go.mod", "feat: add go.mod", []byte("module foo\n"))
+	testutils.CommitFile(t, repo, path, filepath.Join("bar", "go.mod"), "feat: add bar", []byte("module foo/bar\n"))
+	testutils.CommitFile(t, repo, path, filepath.Join("bar", "baz", "baz.go"), "feat: add baz", []byte("package baz\n"))
+
+	// change the worktree without committing
+	require.NoError(t, os.Remove(filepath.Join(path, "bar", "go.mod")))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "new.go"), []byte("package foo\n"), 0o600))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	fsys, err := r.TreeFS("HEAD")
+	require.NoError(t, err)
+
+	assert.NoError(t, fstest.TestFS(fsys, "go.mod", "bar/go.mod", "bar/baz/baz.go"))
+
+	if data, err := fs.ReadFile(fsys, "bar/go.mod"); assert.NoError(t, err) {
+		assert.Equal(t, "module foo/bar\n", string(data))
+	}
+
+	_, err = fs.Stat(fsys, "new.go")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	if fsys, err := r.TreeFS("HEAD~1"); assert.NoError(t, err) {
+		assert.NoError(t, fstest.TestFS(fsys, "go.mod", "bar/go.mod"))
+		_, err = fs.Stat(fsys, "bar/baz")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	}
+
+	_, err = r.TreeFS("missing")
+	assert.Error(t, err)
+}
+
+func TestRepository_ReadFileAt(t *testing.T) {
+	repo, path := testutils.NewGitRepo(t)
+
+	testutils.CommitFile(t, repo, path, "VERSION", "chore: add VERSION", []byte("1.0.0\n"))
+	testutils.CommitFile(t, repo, path, filepath.Join("bar", "VERSION"), "chore: add bar", []byte("2.0.0\n"))
+	testutils.CommitFile(t, repo, path, "VERSION", "chore: bump VERSION", []byte("1.1.0\n"))
+
+	r, err := New(path)
+	require.NoError(t, err)
+
+	if data, err := r.ReadFileAt("HEAD", "VERSION"); assert.NoError(t, err) {
+		assert.Equal(t, "1.1.0\n", string(data))
+	}
+
+	if data, err := r.ReadFileAt("HEAD~2", "VERSION"); assert.NoError(t, err) {
+		assert.Equal(t, "1.0.0\n", string(data))
+	}
+
+	if data, err := r.ReadFileAt("HEAD", "bar/VERSION"); assert.NoError(t, err) {
+		assert.Equal(t, "2.0.0\n", string(data))
+	}
+
+	_, err = r.ReadFileAt("HEAD~2", "bar/VERSION")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	// directories are not files
+	_, err = r.ReadFileAt("HEAD", "bar")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
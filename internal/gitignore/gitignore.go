@@ -0,0 +1,150 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gitignore matches paths against patterns in gitignore syntax, as
+// used by ignore files and CODEOWNERS files.
+package gitignore
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchResult is the result of matching a path against a Pattern.
+type MatchResult int
+
+const (
+	// NoMatch means the pattern does not match the path.
+	NoMatch MatchResult = iota
+
+	// Exclude means the pattern matches the path.
+	Exclude
+
+	// Include means a negated pattern, such as "!foo", matches the path.
+	Include
+)
+
+// Pattern is a line of a gitignore file.
+type Pattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ParsePattern parses a gitignore pattern, such as "*.md", "/docs/", or
+// "!keep/**".
+func ParsePattern(p string) Pattern {
+	var res Pattern
+
+	// trailing spaces are ignored unless they are escaped
+	if !strings.HasSuffix(p, `\ `) {
+		p = strings.TrimRight(p, " ")
+	}
+
+	switch {
+	case strings.HasPrefix(p, "!"):
+		res.negate = true
+		p = p[1:]
+	case strings.HasPrefix(p, `\!`), strings.HasPrefix(p, `\#`):
+		p = p[1:]
+	}
+
+	if strings.HasSuffix(p, "/") {
+		res.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+
+	// a pattern with a slash is relative to the root,
+	// otherwise it matches a name at any depth
+	if strings.Contains(p, "/") {
+		res.anchored = true
+		p = strings.TrimPrefix(p, "/")
+	}
+	res.segments = strings.Split(p, "/")
+
+	return res
+}
+
+// Match matches the path, split into its elements, against p. isDir is true
+// if path is a directory. A pattern that matches a directory also matches
+// everything in it.
+func (p Pattern) Match(path []string, isDir bool) MatchResult {
+	if len(path) == 0 || len(p.segments) == 0 || p.segments[0] == "" && len(p.segments) == 1 {
+		return NoMatch
+	}
+
+	for n := 1; n <= len(path); n++ {
+		// only the last element may be a file
+		if p.dirOnly && n == len(path) && !isDir {
+			break
+		}
+
+		var ok bool
+		if p.anchored {
+			ok = matchSegments(p.segments, path[:n])
+		} else {
+			ok = matchName(p.segments[0], path[n-1])
+		}
+		if ok {
+			if p.negate {
+				return Include
+			}
+			return Exclude
+		}
+	}
+
+	return NoMatch
+}
+
+// matchSegments returns true if the elements of path match the segments of
+// an anchored pattern, where "**" matches any number of elements.
+func matchSegments(segments, path []string) bool {
+	if len(segments) == 0 {
+		return len(path) == 0
+	}
+
+	if segments[0] == "**" {
+		// a trailing "**" matches everything inside, but not the directory
+		if len(segments) == 1 {
+			return len(path) > 0
+		}
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(segments[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return len(path) > 0 && matchName(segments[0], path[0]) && matchSegments(segments[1:], path[1:])
+}
+
+// matchName returns true if the path element name matches the glob pattern.
+func matchName(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// Matcher matches paths against a list of patterns.
+type Matcher struct {
+	patterns []Pattern
+}
+
+// NewMatcher returns a Matcher for patterns, in the order they appear in a
+// gitignore file.
+func NewMatcher(patterns []Pattern) Matcher {
+	return Matcher{patterns: patterns}
+}
+
+// Match returns true if path is excluded. The last pattern that matches path
+// wins, so a negated pattern can include a path excluded by an earlier one.
+func (m Matcher) Match(path []string, isDir bool) bool {
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if res := m.patterns[i].Match(path, isDir); res != NoMatch {
+			return res == Exclude
+		}
+	}
+
+	return false
+}
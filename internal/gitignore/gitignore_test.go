@@ -0,0 +1,72 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gitignore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    MatchResult
+	}{
+		{pattern: "*.md", path: "README.md", want: Exclude},
+		{pattern: "*.md", path: "docs/guide.md", want: Exclude},
+		{pattern: "*.md", path: "foo.go", want: NoMatch},
+		{pattern: "docs", path: "docs/guide.md", want: Exclude},
+		{pattern: "docs", path: "sub/docs/guide.md", want: Exclude},
+		{pattern: "docs/", path: "docs", want: NoMatch},
+		{pattern: "docs/", path: "docs", isDir: true, want: Exclude},
+		{pattern: "docs/", path: "docs/guide.md", want: Exclude},
+		{pattern: "/docs", path: "docs/guide.md", want: Exclude},
+		{pattern: "/docs", path: "sub/docs/guide.md", want: NoMatch},
+		{pattern: "docs/*.md", path: "docs/guide.md", want: Exclude},
+		{pattern: "docs/*.md", path: "sub/docs/guide.md", want: NoMatch},
+		{pattern: "docs/*.md", path: "docs/api/guide.md", want: NoMatch},
+		{pattern: "**/testdata", path: "testdata/foo", want: Exclude},
+		{pattern: "**/testdata", path: "sub/pkg/testdata/foo", want: Exclude},
+		{pattern: "docs/**/*.png", path: "docs/a/b/logo.png", want: Exclude},
+		{pattern: "docs/**/*.png", path: "docs/logo.png", want: Exclude},
+		{pattern: "docs/**", path: "docs/guide.md", want: Exclude},
+		{pattern: "docs/**", path: "docs", want: NoMatch},
+		{pattern: "!docs/keep.md", path: "docs/keep.md", want: Include},
+		{pattern: `\!important`, path: "!important", want: Exclude},
+		{pattern: "foo.go  ", path: "foo.go", want: Exclude},
+		{pattern: "[ab].go", path: "a.go", want: Exclude},
+		{pattern: "?.go", path: "ab.go", want: NoMatch},
+		{pattern: "", path: "foo.go", want: NoMatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			got := ParsePattern(tt.pattern).Match(strings.Split(tt.path, "/"), tt.isDir)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	m := NewMatcher([]Pattern{
+		ParsePattern("docs/"),
+		ParsePattern("!docs/api.md"),
+		ParsePattern("*.tmp"),
+	})
+
+	assert.True(t, m.Match([]string{"docs", "guide.md"}, false))
+	assert.False(t, m.Match([]string{"docs", "api.md"}, false))
+	assert.True(t, m.Match([]string{"foo.tmp"}, false))
+	assert.False(t, m.Match([]string{"foo.go"}, false))
+
+	// no patterns match nothing
+	assert.False(t, NewMatcher(nil).Match([]string{"foo.go"}, false))
+}
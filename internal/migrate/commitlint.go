@@ -0,0 +1,228 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+const configConventional = "@commitlint/config-conventional"
+
+// commitlintFiles are the commitlint configuration files that can be read,
+// in the order commitlint looks for them.
+var commitlintFiles = []string{"package.json", ".commitlintrc", ".commitlintrc.json", "commitlint.config.js"}
+
+// conventionalTypes are the types allowed by @commitlint/config-conventional.
+var conventionalTypes = []string{
+	mapper.TypeBuild,
+	mapper.TypeChore,
+	mapper.TypeCI,
+	mapper.TypeDocs,
+	mapper.TypeFeature,
+	mapper.TypeBugFix,
+	mapper.TypePerformance,
+	mapper.TypeRefactor,
+	mapper.TypeRevert,
+	mapper.TypeStyle,
+	mapper.TypeTest,
+}
+
+// CommitlintRules are the commitlint rules that are relevant to gotagger.
+type CommitlintRules struct {
+	// Types are the commit types allowed by the type-enum rule.
+	Types []string
+
+	// Scopes are the commit scopes allowed by the scope-enum rule.
+	Scopes []string
+}
+
+type commitlint struct {
+	Extends json.RawMessage              `json:"extends"`
+	Rules   map[string][]json.RawMessage `json:"rules"`
+}
+
+// Commitlint reads the commitlint configuration in dir.
+//
+// Only JSON configuration is supported. A commitlint.config.js file is read
+// if it only exports an object literal.
+func Commitlint(dir string) (CommitlintRules, error) {
+	for _, name := range commitlintFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return CommitlintRules{}, err
+		}
+
+		switch name {
+		case "package.json":
+			var pkg struct {
+				Commitlint json.RawMessage `json:"commitlint"`
+			}
+			if err := json.Unmarshal(data, &pkg); err != nil {
+				return CommitlintRules{}, fmt.Errorf("could not parse %s: %w", name, err)
+			}
+			if len(pkg.Commitlint) == 0 {
+				continue
+			}
+			data = pkg.Commitlint
+		case "commitlint.config.js":
+			if data, err = jsToJSON(string(data)); err != nil {
+				return CommitlintRules{}, fmt.Errorf("could not parse %s: %w", name, err)
+			}
+		}
+
+		rules, err := ParseCommitlint(data)
+		if err != nil {
+			return CommitlintRules{}, fmt.Errorf("could not parse %s: %w", name, err)
+		}
+
+		return rules, nil
+	}
+
+	return CommitlintRules{}, fmt.Errorf("no commitlint configuration found in %s", dir)
+}
+
+// ParseCommitlint parses the type-enum and scope-enum rules from a commitlint
+// JSON configuration. If the configuration extends
+// @commitlint/config-conventional and does not set the type-enum rule, then
+// the types allowed by config-conventional are used.
+func ParseCommitlint(data []byte) (rules CommitlintRules, err error) {
+	var cl commitlint
+	if err := json.Unmarshal(data, &cl); err != nil {
+		return rules, fmt.Errorf("only JSON configuration is supported: %w", err)
+	}
+
+	if rules.Types, err = enumRule(cl.Rules, "type-enum"); err != nil {
+		return rules, err
+	}
+	if rules.Scopes, err = enumRule(cl.Rules, "scope-enum"); err != nil {
+		return rules, err
+	}
+
+	if _, ok := cl.Rules["type-enum"]; !ok && extends(cl.Extends, configConventional) {
+		rules.Types = append([]string(nil), conventionalTypes...)
+	}
+
+	return rules, nil
+}
+
+// Config returns gotagger configuration that maps every type in r. Features
+// are mapped to minor increments and all other types to patch increments,
+// which matches the gotagger defaults.
+func (r CommitlintRules) Config() (Config, error) {
+	if len(r.Types) == 0 {
+		return Config{}, errors.New("commitlint configuration does not restrict commit types with type-enum")
+	}
+
+	cfg := Config{IncrementMappings: map[string]string{}}
+	for _, typ := range r.Types {
+		// gotagger does not allow mapping the release type
+		if typ != mapper.TypeRelease {
+			cfg.IncrementMappings[typ] = commitlintIncrement(typ).String()
+		}
+	}
+
+	return cfg, nil
+}
+
+// Check returns a problem for every type that is mapped in table but not
+// allowed by r, and for every type allowed by r that is not mapped and would
+// get a different increment from the default of table than Config maps it to.
+func (r CommitlintRules) Check(table mapper.Table) []string {
+	allowed := map[string]struct{}{}
+	for _, typ := range r.Types {
+		allowed[typ] = struct{}{}
+	}
+
+	var problems []string
+	for typ := range table.Mapper {
+		if _, ok := allowed[typ]; !ok && len(r.Types) > 0 {
+			problems = append(problems, fmt.Sprintf("type %q is mapped but not allowed by commitlint", typ))
+		}
+	}
+
+	for _, typ := range r.Types {
+		if _, ok := table.Mapper[typ]; ok || typ == mapper.TypeRelease {
+			continue
+		}
+		if want := commitlintIncrement(typ); table.Default() != want {
+			problems = append(problems, fmt.Sprintf("type %q is allowed by commitlint but not mapped, so it is a %s increment instead of %s", typ, table.Default(), want))
+		}
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
+
+// commitlintIncrement returns the increment that Config maps typ to.
+func commitlintIncrement(typ string) mapper.Increment {
+	if typ == mapper.TypeFeature {
+		return mapper.IncrementMinor
+	}
+
+	return mapper.IncrementPatch
+}
+
+// enumRule returns the values of an enum rule, such as type-enum. Disabled
+// rules and rules that list values that are not allowed return nil.
+func enumRule(rules map[string][]json.RawMessage, name string) ([]string, error) {
+	rule, ok := rules[name]
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		level      int
+		applicable = "always"
+		values     []string
+	)
+	if len(rule) != 3 {
+		return nil, fmt.Errorf("invalid %s rule: must have a level, applicability, and value", name)
+	}
+	if err := json.Unmarshal(rule[0], &level); err != nil {
+		return nil, fmt.Errorf("invalid %s rule level: %w", name, err)
+	}
+	if err := json.Unmarshal(rule[1], &applicable); err != nil {
+		return nil, fmt.Errorf("invalid %s rule applicability: %w", name, err)
+	}
+	if err := json.Unmarshal(rule[2], &values); err != nil {
+		return nil, fmt.Errorf("invalid %s rule value: %w", name, err)
+	}
+
+	if level == 0 || applicable != "always" {
+		return nil, nil
+	}
+
+	return values, nil
+}
+
+// extends returns true if the extends setting in data includes name.
+func extends(data json.RawMessage, name string) bool {
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		var single string
+		if json.Unmarshal(data, &single) != nil {
+			return false
+		}
+		list = []string{single}
+	}
+
+	for _, e := range list {
+		if e == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sassoftware/gotagger/mapper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommitlint(t *testing.T) {
+	tests := []struct {
+		title   string
+		data    string
+		want    CommitlintRules
+		wantErr string
+	}{
+		{
+			title: "type and scope enums",
+			data:  `{"rules": {"type-enum": [2, "always", ["feat", "fix"]], "scope-enum": [1, "always", ["api", "cli"]]}}`,
+			want:  CommitlintRules{Types: []string{"feat", "fix"}, Scopes: []string{"api", "cli"}},
+		},
+		{
+			title: "config conventional",
+			data:  `{"extends": "@commitlint/config-conventional"}`,
+			want:  CommitlintRules{Types: conventionalTypes},
+		},
+		{
+			title: "disabled rule",
+			data:  `{"extends": ["@commitlint/config-conventional"], "rules": {"type-enum": [0, "always", ["feat"]]}}`,
+			want:  CommitlintRules{},
+		},
+		{
+			title: "never",
+			data:  `{"rules": {"type-enum": [2, "never", ["wip"]]}}`,
+			want:  CommitlintRules{},
+		},
+		{
+			title:   "invalid rule",
+			data:    `{"rules": {"type-enum": [2, "always"]}}`,
+			wantErr: "invalid type-enum rule: must have a level, applicability, and value",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseCommitlint([]byte(tt.data))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCommitlint(t *testing.T) {
+	// this project's own configuration
+	if rules, err := Commitlint(filepath.Join("..", "..")); assert.NoError(t, err) {
+		assert.Contains(t, rules.Types, "release")
+		assert.Contains(t, rules.Types, "feat")
+	}
+
+	dir := t.TempDir()
+
+	_, err := Commitlint(dir)
+	assert.EqualError(t, err, "no commitlint configuration found in "+dir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".commitlintrc"), []byte("extends:\n  - '@commitlint/config-conventional'\n"), 0o600))
+
+	_, err = Commitlint(dir)
+	assert.ErrorContains(t, err, "could not parse .commitlintrc: only JSON configuration is supported")
+}
+
+func TestCommitlintRules_Config(t *testing.T) {
+	rules := CommitlintRules{Types: []string{"feat", "fix", "docs", "release"}}
+
+	if cfg, err := rules.Config(); assert.NoError(t, err) {
+		assert.Equal(t, Config{IncrementMappings: map[string]string{"feat": "minor", "fix": "patch", "docs": "patch"}}, cfg)
+	}
+
+	_, err := CommitlintRules{}.Config()
+	assert.EqualError(t, err, "commitlint configuration does not restrict commit types with type-enum")
+}
+
+func TestCommitlintRules_Check(t *testing.T) {
+	rules := CommitlintRules{Types: []string{"feat", "fix", "docs", "release"}}
+
+	assert.Empty(t, rules.Check(mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor, "fix": mapper.IncrementPatch, "docs": mapper.IncrementNone}, mapper.IncrementNone)))
+	assert.Equal(t, []string{
+		`type "docs" is allowed by commitlint but not mapped, so it is a none increment instead of patch`,
+		`type "f" is mapped but not allowed by commitlint`,
+	}, rules.Check(mapper.NewTable(mapper.Mapper{"feat": mapper.IncrementMinor, "fix": mapper.IncrementPatch, "f": mapper.IncrementMinor}, mapper.IncrementNone)))
+
+	// unmapped types that get the increment Config would map them to are fine
+	assert.Empty(t, rules.Check(mapper.NewTable(nil, mapper.IncrementPatch)))
+	assert.Equal(t, []string{
+		`type "feat" is allowed by commitlint but not mapped, so it is a patch increment instead of minor`,
+	}, rules.Check(mapper.NewTable(mapper.Mapper{}, mapper.IncrementPatch)))
+}
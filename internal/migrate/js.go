@@ -0,0 +1,136 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// jsToJSON converts a javascript module that exports an object literal,
+// such as a release.config.js or commitlint.config.js, into JSON.
+//
+// Only literal values are supported. Comments, unquoted keys, single quoted
+// strings, and trailing commas are allowed, but expressions such as function
+// calls or variables are not.
+func jsToJSON(src string) ([]byte, error) {
+	var out strings.Builder
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '"' || c == '\'' || c == '`':
+			s, n, err := readString(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(s)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data)
+			i += n
+		case c == '}' || c == ']':
+			// drop trailing commas
+			trimmed := strings.TrimRightFunc(out.String(), unicode.IsSpace)
+			if strings.HasSuffix(trimmed, ",") {
+				out.Reset()
+				out.WriteString(strings.TrimSuffix(trimmed, ","))
+			}
+			out.WriteByte(c)
+			i++
+		case c == ';':
+			// only allowed at the end
+			if strings.TrimSpace(src[i+1:]) != "" {
+				return nil, fmt.Errorf("only a single exported object is supported")
+			}
+			i = len(src)
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || (src[j] >= '0' && src[j] <= '9')) {
+				j++
+			}
+			ident := src[i:j]
+
+			// skip the export itself
+			if rest := strings.TrimLeftFunc(src[j:], unicode.IsSpace); ident == "module" && strings.HasPrefix(rest, ".exports") {
+				rest = strings.TrimLeftFunc(strings.TrimPrefix(rest, ".exports"), unicode.IsSpace)
+				if !strings.HasPrefix(rest, "=") {
+					return nil, fmt.Errorf("unsupported expression %q", ident)
+				}
+				i = len(src) - len(rest) + 1
+				continue
+			} else if ident == "export" && strings.HasPrefix(rest, "default") {
+				i = len(src) - len(rest) + len("default")
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(strings.TrimLeftFunc(src[j:], unicode.IsSpace), ":"):
+				out.WriteString(`"` + ident + `"`)
+			case ident == "true" || ident == "false" || ident == "null":
+				out.WriteString(ident)
+			default:
+				return nil, fmt.Errorf("unsupported expression %q", ident)
+			}
+			i = j
+		case strings.ContainsRune("{[:,-.+0123456789", rune(c)) || unicode.IsSpace(rune(c)):
+			out.WriteByte(c)
+			i++
+		default:
+			return nil, fmt.Errorf("unsupported character %q", c)
+		}
+	}
+
+	return []byte(out.String()), nil
+}
+
+// readString reads the quoted javascript string at the start of s, returning
+// the unquoted string and the number of bytes read.
+func readString(s string) (string, int, error) {
+	quote := s[0]
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				break
+			}
+			switch e := s[i]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			if quote == '`' && strings.HasPrefix(s[i:], "${") {
+				return "", 0, fmt.Errorf("template strings with expressions are not supported")
+			}
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
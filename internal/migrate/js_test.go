@@ -0,0 +1,75 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSToJSON(t *testing.T) {
+	tests := []struct {
+		title   string
+		src     string
+		want    string
+		wantErr string
+	}{
+		{
+			title: "module exports",
+			src: `/** @type {import('semantic-release').GlobalConfig} */
+module.exports = {
+  // release from main
+  branches: ['main', "next"],
+  tagFormat: 'v${version}',
+  name: ` + "`foo`" + `,
+  rules: {
+    "type-enum": [2, 'always', ['feat', 'fix',],],
+  },
+  dryRun: false, /* not yet */
+};
+`,
+			want: `{"branches":["main","next"],"dryRun":false,"name":"foo","rules":{"type-enum":[2,"always",["feat","fix"]]},"tagFormat":"v${version}"}`,
+		},
+		{
+			title:   "template expression",
+			src:     "module.exports = { tagFormat: `${prefix}${version}` }",
+			wantErr: "template strings with expressions are not supported",
+		},
+		{
+			title: "export default",
+			src:   `export default { extends: ['@commitlint/config-conventional'], level: -1.5 }`,
+			want:  `{"extends":["@commitlint/config-conventional"],"level":-1.5}`,
+		},
+		{
+			title:   "function call",
+			src:     `module.exports = { plugins: require('./plugins') }`,
+			wantErr: `unsupported expression "require"`,
+		},
+		{
+			title:   "multiple statements",
+			src:     `module.exports = {}; console.log("hi")`,
+			wantErr: "only a single exported object is supported",
+		},
+		{
+			title:   "unterminated string",
+			src:     `module.exports = { a: 'b }`,
+			wantErr: "unterminated string",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := jsToJSON(tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else if assert.NoError(t, err) {
+				assert.JSONEq(t, tt.want, string(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package migrate converts the configuration of other release tools into
+// gotagger configuration.
+package migrate
+
+// Config is the subset of the gotagger.json configuration that can be
+// produced by a migration.
+type Config struct {
+	ChangelogPreset   string            `json:"changelogPreset,omitempty"`
+	DefaultIncrement  string            `json:"defaultIncrement,omitempty"`
+	IncrementMappings map[string]string `json:"incrementMappings,omitempty"`
+	ReleaseBranches   []string          `json:"releaseBranches,omitempty"`
+	VersionPrefix     *string           `json:"versionPrefix,omitempty"`
+}
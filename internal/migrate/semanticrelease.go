@@ -0,0 +1,248 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	commitAnalyzer = "@semantic-release/commit-analyzer"
+	defaultTag     = "v${version}"
+	versionVar     = "${version}"
+)
+
+// semanticReleaseFiles are the semantic-release configuration files that
+// can be read, in the order semantic-release looks for them.
+var semanticReleaseFiles = []string{"package.json", ".releaserc", ".releaserc.json", "release.config.js"}
+
+// angularRules are the default release rules of the commit analyzer.
+var angularRules = map[string]string{
+	"feat": "minor",
+	"fix":  "patch",
+	"perf": "patch",
+}
+
+type semanticRelease struct {
+	Branches  []json.RawMessage `json:"branches"`
+	Plugins   []json.RawMessage `json:"plugins"`
+	TagFormat string            `json:"tagFormat"`
+}
+
+type semanticReleaseBranch struct {
+	Name       string          `json:"name"`
+	Prerelease json.RawMessage `json:"prerelease"`
+}
+
+type commitAnalyzerOptions struct {
+	Preset       string          `json:"preset"`
+	ReleaseRules json.RawMessage `json:"releaseRules"`
+}
+
+type releaseRule struct {
+	Type    string          `json:"type"`
+	Release json.RawMessage `json:"release"`
+
+	// fields that gotagger cannot match on
+	Breaking *bool  `json:"breaking"`
+	Revert   *bool  `json:"revert"`
+	Scope    string `json:"scope"`
+	Subject  string `json:"subject"`
+}
+
+// SemanticRelease reads the semantic-release configuration in dir and
+// converts it into gotagger configuration. It returns warnings for any
+// settings that could not be converted.
+//
+// Only JSON configuration is supported. A release.config.js file is read if
+// it only exports an object literal.
+func SemanticRelease(dir string) (Config, []string, error) {
+	for _, name := range semanticReleaseFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return Config{}, nil, err
+		}
+
+		switch name {
+		case "package.json":
+			var pkg struct {
+				Release json.RawMessage `json:"release"`
+			}
+			if err := json.Unmarshal(data, &pkg); err != nil {
+				return Config{}, nil, fmt.Errorf("could not parse %s: %w", name, err)
+			}
+			if len(pkg.Release) == 0 {
+				continue
+			}
+			data = pkg.Release
+		case "release.config.js":
+			if data, err = jsToJSON(string(data)); err != nil {
+				return Config{}, nil, fmt.Errorf("could not parse %s: %w", name, err)
+			}
+		}
+
+		cfg, warnings, err := ConvertSemanticRelease(data)
+		if err != nil {
+			return Config{}, nil, fmt.Errorf("could not convert %s: %w", name, err)
+		}
+
+		return cfg, warnings, nil
+	}
+
+	return Config{}, nil, fmt.Errorf("no semantic-release configuration found in %s", dir)
+}
+
+// ConvertSemanticRelease converts a semantic-release JSON configuration into
+// gotagger configuration. It returns warnings for any settings that could not
+// be converted.
+func ConvertSemanticRelease(data []byte) (cfg Config, warnings []string, err error) {
+	var sr semanticRelease
+	if err := json.Unmarshal(data, &sr); err != nil {
+		return cfg, nil, fmt.Errorf("only JSON configuration is supported: %w", err)
+	}
+
+	// semantic-release only releases for the types in its rules
+	cfg.DefaultIncrement = "none"
+	cfg.IncrementMappings = map[string]string{}
+	for typ, inc := range angularRules {
+		cfg.IncrementMappings[typ] = inc
+	}
+
+	// tag format
+	if sr.TagFormat != "" && sr.TagFormat != defaultTag {
+		idx := strings.Index(sr.TagFormat, versionVar)
+		if idx < 0 {
+			return cfg, nil, fmt.Errorf("tagFormat %q does not contain %s", sr.TagFormat, versionVar)
+		}
+		if suffix := sr.TagFormat[idx+len(versionVar):]; suffix != "" {
+			warnings = append(warnings, fmt.Sprintf("tagFormat suffix %q is not supported", suffix))
+		}
+
+		prefix := sr.TagFormat[:idx]
+		cfg.VersionPrefix = &prefix
+	}
+
+	// branches
+	for _, raw := range sr.Branches {
+		var branch semanticReleaseBranch
+		if err := json.Unmarshal(raw, &branch.Name); err != nil {
+			if err := json.Unmarshal(raw, &branch); err != nil {
+				return cfg, nil, fmt.Errorf("invalid branch %s: %w", raw, err)
+			}
+		}
+
+		if len(branch.Prerelease) > 0 && string(branch.Prerelease) != "false" {
+			warnings = append(warnings, fmt.Sprintf("pre-release branch %q is not supported", branch.Name))
+			continue
+		}
+
+		if strings.ContainsAny(branch.Name, "{}()") {
+			warnings = append(warnings, fmt.Sprintf("branch pattern %q uses extended glob syntax, which is not supported", branch.Name))
+			continue
+		}
+
+		if _, err := path.Match(branch.Name, ""); err != nil {
+			warnings = append(warnings, fmt.Sprintf("branch pattern %q is not a valid glob", branch.Name))
+			continue
+		}
+
+		cfg.ReleaseBranches = append(cfg.ReleaseBranches, branch.Name)
+	}
+
+	// plugins
+	for _, raw := range sr.Plugins {
+		var name string
+		var opts commitAnalyzerOptions
+		if err := json.Unmarshal(raw, &name); err != nil {
+			var plugin []json.RawMessage
+			if err := json.Unmarshal(raw, &plugin); err != nil || len(plugin) == 0 {
+				return cfg, nil, fmt.Errorf("invalid plugin %s", raw)
+			}
+			if err := json.Unmarshal(plugin[0], &name); err != nil {
+				return cfg, nil, fmt.Errorf("invalid plugin %s", raw)
+			}
+			if len(plugin) > 1 && name == commitAnalyzer {
+				if err := json.Unmarshal(plugin[1], &opts); err != nil {
+					return cfg, nil, fmt.Errorf("invalid %s options: %w", commitAnalyzer, err)
+				}
+			}
+		}
+
+		if name != commitAnalyzer {
+			continue
+		}
+
+		switch opts.Preset {
+		case "", "angular", "conventionalcommits":
+			cfg.ChangelogPreset = "angular"
+		default:
+			warnings = append(warnings, fmt.Sprintf("commit analyzer preset %q is not supported", opts.Preset))
+		}
+
+		w, err := convertReleaseRules(opts.ReleaseRules, cfg.IncrementMappings)
+		if err != nil {
+			return cfg, nil, err
+		}
+		warnings = append(warnings, w...)
+	}
+
+	return cfg, warnings, nil
+}
+
+// convertReleaseRules adds the commit analyzer releaseRules in data to
+// mappings.
+func convertReleaseRules(data json.RawMessage, mappings map[string]string) (warnings []string, err error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var rules []releaseRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		var module string
+		if json.Unmarshal(data, &module) == nil {
+			return []string{fmt.Sprintf("releaseRules module %q is not supported", module)}, nil
+		}
+		return nil, fmt.Errorf("invalid releaseRules: %w", err)
+	}
+
+	for _, rule := range rules {
+		switch {
+		case rule.Type == "":
+			warnings = append(warnings, "release rules without a type are not supported")
+			continue
+		case rule.Type == "release":
+			warnings = append(warnings, "the release type cannot be mapped")
+			continue
+		case rule.Scope != "" || rule.Subject != "" || rule.Breaking != nil || rule.Revert != nil:
+			warnings = append(warnings, fmt.Sprintf("release rule for type %q only matches some commits and is not supported", rule.Type))
+			continue
+		}
+
+		var release string
+		if err := json.Unmarshal(rule.Release, &release); err != nil {
+			// release: false means no release
+			release = "none"
+		}
+
+		switch release {
+		case "major":
+			warnings = append(warnings, fmt.Sprintf("type %q cannot be mapped to a major release, use a breaking change instead", rule.Type))
+		case "minor", "patch", "none":
+			mappings[rule.Type] = release
+		default:
+			warnings = append(warnings, fmt.Sprintf("release %q for type %q is not supported", release, rule.Type))
+		}
+	}
+
+	return warnings, nil
+}
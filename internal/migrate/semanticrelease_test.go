@@ -0,0 +1,123 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSemanticRelease(t *testing.T) {
+	prefix := "release-"
+
+	tests := []struct {
+		title        string
+		data         string
+		want         Config
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			title: "empty",
+			data:  `{}`,
+			want: Config{
+				DefaultIncrement:  "none",
+				IncrementMappings: map[string]string{"feat": "minor", "fix": "patch", "perf": "patch"},
+			},
+		},
+		{
+			title: "full",
+			data: `{
+				"branches": ["main", "+([0-9]).x", {"name": "release/*", "channel": "stable"}, {"name": "beta", "prerelease": true}, "bad["],
+				"tagFormat": "release-${version}",
+				"plugins": [
+					["@semantic-release/commit-analyzer", {
+						"preset": "angular",
+						"releaseRules": [
+							{"type": "docs", "release": "patch"},
+							{"type": "perf", "release": false},
+							{"type": "refactor", "release": "major"},
+							{"type": "style", "scope": "ui", "release": "patch"}
+						]
+					}],
+					"@semantic-release/release-notes-generator",
+					"@semantic-release/github"
+				]
+			}`,
+			want: Config{
+				ChangelogPreset:   "angular",
+				DefaultIncrement:  "none",
+				IncrementMappings: map[string]string{"docs": "patch", "feat": "minor", "fix": "patch", "perf": "none"},
+				ReleaseBranches:   []string{"main", "release/*"},
+				VersionPrefix:     &prefix,
+			},
+			wantWarnings: []string{
+				`branch pattern "+([0-9]).x" uses extended glob syntax, which is not supported`,
+				`pre-release branch "beta" is not supported`,
+				`branch pattern "bad[" is not a valid glob`,
+				`type "refactor" cannot be mapped to a major release, use a breaking change instead`,
+				`release rule for type "style" only matches some commits and is not supported`,
+			},
+		},
+		{
+			title: "unsupported preset",
+			data:  `{"plugins": [["@semantic-release/commit-analyzer", {"preset": "eslint"}]]}`,
+			want: Config{
+				DefaultIncrement:  "none",
+				IncrementMappings: map[string]string{"feat": "minor", "fix": "patch", "perf": "patch"},
+			},
+			wantWarnings: []string{`commit analyzer preset "eslint" is not supported`},
+		},
+		{
+			title:   "invalid tag format",
+			data:    `{"tagFormat": "v1"}`,
+			wantErr: `tagFormat "v1" does not contain ${version}`,
+		},
+		{
+			title:   "yaml",
+			data:    "branches:\n  - main\n",
+			wantErr: "only JSON configuration is supported: invalid character 'b' looking for beginning of value",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, warnings, err := ConvertSemanticRelease([]byte(tt.data))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, cfg)
+				assert.Equal(t, tt.wantWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestSemanticRelease(t *testing.T) {
+	dir := t.TempDir()
+
+	_, _, err := SemanticRelease(dir)
+	assert.EqualError(t, err, "no semantic-release configuration found in "+dir)
+
+	// package.json without a release key is skipped
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "foo"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "release.config.js"), []byte("module.exports = {\n  \"branches\": [\"main\"]\n};\n"), 0o600))
+
+	if cfg, _, err := SemanticRelease(dir); assert.NoError(t, err) {
+		assert.Equal(t, []string{"main"}, cfg.ReleaseBranches)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"release": {"branches": ["trunk"]}}`), 0o600))
+
+	if cfg, _, err := SemanticRelease(dir); assert.NoError(t, err) {
+		assert.Equal(t, []string{"trunk"}, cfg.ReleaseBranches)
+	}
+}
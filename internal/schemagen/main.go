@@ -0,0 +1,29 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Command schemagen writes the JSON Schema of gotagger's JSON output, as
+// returned by gotagger.Schema, to the file named by its argument.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sassoftware/gotagger"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: schemagen FILE")
+		os.Exit(2)
+	}
+
+	data, err := gotagger.Schema()
+	if err == nil {
+		err = os.WriteFile(os.Args[1], data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/require"
@@ -43,6 +44,32 @@ func CommitFile(t T, repo *git.Repository, path, filename, message string, data
 func CommitFiles(t T, repo *git.Repository, path, message string, files []FileCommit) plumbing.Hash {
 	t.Helper()
 
+	return commitFiles(t, repo, path, message, files, nil, nil)
+}
+
+// CommitFileAs commits a file with the author name and email.
+func CommitFileAs(t T, repo *git.Repository, path, filename, message string, data []byte, name, email string) plumbing.Hash {
+	t.Helper()
+
+	author := &object.Signature{Name: name, Email: email, When: time.Now()}
+	return commitFiles(t, repo, path, message, []FileCommit{{Path: filename, Contents: data}}, nil, author)
+}
+
+// MergeFiles commits files as a merge of HEAD and parent.
+func MergeFiles(t T, repo *git.Repository, path, message string, parent plumbing.Hash, files []FileCommit) plumbing.Hash {
+	t.Helper()
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return commitFiles(t, repo, path, message, files, []plumbing.Hash{head.Hash(), parent}, nil)
+}
+
+func commitFiles(t T, repo *git.Repository, path, message string, files []FileCommit, parents []plumbing.Hash, author *object.Signature) plumbing.Hash {
+	t.Helper()
+
 	w, err := repo.Worktree()
 	if err != nil {
 		t.Fatal(err)
@@ -65,12 +92,17 @@ func CommitFiles(t T, repo *git.Repository, path, message string, files []FileCo
 		}
 	}
 
-	h, err := w.Commit(message, &git.CommitOptions{
-		Author: &object.Signature{
+	if author == nil {
+		author = &object.Signature{
 			Email: GotaggerEmail,
 			Name:  GotaggerName,
 			When:  time.Now(),
-		},
+		}
+	}
+
+	h, err := w.Commit(message, &git.CommitOptions{
+		Author:  author,
+		Parents: parents,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -98,6 +130,54 @@ func CreateTag(t T, r *git.Repository, name string) {
 	}
 }
 
+// NewRemote creates a bare repository, adds it to repo as the origin
+// remote, pushes all branches to it, and sets the upstream of each branch.
+func NewRemote(t T, repo *git.Repository) (remote *git.Repository, path string) {
+	t.Helper()
+
+	path = t.TempDir()
+
+	var err error
+	remote, err = git.PlainInit(path, true)
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name:  "origin",
+		URLs:  []string{path},
+		Fetch: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"refs/heads/*:refs/heads/*"},
+	}))
+
+	err = repo.Fetch(&git.FetchOptions{RemoteName: "origin"})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatal(err)
+	}
+
+	// track the remote branches
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+
+	branches, err := repo.Branches()
+	require.NoError(t, err)
+	require.NoError(t, branches.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		cfg.Branches[name] = &config.Branch{
+			Name:   name,
+			Remote: "origin",
+			Merge:  ref.Name(),
+		}
+		return nil
+	}))
+	require.NoError(t, repo.SetConfig(cfg))
+
+	return
+}
+
 func NewGitRepo(t T) (repo *git.Repository, path string) {
 	t.Helper()
 
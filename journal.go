@@ -0,0 +1,156 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	journalDir  = "gotagger"
+	journalFile = "journal.json"
+)
+
+// ErrInterruptedRelease is returned by TagRepo when a previous release did
+// not finish. Call Resume to complete it.
+var ErrInterruptedRelease = errors.New("a previous release was interrupted, run 'gotagger resume' to finish it")
+
+// journal records the tags a release intends to create and push,
+// so that an interrupted release can be finished by Resume.
+type journal struct {
+	Commit        string        `json:"commit"`
+	Tags          []string      `json:"tags"`
+	Floating      []floatingTag `json:"floating,omitempty"`
+	ForceFloating bool          `json:"forceFloating,omitempty"`
+	Push          bool          `json:"push"`
+	Remote        string        `json:"remote,omitempty"`
+}
+
+func (g *Gotagger) journalPath() string {
+	return filepath.Join(g.repo.GitDir, journalDir, journalFile)
+}
+
+// readJournal returns the journal of an interrupted release,
+// or nil if there is none.
+func (g *Gotagger) readJournal() (*journal, error) {
+	data, err := os.ReadFile(g.journalPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	j := &journal{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("invalid release journal %s: %w", g.journalPath(), err)
+	}
+
+	return j, nil
+}
+
+func (g *Gotagger) writeJournal(j *journal) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.journalPath()), 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file and rename it so that a crash never leaves
+	// a partially written journal behind
+	tmp := g.journalPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, g.journalPath())
+}
+
+func (g *Gotagger) removeJournal() error {
+	if err := os.Remove(g.journalPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Resume finishes a release that was interrupted while creating or pushing
+// tags. Any tags that were not created are created on the recorded commit,
+// the floating tags of the release are moved to it, and then all of the tags
+// are pushed if the release was going to push them. An error is returned if
+// a tag of the release already exists on another commit.
+//
+// Resume returns the tags of the interrupted release,
+// or nil if there was no interrupted release.
+func (g *Gotagger) Resume() ([]string, error) {
+	j, err := g.readJournal()
+	if err != nil || j == nil {
+		return nil, err
+	}
+
+	// leave the release untouched if it could not be finished
+	if j.Push {
+		if err := g.checkOnline("push tags"); err != nil {
+			return nil, err
+		}
+	}
+
+	g.logger.Info("resuming interrupted release", "commit", j.Commit, "tags", j.Tags)
+
+	refs, err := g.repo.FindRefs(j.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]bool{}
+	for _, ref := range refs {
+		existing[ref] = true
+	}
+
+	for _, tag := range j.Tags {
+		if ref := g.repo.TagRef(tag); existing[ref] {
+			// a tag of the same name on another commit is not ours
+			commit, err := g.repo.CommitHash(ref)
+			if err != nil {
+				return nil, err
+			}
+			if commit != j.Commit {
+				return nil, fmt.Errorf("cannot resume release: %s already exists at commit %s, not %s", tag, shortHash(commit), shortHash(j.Commit))
+			}
+			continue
+		}
+
+		g.logger.Info("creating missing tag", "tag", tag)
+		if err := g.repo.CreateTag(j.Commit, tag, tagMessage(tag), false); err != nil {
+			return nil, err
+		}
+		g.events.Info("created tag", "tag", tag, "commit", j.Commit)
+	}
+
+	// moving floating tags is idempotent
+	if _, err := g.moveFloatingTags(j.Commit, j.Floating); err != nil {
+		return nil, err
+	}
+
+	if j.Push {
+		// leave the journal in place if the push fails,
+		// so that the push can be retried
+		if err := g.pushTags(j); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.removeJournal(); err != nil {
+		return nil, err
+	}
+
+	return j.Tags, nil
+}
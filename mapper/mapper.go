@@ -3,7 +3,10 @@
 
 package mapper
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 func Convert(inc string) (Increment, error) {
 	switch inc {
@@ -28,6 +31,28 @@ const (
 	IncrementMajor = iota
 )
 
+// String returns the name of i, as accepted by Convert.
+func (i Increment) String() string {
+	switch i {
+	case IncrementMajor:
+		return "major"
+	case IncrementMinor:
+		return "minor"
+	case IncrementPatch:
+		return "patch"
+	case IncrementNone:
+		return "none"
+	}
+
+	return fmt.Sprintf("Increment(%d)", int(i))
+}
+
+// MarshalText returns the name of i, so that increments are written to JSON
+// the same way as they are configured.
+func (i Increment) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
 const (
 	TypeFeature     = "feat"
 	TypeBugFix      = "fix"
@@ -43,6 +68,22 @@ const (
 	TypeRevert      = "revert"
 )
 
+// conventionalTypes are the commit types of the conventional commit standard.
+var conventionalTypes = map[string]bool{
+	TypeFeature:     true,
+	TypeBugFix:      true,
+	TypeRelease:     true,
+	TypeRefactor:    true,
+	TypePerformance: true,
+	TypeTest:        true,
+	TypeStyle:       true,
+	TypeBuild:       true,
+	TypeChore:       true,
+	TypeCI:          true,
+	TypeDocs:        true,
+	TypeRevert:      true,
+}
+
 // All other commit types are patch by default.
 var defaultCommitTypeMapper = map[string]Increment{
 	TypeFeature: IncrementMinor,
@@ -82,3 +123,43 @@ func (t Table) Get(typ string) Increment {
 
 	return inc
 }
+
+// Known returns true if typ is mapped by the table or is one of the commit
+// types of the conventional commit standard.
+func (t Table) Known(typ string) bool {
+	if conventionalTypes[typ] {
+		return true
+	}
+
+	_, ok := t.Mapper[typ]
+	return ok
+}
+
+// Default returns the increment of commit types that are not mapped.
+func (t Table) Default() Increment {
+	return t.defaultInc
+}
+
+// Mappings returns a copy of the increments of the mapped commit types,
+// including the release type, which is always a patch increment.
+func (t Table) Mappings() Mapper {
+	mappings := make(Mapper, len(t.Mapper)+1)
+	for typ, inc := range t.Mapper {
+		mappings[typ] = inc
+	}
+	mappings[TypeRelease] = IncrementPatch
+
+	return mappings
+}
+
+// MarshalJSON returns the effective policy of t, such as
+// {"default":"patch","mappings":{"feat":"minor","release":"patch"}}.
+func (t Table) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Default  Increment `json:"default"`
+		Mappings Mapper    `json:"mappings"`
+	}{
+		Default:  t.Default(),
+		Mappings: t.Mappings(),
+	})
+}
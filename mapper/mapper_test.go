@@ -4,6 +4,7 @@
 package mapper
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -102,3 +103,51 @@ func TestTypeTable_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestTable_Known(t *testing.T) {
+	t.Parallel()
+
+	table := NewTable(Mapper{"feat": IncrementMinor, "deps": IncrementPatch}, IncrementPatch)
+	for _, typ := range []string{TypeFeature, TypeBugFix, TypeChore, TypeRelease, "deps"} {
+		assert.True(t, table.Known(typ), typ)
+	}
+	for _, typ := range []string{"faet", "", "Feat"} {
+		assert.False(t, table.Known(typ), typ)
+	}
+}
+
+func TestTable_Mappings(t *testing.T) {
+	t.Parallel()
+
+	table := NewTable(Mapper{"feat": IncrementMinor, "deps": IncrementNone}, IncrementPatch)
+	assert.Equal(t, Increment(IncrementPatch), table.Default())
+	assert.Equal(t, Mapper{"feat": IncrementMinor, "deps": IncrementNone, "release": IncrementPatch}, table.Mappings())
+
+	// the mappings are a copy
+	table.Mappings()["feat"] = IncrementMajor
+	assert.Equal(t, Increment(IncrementMinor), table.Get("feat"))
+
+	// the default table maps features
+	assert.Equal(t, Mapper{"feat": IncrementMinor, "release": IncrementPatch}, NewTable(nil, IncrementNone).Mappings())
+}
+
+func TestTable_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(NewTable(Mapper{"feat": IncrementMinor, "deps": IncrementNone}, IncrementPatch))
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"default":"patch","mappings":{"deps":"none","feat":"minor","release":"patch"}}`, string(data))
+	}
+}
+
+func TestIncrement_String(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"major", "minor", "patch", "none"} {
+		inc, err := Convert(s)
+		if assert.NoError(t, err) {
+			assert.Equal(t, s, inc.String())
+		}
+	}
+	assert.Equal(t, "Increment(7)", Increment(7).String())
+}
@@ -0,0 +1,119 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// modulesAll is the value of a Modules footer that releases every module that
+// changed since its latest version.
+const modulesAll = "all"
+
+// footerPunctuation is removed from the ends of the module names in Modules
+// footers, such as the period that ends a sentence.
+const footerPunctuation = ".;:!?'\"`()[]"
+
+// isModulesFooter returns true if title is the title of a Modules footer,
+// ignoring case.
+func isModulesFooter(title string) bool {
+	return strings.EqualFold(title, modulesFooter)
+}
+
+// splitModulesFooter returns the module names listed in the text of a Modules
+// footer. Names are separated by commas or whitespace, which unfolds footers
+// that are wrapped over several lines, and punctuation around each name is
+// removed.
+func splitModulesFooter(text string) []string {
+	var names []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if name := strings.Trim(field, footerPunctuation); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// isAllModules returns true if the module names listed in the Modules footers
+// of a commit are the single value "all". It is an error to list module names
+// as well.
+func isAllModules(names []string) (bool, error) {
+	all := false
+	for _, name := range names {
+		if strings.EqualFold(name, modulesAll) {
+			all = true
+		}
+	}
+
+	if all && len(names) > 1 {
+		return false, fmt.Errorf(`"%s: %s" cannot be combined with module names`, modulesFooter, modulesAll)
+	}
+
+	return all, nil
+}
+
+// foldModuleName returns the module name for comparisons, with its first
+// element, which is usually a host name, in lower case.
+func foldModuleName(name string) string {
+	host, rest, found := strings.Cut(name, "/")
+	if !found {
+		return strings.ToLower(host)
+	}
+
+	return strings.ToLower(host) + "/" + rest
+}
+
+// unknownModuleError returns the error for a module name in a Modules footer
+// that does not match any of modules, suggesting the closest module name or
+// alias, if one is close enough to be a typo.
+func (g *Gotagger) unknownModuleError(name string, modules []module) error {
+	var candidates []string
+	for _, m := range modules {
+		candidates = append(candidates, m.name)
+		if alias := g.moduleAlias(m.name); alias != "" {
+			candidates = append(candidates, alias)
+		}
+	}
+
+	suggestion, best := "", len(name)/3+1
+	for _, candidate := range candidates {
+		if d := editDistance(foldModuleName(name), foldModuleName(candidate)); d <= best {
+			suggestion, best = candidate, d
+		}
+	}
+
+	if suggestion == "" {
+		return fmt.Errorf("no module %s found", name)
+	}
+
+	return fmt.Errorf("no module %s found, did you mean %s?", name, suggestion)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur := make([]int, len(br)+1)
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(br)]
+}
@@ -0,0 +1,71 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+// nestedModules returns the modules nested under the commitModules listed in
+// Modules footers that are not listed themselves, if o.includeNested is set.
+// They are marked optional in o, so that they are only released if they
+// changed since their latest version.
+func (o *releaseOptions) nestedModules(modules, commitModules []module) []module {
+	if !o.includeNested {
+		return nil
+	}
+
+	listed := map[module]bool{}
+	for _, m := range commitModules {
+		listed[m] = true
+	}
+
+	var nested []module
+	for _, m := range modules {
+		if listed[m] {
+			continue
+		}
+
+		for _, parent := range commitModules {
+			if isNestedModule(parent, m) {
+				nested = append(nested, m)
+				break
+			}
+		}
+	}
+
+	o.optional = map[module]bool{}
+	for _, m := range nested {
+		o.optional[m] = true
+	}
+
+	return nested
+}
+
+// isNestedModule returns true if the directory of module m is under the
+// directory of module parent.
+func isNestedModule(parent, m module) bool {
+	if parent.path == rootModulePath {
+		return m.path != rootModulePath
+	}
+
+	return m.path != parent.path && inDir(m.path, parent.path)
+}
+
+// withoutModules returns the modules that are not in remove.
+func withoutModules(modules, remove []module) []module {
+	if len(remove) == 0 {
+		return modules
+	}
+
+	removed := map[module]bool{}
+	for _, m := range remove {
+		removed[m] = true
+	}
+
+	var kept []module
+	for _, m := range modules {
+		if !removed[m] {
+			kept = append(kept, m)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,61 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// nightlyIdentifier is the pre-release identifier of nightly versions.
+const nightlyIdentifier = "nightly"
+
+// nightlyVersion returns version with the nightly pre-release identifiers
+// for the UTC date of now, as in v1.3.0-nightly.20240615. If version is
+// already a pre-release, then the identifiers are appended to it.
+func nightlyVersion(version string, now time.Time) string {
+	sep := "-"
+	if strings.Contains(version, "-") {
+		sep = "."
+	}
+
+	return version + sep + nightlyIdentifier + "." + now.UTC().Format("20060102")
+}
+
+// moveNightlyTag points Config.NightlyTag at the commit hash, and pushes it
+// if Config.PushTag is set, replacing the tag on the remote. The tag is
+// restored if the push fails. Nothing is done if Config.NightlyTag is not
+// set.
+func (g *Gotagger) moveNightlyTag(hash string, versions []string) error {
+	name := g.Config.NightlyTag
+	if name == "" {
+		g.logger.Info("no nightly tag to move")
+		return nil
+	}
+
+	if problem := refNameProblem(name); problem != "" {
+		return fmt.Errorf("invalid nightly tag %q: %s", name, problem)
+	}
+
+	previous, err := g.repo.MoveTag(hash, name, "Nightly "+strings.Join(versions, " "))
+	if err != nil {
+		return err
+	}
+	g.events.Info("moved nightly tag", "tag", name, "versions", versions, "commit", hash)
+
+	if g.Config.PushTag {
+		// the nightly tag is pushed like a forced floating tag
+		j := &journal{
+			Floating:      []floatingTag{{Name: name}},
+			ForceFloating: true,
+			Remote:        g.Config.RemoteName,
+		}
+		if err := g.pushTags(j); err != nil {
+			return g.restoreFloatingTags(map[string]string{name: previous}, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOffline is returned when Config.Offline is set and an operation would
+// need the network, such as pushing tags.
+var ErrOffline = errors.New("offline mode forbids network access")
+
+// offline reports whether git must not use the network.
+func (g *Gotagger) offline() bool {
+	return g.Config.Offline
+}
+
+// checkOnline returns an error wrapping ErrOffline if Config.Offline is set,
+// describing the action that would need the network.
+func (g *Gotagger) checkOnline(action string) error {
+	if g.Config.Offline {
+		return fmt.Errorf("%w: cannot %s", ErrOffline, action)
+	}
+
+	return nil
+}
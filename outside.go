@@ -0,0 +1,119 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// outsideModules finds the files outside of modules that are changed by the
+// commits since the latest version of each module.
+type outsideModules struct {
+	g        *Gotagger
+	rev      string
+	excluded fileMatcher
+
+	// modules maps the directory of every module in rev to the module.
+	modules map[string]module
+
+	// excludes are the normalized paths in Config.ExcludeModules.
+	excludes []string
+
+	// files caches the files outside of modules by latest version hash.
+	files map[string][]string
+}
+
+// newOutsideModules returns an outsideModules for the modules in the tree of
+// rev. Unlike the modules being versioned, these are not filtered by
+// explicitly included modules or by the directory gotagger was created for.
+func (g *Gotagger) newOutsideModules(rev string, excluded fileMatcher) (*outsideModules, error) {
+	modules, err := g.walkModules(rev, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	excludes := make([]string, len(g.Config.ExcludeModules))
+	for i, name := range g.Config.ExcludeModules {
+		excludes[i] = normalizePath(name)
+	}
+
+	return &outsideModules{
+		g:        g,
+		rev:      rev,
+		excluded: excluded,
+		modules:  mapModulesByPath(modules),
+		excludes: excludes,
+		files:    map[string][]string{},
+	}, nil
+}
+
+// check applies Config.OutsideChanges to the files outside of modules that
+// are changed by the commits since hash, which is the latest version of
+// module mod, or the empty string if it has none. It returns a warning if
+// the policy is OutsideChangesWarn.
+func (o *outsideModules) check(mod module, hash string) ([]Warning, error) {
+	files, err := o.changedFiles(hash)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("commits since the latest version of module %s change files outside of modules: %s", mod.name, strings.Join(files, ", "))
+	if o.g.Config.OutsideChanges == OutsideChangesFail {
+		return nil, errors.New(message)
+	}
+
+	return []Warning{{
+		Code:    WarningOutsideChanges,
+		Modules: []string{mod.name},
+		Message: message,
+	}}, nil
+}
+
+// changedFiles returns the sorted, slash-separated names of the files outside
+// of modules that are changed by the commits between rev and hash.
+func (o *outsideModules) changedFiles(hash string) ([]string, error) {
+	if files, ok := o.files[hash]; ok {
+		return files, nil
+	}
+
+	commits, err := o.g.repo.RevList(o.rev, hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch commits %s..%s: %w", o.rev, hash, err)
+	}
+	commits = o.g.excludeCommits(commits)
+
+	seen := map[string]struct{}{}
+	var files []string
+	for _, c := range commits {
+		for _, change := range c.Changes {
+			for _, name := range []string{change.SourceName, change.DestName} {
+				if name == "" || !o.outside(name) {
+					continue
+				}
+				if _, ok := seen[name]; !ok {
+					seen[name] = struct{}{}
+					files = append(files, name)
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+
+	o.files[hash] = files
+	return files, nil
+}
+
+// outside returns true if the file name, relative to the root of the
+// repository, does not belong to any module and is not excluded.
+func (o *outsideModules) outside(name string) bool {
+	if _, ok := isModuleFile(filepath.FromSlash(name), o.modules); ok {
+		return false
+	}
+
+	return !o.excluded.match(name) && !excludedPath(filepath.Dir(filepath.FromSlash(name)), o.excludes)
+}
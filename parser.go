@@ -0,0 +1,109 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"strings"
+
+	"github.com/sassoftware/gotagger/internal/commit"
+)
+
+// CommitParser parses commit messages, so that repositories with their own
+// commit conventions, such as subjects that start with a ticket number, can be
+// versioned and tagged. It is set by Config.CommitParser.
+//
+// A CommitParser must be safe for concurrent use.
+type CommitParser interface {
+	// ParseCommit parses the full message of a commit. A message that the
+	// parser does not understand has an empty Type.
+	ParseCommit(message string) ParsedCommit
+}
+
+// ParsedCommit is a commit message parsed by a CommitParser.
+type ParsedCommit struct {
+	// Type is the type of the commit, such as "feat", which is mapped to
+	// a version increment by Config.CommitTypeTable. Release commits have
+	// the type "release".
+	Type string
+
+	// Scope is the scope of the commit, if any.
+	Scope string
+
+	// Subject is the summary of the commit shown in changelogs.
+	Subject string
+
+	// Body is the message between the subject and the footers.
+	Body string
+
+	// Breaking is true if the commit is a breaking change.
+	Breaking bool
+
+	// Footers are the footers of the message, such as the Modules footer of
+	// a release commit.
+	Footers []Footer
+}
+
+// Footer is a footer of a commit message, such as "Modules: foo/bar".
+type Footer struct {
+	Title string
+	Text  string
+}
+
+// ConventionalCommitParser is the CommitParser of conventional commits, such
+// as "feat(scope)!: subject", which is used if Config.CommitParser is nil.
+// Other parsers can use it for messages they do not handle themselves.
+type ConventionalCommitParser struct{}
+
+// ParseCommit parses message as a conventional commit.
+func (ConventionalCommitParser) ParseCommit(message string) ParsedCommit {
+	c := commit.Parse(message)
+
+	parsed := ParsedCommit{
+		Type:     c.Type,
+		Scope:    c.Scope,
+		Subject:  c.Subject,
+		Body:     c.Body,
+		Breaking: c.Breaking,
+	}
+	for _, f := range c.Footers {
+		parsed.Footers = append(parsed.Footers, Footer{Title: f.Title, Text: f.Text})
+	}
+
+	return parsed
+}
+
+// parseMessage parses message with Config.CommitParser. It returns false if
+// commits are conventional commits, so that they are parsed and cached by
+// the repository.
+func (g *Gotagger) parseMessage(message string) (commit.Commit, bool) {
+	switch g.Config.CommitParser.(type) {
+	case nil, ConventionalCommitParser, *ConventionalCommitParser:
+		return commit.Commit{}, false
+	}
+
+	parsed := g.Config.CommitParser.ParseCommit(message)
+	header, _, _ := strings.Cut(message, "\n")
+	c := commit.Commit{
+		Type:     parsed.Type,
+		Scope:    parsed.Scope,
+		Subject:  parsed.Subject,
+		Body:     parsed.Body,
+		Breaking: parsed.Breaking,
+		Header:   strings.TrimSpace(header),
+	}
+	for _, f := range parsed.Footers {
+		c.Footers = append(c.Footers, commit.Footer{Title: f.Title, Text: f.Text})
+	}
+
+	return c, true
+}
+
+// parseCommitMessage parses a message that is not committed yet.
+func (g *Gotagger) parseCommitMessage(message string) commit.Commit {
+	if c, ok := g.parseMessage(message); ok {
+		return c
+	}
+
+	return commit.Parse(message)
+}
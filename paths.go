@@ -0,0 +1,157 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymlinks is the number of symlinks resolvePath follows before giving up,
+// as with ELOOP.
+const maxSymlinks = 255
+
+// readLinkFS is implemented by file systems that can read symlinks, such as
+// os.DirFS and fstest.MapFS in newer versions of Go.
+type readLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+}
+
+// Paths relative to the root of the repository are kept in the form of the
+// operating system, such as sub\module on Windows, so that they can be used
+// with the filesystem. Git, tag prefixes, and anything else that is printed
+// use forward slashes, so paths are converted with slashPath before they are
+// compared or joined with those.
+
+// slashPath returns the path p, relative to the root of the repository, with
+// forward slashes and without redundant separators or elements.
+func slashPath(p string) string {
+	return path.Clean(filepath.ToSlash(p))
+}
+
+// inDir returns true if the path p is the directory dir or is under it. Both
+// are relative to the root of the repository, and may use either separator
+// on Windows.
+func inDir(p, dir string) bool {
+	p, dir = slashPath(p), slashPath(dir)
+	return dir == rootModulePath || p == dir || strings.HasPrefix(p, dir+goModSep)
+}
+
+// modulePrefix returns the tag prefix of the module name in the directory
+// dir, such as "sub/module/" for a module in sub/module, or for a major
+// version of it in sub/module/v2. The root module has no prefix.
+func modulePrefix(dir, name string) string {
+	prefix := slashPath(dir)
+	if prefix == rootModulePath {
+		return ""
+	}
+
+	// strip a trailing major version directory, but not a directory that
+	// merely ends with the same characters
+	if major := majorVersion(name); major != "" {
+		if prefix == major {
+			return ""
+		}
+		prefix = strings.TrimSuffix(prefix, goModSep+major)
+	}
+
+	return prefix + goModSep
+}
+
+// comparePaths compares the directories a and b, relative to the root of the
+// repository, element by element, so that each directory sorts right before
+// the directories under it, as in a depth-first walk. The root directory
+// sorts first. The result is negative if a sorts before b, positive if it
+// sorts after b, and zero if they are the same directory.
+func comparePaths(a, b string) int {
+	ae, be := pathElements(a), pathElements(b)
+	for i := 0; i < len(ae) && i < len(be); i++ {
+		if c := strings.Compare(ae[i], be[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(ae) - len(be)
+}
+
+// pathElements returns the elements of the path p, relative to the root of
+// the repository. The root directory has none.
+func pathElements(p string) []string {
+	if p = slashPath(p); p == rootModulePath {
+		return nil
+	}
+
+	return strings.Split(p, goModSep)
+}
+
+// normalizePath returns the directory p with forward slashes, a leading "./",
+// and a trailing "/", so that directories can be compared by prefix.
+func normalizePath(p string) string {
+	// normalize to /
+	p = filepath.ToSlash(p)
+
+	// ensure leading "./"
+	if !strings.HasPrefix(p, "./") && p != "." {
+		p = "./" + p
+	}
+
+	// ensure trailing /
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+
+	return p
+}
+
+// resolvePath returns the path name in fsys with every symlink in it resolved,
+// so that a directory reached through different symlinks always resolves to
+// the same path. Symlinks are not resolved if fsys cannot read them. Symlinks
+// to absolute paths leave fsys, and are resolved by the operating system.
+func resolvePath(fsys fs.FS, name string) (string, error) {
+	rl, ok := fsys.(readLinkFS)
+	if !ok {
+		return path.Clean(name), nil
+	}
+
+	resolved := "."
+	rest := strings.Split(name, goModSep)
+	for links := 0; len(rest) > 0; {
+		elem := rest[0]
+		rest = rest[1:]
+
+		next := path.Join(resolved, elem)
+		if elem == "" || elem == "." || elem == ".." || !fs.ValidPath(next) {
+			// links that leave fsys are only resolved lexically
+			resolved = next
+			continue
+		}
+
+		target, err := rl.ReadLink(next)
+		if err != nil {
+			// not a symlink
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("%s: too many levels of symbolic links", name)
+		}
+
+		if filepath.IsAbs(target) {
+			abs := filepath.Join(append([]string{target}, rest...)...)
+			if evaluated, err := filepath.EvalSymlinks(abs); err == nil {
+				abs = evaluated
+			}
+			return abs, nil
+		}
+
+		rest = append(strings.Split(target, goModSep), rest...)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,119 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// Impact is the effect that merging a branch would have on the version of a
+// go module, or of a path if go modules are ignored.
+type Impact struct {
+	// Module is the name of the go module, if any.
+	Module string
+
+	// Alias is the alias of the module in Config.ModuleAliases, if any.
+	Alias string
+
+	// Path is the path to the module or path filter,
+	// relative to the root of the repository.
+	Path string
+
+	// Increment is the increment that the commits of the branch make to
+	// the latest version on their own, such as IncrementMajor if one of them
+	// is a breaking change.
+	Increment mapper.Increment
+
+	// Commits is the number of commits of the branch that change the module.
+	Commits int
+
+	// BaseVersion is the version of the module at the base, including its
+	// prefix. It is empty if the module does not exist at the base.
+	BaseVersion string
+
+	// Version is the version of the module with the branch, including its
+	// prefix.
+	Version string
+}
+
+// Preview returns the Impact of the commits that HEAD adds to base, such as
+// the commits of a pull request branch and its target branch, on every module
+// or path that they change, in the same order as Results. Versions only
+// include what would be tagged, as with NextTags.
+func (g *Gotagger) Preview(base string) ([]Impact, error) {
+	if err := g.checkCommits(); err != nil {
+		return nil, err
+	}
+
+	var modules, baseModules []module
+	if !g.Config.IgnoreModules {
+		var err error
+		if modules, err = g.findAllModules(nil); err != nil {
+			return nil, err
+		}
+		if baseModules, err = g.findModulesAt(base, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	releases, err := g.releases(modules, nil, releaseOptions{tagsOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	baseReleases, err := g.releases(baseModules, nil, releaseOptions{to: base, tagsOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	baseVersions := make(map[moduleKey]string, len(baseReleases))
+	for _, rel := range baseReleases {
+		baseVersions[moduleKey{rel.Module, slashPath(rel.Path)}] = rel.Version
+	}
+
+	// the commits of the branch
+	branch, err := g.repo.RevList(head, base)
+	if err != nil {
+		return nil, err
+	}
+	added := make(map[string]bool, len(branch))
+	for _, c := range branch {
+		added[c.Hash] = true
+	}
+
+	var impacts []Impact
+	for _, rel := range releases {
+		commits := rel.commits[:0:0]
+		for _, c := range rel.commits {
+			if added[c.Hash] {
+				commits = append(commits, c)
+			}
+		}
+		if len(commits) == 0 {
+			continue
+		}
+
+		// the latest version decides whether breaking changes are major
+		latest := new(semver.Version)
+		if rel.LatestTag != "" {
+			if latest, err = semver.NewVersion(strings.TrimPrefix(rel.LatestTag, rel.Prefix)); err != nil {
+				return nil, err
+			}
+		}
+
+		impacts = append(impacts, Impact{
+			Module:      rel.Module,
+			Alias:       rel.Alias,
+			Path:        rel.Path,
+			Increment:   g.parseCommits(commits, latest, g.commitTypeTable(rel.Module)),
+			Commits:     len(commits),
+			BaseVersion: baseVersions[moduleKey{rel.Module, slashPath(rel.Path)}],
+			Version:     rel.Version,
+		})
+	}
+
+	return impacts, nil
+}
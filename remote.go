@@ -0,0 +1,57 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sassoftware/gotagger/internal/git"
+)
+
+// ErrNoRemote is returned when a remote does not exist.
+var ErrNoRemote = errors.New("no such remote")
+
+// Remote describes a git remote of the repository, for integrations such as
+// release publishing.
+type Remote struct {
+	// Name is the name of the remote, such as origin.
+	Name string `json:"name"`
+
+	// URL is the URL of the remote, without any credentials.
+	URL string `json:"url"`
+
+	// Host is the lower case host name of the server, such as github.com.
+	// It is empty if URL is a local path or has an unknown scheme.
+	Host string `json:"host,omitempty"`
+
+	// Slug identifies the project independently of how it is cloned, as the
+	// host name and the path of the repository without the .git suffix,
+	// such as github.com/sassoftware/gotagger. It is empty if Host is.
+	Slug string `json:"slug,omitempty"`
+}
+
+// Remote returns the remote name, or Config.RemoteName if name is empty.
+// An error wrapping ErrNoRemote is returned if the remote does not exist.
+func (g *Gotagger) Remote(name string) (Remote, error) {
+	if name == "" {
+		name = g.Config.RemoteName
+	}
+
+	rawURL, err := g.repo.RemoteURL(name)
+	if err != nil {
+		return Remote{}, err
+	}
+	if rawURL == "" {
+		return Remote{}, fmt.Errorf("%w: %s", ErrNoRemote, name)
+	}
+
+	remote := Remote{Name: name, URL: redactURL(rawURL)}
+	if host, path, ok := git.ParseRemoteURL(rawURL); ok {
+		remote.Host = host
+		remote.Slug = host + "/" + path
+	}
+
+	return remote, nil
+}
@@ -0,0 +1,26 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date returns the time that outputs about the revision rev are dated with,
+// such as changelogs and provenance: the current time, or the committer date
+// of rev if Config.Reproducible is set, so that every run against the same
+// commit produces the same output. The time is in UTC.
+func (g *Gotagger) Date(rev string) (time.Time, error) {
+	if !g.Config.Reproducible {
+		return time.Now().UTC(), nil
+	}
+
+	date, err := g.repo.CommitDate(rev)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not find the date of %s: %w", rev, err)
+	}
+
+	return date.UTC(), nil
+}
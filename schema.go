@@ -0,0 +1,181 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+//go:generate go run ./internal/schemagen schema.json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/sassoftware/gotagger/changelog"
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+const (
+	schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	schemaID      = "https://github.com/sassoftware/gotagger/schema.json"
+)
+
+// schemaOutputs are the JSON documents that gotagger writes, by the name of
+// their definition in the schema.
+var schemaOutputs = []struct {
+	name string
+	v    any
+}{
+	// -format json
+	{"results", []Result{}},
+	// -dry-run -format json
+	{"plannedTags", []PlannedTag{}},
+	// -format provenance
+	{"provenance", []Provenance{}},
+	// -format badge, one per line
+	{"badge", Badge{}},
+	// -trace-file
+	{"trace", Trace{}},
+	// CHANGELOG.json
+	{"changelog", []changelog.Release{}},
+}
+
+// Schema returns a JSON Schema (draft 2020-12) of the JSON documents that
+// gotagger writes, such as the Results printed by -format json, the
+// PlannedTags printed by -dry-run -format json, a Trace, and a changelog in
+// changelog.FormatJSON. Each document and each of the types it contains is
+// a definition in $defs, so that clients can be generated from it.
+//
+// The schema is also published as schema.json at the root of the repository.
+func Schema() ([]byte, error) {
+	s := schemaBuilder{defs: map[string]any{}}
+
+	outputs := make([]any, len(schemaOutputs))
+	for i, out := range schemaOutputs {
+		s.defs[out.name] = s.schema(reflect.TypeOf(out.v), false)
+		outputs[i] = map[string]any{"$ref": "#/$defs/" + out.name}
+	}
+
+	data, err := json.MarshalIndent(map[string]any{
+		"$schema":     schemaDialect,
+		"$id":         schemaID,
+		"title":       "gotagger",
+		"description": "The JSON documents written by gotagger.",
+		"anyOf":       outputs,
+		"$defs":       s.defs,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// schemaBuilder builds the schemas of go types as encoding/json writes them.
+type schemaBuilder struct {
+	// schemas of named struct types, by type name
+	defs map[string]any
+}
+
+var (
+	bytesType     = reflect.TypeOf([]byte(nil))
+	incrementType = reflect.TypeOf(mapper.Increment(0))
+	tableType     = reflect.TypeOf(mapper.Table{})
+	timeType      = reflect.TypeOf(time.Time{})
+)
+
+// schema returns the schema of values of type t. If nullable is true, then
+// nil maps, pointers, and slices are written as null.
+func (s schemaBuilder) schema(t reflect.Type, nullable bool) any {
+	switch t {
+	case bytesType:
+		return map[string]any{"type": "string"}
+	case incrementType:
+		return map[string]any{"type": "string", "enum": []string{"none", "patch", "minor", "major"}}
+	case tableType:
+		return map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"default":  s.schema(incrementType, false),
+				"mappings": map[string]any{"type": "object", "additionalProperties": s.schema(incrementType, false)},
+			},
+			"required": []string{"default", "mappings"},
+		}
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Pointer:
+		return s.nullable(s.schema(t.Elem(), false), nullable)
+	case reflect.Slice, reflect.Array:
+		return s.nullable(map[string]any{"type": "array", "items": s.schema(t.Elem(), true)}, nullable && t.Kind() == reflect.Slice)
+	case reflect.Map:
+		return s.nullable(map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem(), true)}, nullable)
+	case reflect.Struct:
+		if _, ok := s.defs[t.Name()]; !ok {
+			// reserve the name first, for recursive types
+			s.defs[t.Name()] = nil
+			s.defs[t.Name()] = s.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+
+	// anything else, such as an interface
+	return map[string]any{}
+}
+
+// object returns the schema of the struct type t.
+func (s schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		omitempty := false
+		for _, opt := range strings.Split(opts, ",") {
+			omitempty = omitempty || opt == "omitempty"
+		}
+
+		properties[name] = s.schema(f.Type, !omitempty)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// nullable returns schema, or a schema that also allows null if nullable is
+// true.
+func (s schemaBuilder) nullable(schema any, nullable bool) any {
+	if !nullable {
+		return schema
+	}
+
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
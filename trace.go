@@ -0,0 +1,228 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sassoftware/gotagger/internal/git"
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// Trace records every input to the calculation of a set of versions, so that
+// it can be attached to a release as an auditable record of why each version
+// was chosen.
+type Trace struct {
+	// Timestamp is when the trace was generated, or the committer date of
+	// the versioned commit if Config.Reproducible is set.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Config is a snapshot of the configuration that affects versions.
+	Config TraceConfig `json:"config"`
+
+	// Modules are the traces of the versioned modules or paths.
+	Modules []ModuleTrace `json:"modules"`
+}
+
+// TraceConfig is the configuration that affects versions. IgnoreFile is the
+// patterns in the .gotaggerignore file of the repository.
+type TraceConfig struct {
+	CommitTypeAliases         map[string]string       `json:"commitTypeAliases,omitempty"`
+	CommitTypeTable           mapper.Table            `json:"commitTypeTable"`
+	CommitsSince              bool                    `json:"commitsSince"`
+	CurrentVersion            string                  `json:"currentVersion,omitempty"`
+	DependencyIncrement       *mapper.Increment       `json:"dependencyIncrement,omitempty"`
+	DirtyWorktreeIncrement    mapper.Increment        `json:"dirtyWorktreeIncrement"`
+	DirtyWorktreeSuffix       string                  `json:"dirtyWorktreeSuffix,omitempty"`
+	ExcludeCommits            CommitFilter            `json:"excludeCommits"`
+	ExcludeFiles              []string                `json:"excludeFiles,omitempty"`
+	ExcludeModules            []string                `json:"excludeModules,omitempty"`
+	IgnoreFile                []string                `json:"ignoreFile,omitempty"`
+	IgnoreModules             bool                    `json:"ignoreModules"`
+	MergeIncrement            mapper.Increment        `json:"mergeIncrement"`
+	ModuleCommitTypeTables    map[string]mapper.Table `json:"moduleCommitTypeTables,omitempty"`
+	Nightly                   bool                    `json:"nightly"`
+	Paths                     []string                `json:"paths,omitempty"`
+	PreMajor                  bool                    `json:"preMajor"`
+	PreMajorBreakingIncrement mapper.Increment        `json:"preMajorBreakingIncrement"`
+	PreMajorFeatureIncrement  mapper.Increment        `json:"preMajorFeatureIncrement"`
+	Promote                   bool                    `json:"promote"`
+	StrictCommitTypes         bool                    `json:"strictCommitTypes"`
+	TagLimit                  int                     `json:"tagLimit,omitempty"`
+	TagNamespace              string                  `json:"tagNamespace,omitempty"`
+	UmbrellaVersion           bool                    `json:"umbrellaVersion"`
+	VersionFile               string                  `json:"versionFile,omitempty"`
+	VersionPrefix             string                  `json:"versionPrefix"`
+}
+
+// ModuleTrace records how the version of a module or path was calculated.
+type ModuleTrace struct {
+	// Module is the name of the go module, if any.
+	Module string `json:"module,omitempty"`
+
+	// Path is the path to the module or path filter,
+	// relative to the root of the repository.
+	Path string `json:"path"`
+
+	// Commit is the hash of the commit that was versioned.
+	Commit string `json:"commit"`
+
+	// Tags are the version tags that were considered.
+	Tags []string `json:"tags"`
+
+	// LatestTag and LatestHash are the chosen latest version tag and the
+	// commit it points to, if there is one.
+	LatestTag  string `json:"latestTag,omitempty"`
+	LatestHash string `json:"latestHash,omitempty"`
+
+	// Commits are the commits since the latest version that determined the
+	// increment, newest first.
+	Commits []CommitTrace `json:"commits"`
+
+	// Version is the calculated version.
+	Version string `json:"version"`
+}
+
+// CommitTrace is a commit, as parsed by gotagger, and the increment it maps
+// to.
+type CommitTrace struct {
+	Hash      string           `json:"hash"`
+	Title     string           `json:"title"`
+	Type      string           `json:"type,omitempty"`
+	Scope     string           `json:"scope,omitempty"`
+	Subject   string           `json:"subject,omitempty"`
+	Breaking  bool             `json:"breaking,omitempty"`
+	Merge     bool             `json:"merge,omitempty"`
+	Footers   []string         `json:"footers,omitempty"`
+	Increment mapper.Increment `json:"increment"`
+
+	// ChangeID is the Gerrit Change-Id of the commit, and Topic is the
+	// Gerrit topic it merges, if any.
+	ChangeID string `json:"changeId,omitempty"`
+	Topic    string `json:"topic,omitempty"`
+
+	// PullRequest is the number of the Azure Repos or Bitbucket pull request
+	// it merges, if any.
+	PullRequest int `json:"pullRequest,omitempty"`
+}
+
+// Trace returns the Trace of results, such as those returned by Results.
+// Only results calculated while Config.Trace was set are included.
+func (g *Gotagger) Trace(results []Result) Trace {
+	trace := Trace{
+		Timestamp: g.traceTimestamp(results),
+		Config: TraceConfig{
+			CommitTypeAliases:         g.Config.CommitTypeAliases,
+			CommitTypeTable:           g.Config.CommitTypeTable,
+			CommitsSince:              g.Config.CommitsSince,
+			CurrentVersion:            g.Config.CurrentVersion,
+			DirtyWorktreeIncrement:    g.Config.DirtyWorktreeIncrement,
+			DirtyWorktreeSuffix:       g.Config.DirtyWorktreeSuffix,
+			ExcludeCommits:            g.Config.ExcludeCommits,
+			ExcludeFiles:              g.Config.ExcludeFiles,
+			ExcludeModules:            g.Config.ExcludeModules,
+			IgnoreModules:             g.Config.IgnoreModules,
+			MergeIncrement:            g.Config.MergeIncrement,
+			ModuleCommitTypeTables:    g.Config.ModuleCommitTypeTables,
+			Nightly:                   g.Config.Nightly,
+			Paths:                     g.Config.Paths,
+			PreMajor:                  g.Config.PreMajor,
+			PreMajorBreakingIncrement: g.Config.PreMajorBreakingIncrement,
+			PreMajorFeatureIncrement:  g.Config.PreMajorFeatureIncrement,
+			Promote:                   g.Config.Promote,
+			StrictCommitTypes:         g.Config.StrictCommitTypes,
+			TagLimit:                  g.Config.TagLimit,
+			TagNamespace:              g.Config.TagNamespace,
+			UmbrellaVersion:           g.Config.UmbrellaVersion,
+			VersionFile:               g.Config.VersionFile,
+			VersionPrefix:             g.Config.VersionPrefix,
+		},
+		Modules: []ModuleTrace{},
+	}
+	if g.Config.DependencyUpdates != nil {
+		trace.Config.DependencyIncrement = &g.Config.DependencyUpdates.Increment
+	}
+
+	// the ignore file was already read to calculate results
+	if lines, err := g.ignoredLines(); err != nil {
+		g.logger.Error(err, "could not read "+ignoreFile)
+	} else {
+		trace.Config.IgnoreFile = lines
+	}
+
+	for _, res := range results {
+		if res.Trace != nil {
+			trace.Modules = append(trace.Modules, *res.Trace)
+		}
+	}
+
+	return trace
+}
+
+// traceTimestamp returns the timestamp of the trace of results: the current
+// time, or the committer date of the commit they were calculated from if
+// Config.Reproducible is set. The commit was read to calculate results, so
+// if its date cannot be read, then the zero time is used, which is still
+// reproducible.
+func (g *Gotagger) traceTimestamp(results []Result) time.Time {
+	if !g.Config.Reproducible {
+		return time.Now().UTC()
+	}
+
+	for _, res := range results {
+		if res.Commit == "" {
+			continue
+		}
+
+		date, err := g.Date(res.Commit)
+		if err != nil {
+			g.logger.Info("could not date trace", "error", err)
+			return time.Time{}
+		}
+
+		return date
+	}
+
+	return time.Time{}
+}
+
+// traceResult sets the trace of res, if Config.Trace is set, from the tags
+// that were considered and the commits that incremented its version from v.
+func (g *Gotagger) traceResult(res *Result, tags []string, commits []git.Commit, v *semver.Version, table mapper.Table) {
+	if !g.Config.Trace {
+		return
+	}
+
+	preMajor := g.Config.PreMajor && v.Major() == 0
+	trace := &ModuleTrace{
+		Module:     res.Module,
+		Path:       res.Path,
+		Tags:       append([]string{}, tags...),
+		LatestTag:  res.LatestTag,
+		LatestHash: res.LatestHash,
+		Commits:    make([]CommitTrace, len(commits)),
+		Version:    res.Version,
+	}
+	for i, c := range commits {
+		trace.Commits[i] = CommitTrace{
+			Hash:        c.Hash,
+			Title:       c.Title,
+			Type:        c.Type,
+			Scope:       c.Scope,
+			Subject:     c.Subject,
+			Breaking:    c.Breaking,
+			Merge:       c.IsMerge(),
+			Increment:   g.commitIncrement(c, preMajor, table),
+			ChangeID:    c.ChangeID,
+			Topic:       c.Topic,
+			PullRequest: c.PullRequest,
+		}
+		for _, f := range c.Footers {
+			trace.Commits[i].Footers = append(trace.Commits[i].Footers, f.String())
+		}
+	}
+
+	res.Trace = trace
+}
@@ -0,0 +1,49 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// commitTypeRegex matches the commit types that can be parsed from a
+// conventional commit header.
+var commitTypeRegex = regexp.MustCompile(`^\w+$`)
+
+// validateCommitTypeAliases returns an error if aliases contain types that
+// cannot be parsed, alias the release type, or are chained.
+func validateCommitTypeAliases(aliases map[string]string) error {
+	types := make([]string, 0, len(aliases))
+	for typ := range aliases {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	for _, alias := range types {
+		typ := aliases[alias]
+		for _, t := range []string{alias, typ} {
+			if !commitTypeRegex.MatchString(t) {
+				return fmt.Errorf("invalid commit type %q in alias %s: must only contain letters, digits, and underscores", t, alias)
+			}
+		}
+		if alias == mapper.TypeRelease || typ == mapper.TypeRelease {
+			return fmt.Errorf("release commit type cannot be aliased")
+		}
+		if _, ok := aliases[typ]; ok && typ != alias {
+			return fmt.Errorf("commit type alias %s is an alias of %s, which is also an alias", alias, typ)
+		}
+	}
+
+	return nil
+}
+
+// typeAlias returns the commit type that typ is an alias of in
+// Config.CommitTypeAliases, or the empty string if it is not an alias.
+func (g *Gotagger) typeAlias(typ string) string {
+	return g.Config.CommitTypeAliases[typ]
+}
@@ -0,0 +1,41 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"github.com/sassoftware/gotagger/internal/git"
+)
+
+// umbrellaCommits returns the commits that changed any module, in the order of
+// commits, for the umbrella version of the root module, and the commits to
+// increment the umbrella version by. A new major version of the root module
+// needs a new module path, so breaking changes to other modules only count as
+// breaking for the umbrella version if they also change the root module.
+func umbrellaCommits(commits []git.Commit, grouped map[module][]git.Commit, root module) (all, increment []git.Commit) {
+	own := map[string]struct{}{}
+	for _, c := range grouped[root] {
+		own[c.Hash] = struct{}{}
+	}
+
+	changed := map[string]struct{}{}
+	for _, moduleCommits := range grouped {
+		for _, c := range moduleCommits {
+			changed[c.Hash] = struct{}{}
+		}
+	}
+
+	for _, c := range commits {
+		if _, ok := changed[c.Hash]; !ok {
+			continue
+		}
+		all = append(all, c)
+
+		if _, ok := own[c.Hash]; !ok && c.Breaking {
+			c.Breaking = false
+		}
+		increment = append(increment, c)
+	}
+
+	return all, increment
+}
@@ -0,0 +1,63 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// WriteUnreleased is like WriteChangelogs, but replaces the Unreleased section
+// of each changelog with the changes since the latest version, so that a
+// keep-a-changelog file always lists what the next release will contain.
+// The Unreleased section of a module without changes is emptied, if its
+// changelog exists. Only markdown changelogs have an Unreleased section.
+//
+// When the changelogs are later written by WriteChangelogs, the Unreleased
+// section is moved under the heading of the new version, along with any edits
+// made to it.
+func (g *Gotagger) WriteUnreleased(names ...string) ([]string, error) {
+	return g.writeChangelogs(names, true)
+}
+
+// UpdateChangelog is like WriteChangelogs, or WriteUnreleased if unreleased is
+// true, but only writes the changelog file fn, with the changes of the module
+// or path whose directory contains it. fn is absolute, or relative to the root
+// of the repository, and may have any name.
+func (g *Gotagger) UpdateChangelog(fn string, unreleased bool) error {
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(g.repo.Path, fn)
+	}
+
+	dir, err := relativePath(g.repo.Path, filepath.Dir(fn))
+	if err != nil {
+		return err
+	}
+
+	var modules []module
+	if !g.Config.IgnoreModules {
+		if modules, err = g.findAllModules(nil); err != nil {
+			return err
+		}
+	}
+
+	releases, err := g.releases(modules, nil, releaseOptions{})
+	if err != nil {
+		return err
+	}
+
+	// the deepest module or path contains the file
+	found := -1
+	for i, rel := range releases {
+		if inDir(dir, rel.Path) && (found < 0 || len(pathElements(rel.Path)) > len(pathElements(releases[found].Path))) {
+			found = i
+		}
+	}
+	if found < 0 {
+		return fmt.Errorf("no module or path contains %s", fn)
+	}
+
+	_, err = g.writeChangelog(slashPath(filepath.Join(dir, filepath.Base(fn))), releases[found], unreleased)
+	return err
+}
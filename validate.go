@@ -0,0 +1,137 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sassoftware/gotagger/internal/git"
+	"github.com/sassoftware/gotagger/mapper"
+)
+
+// ValidateRelease checks the release commit at rev, such as the head of a pull
+// request branch, before it is merged, and returns the versions TagRepo would
+// tag for it. An error is returned for the problems that would otherwise stop
+// TagRepo after the merge: rev is not a release commit, its footers are
+// invalid, the modules it lists do not match the modules it changes or have
+// no changes since their latest version, its changelogs are not updated when
+// Config.ModuleChangelogs is set, or its tags already exist.
+//
+// Checks that depend on where and when TagRepo runs, such as
+// Config.ReleaseBranches and Config.CheckUpstream, are not made.
+func (g *Gotagger) ValidateRelease(rev string) ([]Result, error) {
+	if err := g.checkCommits(); err != nil {
+		return nil, err
+	}
+
+	c, err := g.repo.CommitAt(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.validateRelease(c, c.Hash, true)
+}
+
+// ValidateReleaseMessage is like ValidateRelease, but checks a release commit
+// message that has not been committed yet, as if it were committed on top of
+// HEAD. Since the commit has no changes, its modules are not compared to the
+// modules it changes, and its changelogs are not checked.
+func (g *Gotagger) ValidateReleaseMessage(message string) ([]Result, error) {
+	if err := g.checkCommits(); err != nil {
+		return nil, err
+	}
+
+	hash, err := g.repo.CommitHash(head)
+	if err != nil {
+		return nil, err
+	}
+
+	c := git.Commit{Commit: g.parseCommitMessage(message)}
+
+	return g.validateRelease(c, hash, false)
+}
+
+// validateRelease checks the release commit c, which is committed at rev if
+// committed is true, or would be committed on top of rev if it is not.
+func (g *Gotagger) validateRelease(c git.Commit, rev string, committed bool) ([]Result, error) {
+	if c.Type != mapper.TypeRelease {
+		return nil, fmt.Errorf("not a release commit: %q", c.Header)
+	}
+
+	opts, err := g.extractReleaseOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	opts.to = rev
+	opts.tagsOnly = true
+
+	var modules []module
+	if !g.Config.IgnoreModules {
+		if modules, err = g.findModulesAt(rev, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	commitModules, err := g.releaseModules(c, modules, &opts, committed)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := g.releases(modules, commitModules, opts)
+	if err != nil {
+		return nil, err
+	}
+	results := releaseResults(releases)
+
+	if err := unchangedReleases(releases); err != nil {
+		return nil, err
+	}
+
+	if committed && g.Config.ModuleChangelogs {
+		if err := checkChangelogs(c, results, g.Config.ChangelogFormat.FileName()); err != nil {
+			return nil, err
+		}
+	}
+
+	// tags can only already exist at a release commit that is committed
+	var tagged string
+	if committed {
+		tagged = rev
+	}
+	if _, err := g.checkTagCollisions(resultVersions(results), tagged); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// unchangedReleases returns an error listing the modules or paths of releases
+// that have no changes since their latest version.
+func unchangedReleases(releases []release) error {
+	var unchanged []string
+	for _, rel := range releases {
+		if len(rel.commits) > 0 {
+			continue
+		}
+
+		switch {
+		case rel.Module != "":
+			unchanged = append(unchanged, rel.Module)
+		case rel.Path != "":
+			unchanged = append(unchanged, rel.Path)
+		default:
+			unchanged = append(unchanged, rootModulePath)
+		}
+	}
+
+	if len(unchanged) == 0 {
+		return nil
+	}
+	sort.Strings(unchanged)
+
+	return errors.New("no changes since the latest version of: " + strings.Join(unchanged, ", "))
+}
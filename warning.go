@@ -0,0 +1,255 @@
+// Copyright © 2020, SAS Institute Inc., Cary, NC, USA.  All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package gotagger
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// WarningConflictingPrefix means that two modules use the same tag
+	// prefix for the same major version, so they cannot be told apart by
+	// their tags.
+	WarningConflictingPrefix = "conflicting-prefix"
+
+	// WarningUnknownCommitType means that a commit has a type that is not
+	// known, which is usually a typo, so it did not increment the version.
+	WarningUnknownCommitType = "unknown-commit-type"
+
+	// WarningNestedModule means that a module is nested inside another
+	// module, but its module path does not match its directory. This is
+	// usually a copied or generated go.mod that should be excluded.
+	WarningNestedModule = "nested-module"
+
+	// WarningSkippedModule means that a go.mod does not declare a module
+	// path, so its directory is not versioned as a module.
+	WarningSkippedModule = "skipped-module"
+
+	// WarningInvalidTag means that a tag has the version prefix and looks
+	// like a version, but is not a valid semantic version, so it is ignored.
+	WarningInvalidTag = "invalid-tag"
+
+	// WarningIgnoredTag means that a version tag is on the same commit as a
+	// higher version, so it is ignored.
+	WarningIgnoredTag = "ignored-tag"
+
+	// WarningUnverifiedTag means that a version tag failed signature
+	// verification and was skipped, because Config.VerifyTags is
+	// TagVerificationSkip.
+	WarningUnverifiedTag = "unverified-tag"
+
+	// WarningOutsideChanges means that the commits since the latest version
+	// of a module change files that do not belong to any module, and
+	// Config.OutsideChanges is OutsideChangesWarn.
+	WarningOutsideChanges = "outside-changes"
+
+	// WarningShallowClone means that the repository is a shallow clone, so
+	// tags and commits may be missing and versions may be wrong.
+	WarningShallowClone = "shallow-clone"
+
+	// WarningUnpromotedStable means that breaking changes move a 0.x.y
+	// version to 1.0.0 because Config.PreMajor is not set. Crossing 1.0.0 is
+	// usually a deliberate decision, made by promoting the release, rather
+	// than a side effect of a breaking change.
+	WarningUnpromotedStable = "unpromoted-stable"
+
+	// WarningAlreadyStable means that a release is promoted, but the latest
+	// version of a module is already 1.0.0 or later, so its version is
+	// incremented as usual.
+	WarningAlreadyStable = "already-stable"
+)
+
+// Warning is a problem that does not prevent gotagger from versioning the
+// repository, but probably means the result is not what was intended.
+type Warning struct {
+	// Code identifies the kind of problem, such as WarningConflictingPrefix.
+	Code string `json:"code"`
+
+	// Modules are the names of the modules involved.
+	Modules []string `json:"modules,omitempty"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// String returns the message of w.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// ModuleWarnings returns warnings about how the go modules in the repository
+// are laid out. It reports modules that share a tag prefix for the same
+// major version, nested modules whose module path does not match their
+// directory and are not excluded by Config.ExcludeModules, and go.mod files
+// that are skipped because they do not declare a module path.
+//
+// If module names are passed in, then only those modules are checked.
+//
+// The same warnings are included in the Warnings of the results of Results
+// and TagRepoResults, so there is no need to call ModuleWarnings as well.
+func (g *Gotagger) ModuleWarnings(names ...string) ([]Warning, error) {
+	if g.Config.IgnoreModules {
+		return nil, nil
+	}
+
+	_, warnings, err := g.findModuleWarnings(head, names)
+	return warnings, err
+}
+
+// findModuleWarnings returns the modules in the tree of rev, like
+// findModulesAt, along with the warnings about how they are laid out.
+func (g *Gotagger) findModuleWarnings(rev string, include []string) ([]module, []Warning, error) {
+	var skipped []string
+	modules, err := g.walkModules(rev, include, func(dir string) {
+		skipped = append(skipped, dir)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	modules = g.subdirModules(modules, include)
+
+	warnings := checkModules(modules)
+	for _, dir := range skipped {
+		warnings = append(warnings, Warning{
+			Code:    WarningSkippedModule,
+			Message: fmt.Sprintf("%s does not declare a module path and is skipped", path.Join(dir, goMod)),
+		})
+	}
+
+	return modules, warnings, nil
+}
+
+// addModuleWarnings adds each of warnings to the releases of the modules it
+// involves, or to every release if it is not about particular modules.
+func addModuleWarnings(releases []release, warnings []Warning) {
+	for i := range releases {
+		for _, w := range warnings {
+			if len(w.Modules) == 0 || slices.Contains(w.Modules, releases[i].Module) {
+				releases[i].Warnings = append(releases[i].Warnings, w)
+			}
+		}
+	}
+}
+
+// checkModules returns warnings about modules, which must be sorted by path.
+func checkModules(modules []module) (warnings []Warning) {
+	// modules that share a tag prefix and major version
+	type prefixMajor struct{ prefix, major string }
+	seen := map[prefixMajor]module{}
+	for _, mod := range modules {
+		key := prefixMajor{mod.prefix, majorVersion(mod.name)}
+		if other, ok := seen[key]; ok {
+			warnings = append(warnings, Warning{
+				Code:    WarningConflictingPrefix,
+				Modules: []string{other.name, mod.name},
+				Message: fmt.Sprintf("modules %s and %s both use the tag prefix '%s' for the same major version", other.name, mod.name, mod.prefix),
+			})
+			continue
+		}
+		seen[key] = mod
+	}
+
+	// nested modules whose path does not match their directory
+	for i, mod := range modules {
+		parent, ok := parentModule(modules[:i], mod)
+		if !ok || isSubmodulePath(parent, mod) {
+			continue
+		}
+
+		warnings = append(warnings, Warning{
+			Code:    WarningNestedModule,
+			Modules: []string{parent.name, mod.name},
+			Message: fmt.Sprintf("module %s in %s is nested in module %s, but its module path does not match its directory: exclude it if it should not be versioned", mod.name, slashPath(mod.path), parent.name),
+		})
+	}
+
+	return warnings
+}
+
+// parentModule returns the module in candidates whose directory most closely
+// contains the directory of mod.
+func parentModule(candidates []module, mod module) (parent module, ok bool) {
+	child := slashPath(mod.path)
+	for _, c := range candidates {
+		dir := slashPath(c.path)
+		if dir == child {
+			continue
+		}
+
+		if inDir(child, dir) {
+			if !ok || len(dir) > len(slashPath(parent.path)) {
+				parent, ok = c, true
+			}
+		}
+	}
+
+	return
+}
+
+// isSubmodulePath returns true if the module path of child is the module path
+// of parent joined with the directory of child relative to parent. Major
+// version suffixes are ignored.
+func isSubmodulePath(parent, child module) bool {
+	rel := slashPath(child.path)
+	if dir := slashPath(parent.path); dir != rootModulePath {
+		rel = strings.TrimPrefix(rel, dir+goModSep)
+	}
+
+	for _, name := range []string{parent.name, trimMajorVersion(parent.name)} {
+		for _, dir := range []string{rel, trimMajorVersion(rel)} {
+			want := path.Join(name, dir)
+			if child.name == want || trimMajorVersion(child.name) == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// majorVersion returns the major version suffix of a module path, such as
+// "v2", or the empty string.
+func majorVersion(name string) string {
+	return strings.TrimPrefix(versionRegex.FindString(name), goModSep)
+}
+
+// trimMajorVersion returns name without its major version suffix.
+func trimMajorVersion(name string) string {
+	return strings.TrimSuffix(name, versionRegex.FindString(name))
+}
+
+// stableWarnings returns a WarningAlreadyStable warning if the latest version
+// of name, which is promoted, is not a 0.x.y version.
+func stableWarnings(name, prefix string, latest *semver.Version, modules []string) []Warning {
+	if latest.Major() == 0 {
+		return nil
+	}
+
+	return []Warning{{
+		Code:    WarningAlreadyStable,
+		Modules: modules,
+		Message: fmt.Sprintf("cannot promote %s: version %s%s is already stable", name, prefix, latest),
+	}}
+}
+
+// unpromotedWarnings returns a WarningUnpromotedStable warning if version is
+// a stable version of name, whose latest version is a 0.x.y version, and the
+// release is not promoted.
+func unpromotedWarnings(name, prefix string, latest *semver.Version, version string, modules []string) []Warning {
+	v, err := semver.NewVersion(version)
+	if err != nil || latest.Major() != 0 || v.Major() == 0 {
+		return nil
+	}
+
+	return []Warning{{
+		Code:    WarningUnpromotedStable,
+		Modules: modules,
+		Message: fmt.Sprintf("breaking changes move %s from %s%s to %s%s, add a \"%s: %s\" footer to the release commit if this is intended", name, prefix, latest, prefix, v, promoteFooter, promoteStable),
+	}}
+}